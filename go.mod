@@ -5,15 +5,23 @@ go 1.21.3
 require (
 	github.com/GabrielHCataldo/go-helper v1.4.7
 	github.com/GabrielHCataldo/go-logger v1.2.7
+	github.com/go-playground/validator/v10 v10.17.0
 	go.mongodb.org/mongo-driver v1.14.0
+	go.opentelemetry.io/otel v1.21.0
+	go.opentelemetry.io/otel/metric v1.21.0
+	go.opentelemetry.io/otel/sdk v1.21.0
+	go.opentelemetry.io/otel/sdk/metric v1.21.0
+	go.opentelemetry.io/otel/trace v1.21.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/cockroachdb/apd v1.1.0 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.3 // indirect
+	github.com/go-logr/logr v1.3.0 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
-	github.com/go-playground/validator/v10 v10.17.0 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
 	github.com/iancoleman/orderedmap v0.3.0 // indirect
 	github.com/klassmann/cpfcnpj v0.0.0-20200907140233-a595c5fd8de1 // indirect
@@ -0,0 +1,202 @@
+package mongod
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// BinaryEnv is the environment variable with the path of the mongod binary.
+const BinaryEnv = "MONGOD_BINARY"
+
+// alreadyInitializedCode is the server error code of replSetInitiate on an initialized replica set.
+const alreadyInitializedCode = 23
+
+var ErrBinaryNotFound = errors.New("mongod: binary not found, set " + BinaryEnv + " or add mongod to the PATH")
+
+// Config represents the configuration of the started server.
+type Config struct {
+	// BinaryPath path of the mongod binary, see FindBinary
+	BinaryPath string
+	// ReplicaSetName name of the single-node replica set (required)
+	ReplicaSetName string
+	// Dir directory of the data files and of the log, if empty a temporary directory is created and removed on Stop
+	Dir string
+	// StartupTimeout time limit for the server to start and be elected primary (required)
+	StartupTimeout time.Duration
+	// Args extra command line arguments of the mongod
+	Args []string
+}
+
+// Server is a mongod process running a single-node replica set on a random local port.
+type Server struct {
+	// URI connection string of the server
+	URI       string
+	cmd       *exec.Cmd
+	exited    chan struct{}
+	logPath   string
+	removeDir string
+}
+
+// FindBinary returns the path parameter if not empty, otherwise the BinaryEnv environment variable, otherwise the
+// mongod found on the PATH.
+func FindBinary(path string) (string, error) {
+	if helper.IsEmpty(path) {
+		path = os.Getenv(BinaryEnv)
+	}
+	if helper.IsEmpty(path) {
+		path = "mongod"
+	}
+	result, err := exec.LookPath(path)
+	if helper.IsNotNil(err) {
+		return "", ErrBinaryNotFound
+	}
+	return result, nil
+}
+
+// Start starts the mongod and initiates the replica set, returning when the server is the primary, so transactions
+// and change streams can be used.
+func Start(ctx context.Context, config Config) (*Server, error) {
+	binary, err := FindBinary(config.BinaryPath)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	server := &Server{exited: make(chan struct{})}
+	dir := config.Dir
+	if helper.IsEmpty(dir) {
+		dir, err = os.MkdirTemp("", "mongod-")
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		server.removeDir = dir
+	}
+	port, err := freePort()
+	if helper.IsNotNil(err) {
+		_ = server.Stop()
+		return nil, err
+	}
+	host := "127.0.0.1:" + strconv.Itoa(port)
+	server.URI = "mongodb://" + host + "/?directConnection=true"
+	server.logPath = filepath.Join(dir, "mongod.log")
+	args := append([]string{
+		"--replSet", config.ReplicaSetName,
+		"--port", strconv.Itoa(port),
+		"--bind_ip", "127.0.0.1",
+		"--dbpath", dir,
+		"--logpath", server.logPath,
+	}, config.Args...)
+	server.cmd = exec.Command(binary, args...)
+	if err = server.cmd.Start(); helper.IsNotNil(err) {
+		_ = server.Stop()
+		return nil, err
+	}
+	go func() {
+		_ = server.cmd.Wait()
+		close(server.exited)
+	}()
+	ctx, cancel := context.WithTimeout(ctx, config.StartupTimeout)
+	defer cancel()
+	if err = server.initiate(ctx, config.ReplicaSetName, host); helper.IsNotNil(err) {
+		_ = server.Stop()
+		return nil, fmt.Errorf("mongod: error starting replica set: %w%s", err, server.logTail())
+	}
+	return server, nil
+}
+
+// Stop interrupts the mongod, killing it if it does not exit in 10 seconds, and removes the temporary directory.
+func (s *Server) Stop() error {
+	var err error
+	if helper.IsNotNil(s.cmd) && helper.IsNotNil(s.cmd.Process) {
+		if errSignal := s.cmd.Process.Signal(os.Interrupt); helper.IsNotNil(errSignal) {
+			_ = s.cmd.Process.Kill()
+		}
+		select {
+		case <-s.exited:
+		case <-time.After(10 * time.Second):
+			err = s.cmd.Process.Kill()
+			<-s.exited
+		}
+	}
+	if helper.IsNotEmpty(s.removeDir) {
+		if errRemove := os.RemoveAll(s.removeDir); helper.IsNil(err) {
+			err = errRemove
+		}
+	}
+	return err
+}
+
+// initiate waits the server to accept connections, initiates the replica set and waits the server to be primary.
+func (s *Server) initiate(ctx context.Context, replicaSetName, host string) error {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(s.URI).SetServerSelectionTimeout(time.Second))
+	if helper.IsNotNil(err) {
+		return err
+	}
+	defer func() {
+		_ = client.Disconnect(context.WithoutCancel(ctx))
+	}()
+	admin := client.Database("admin")
+	initiated := false
+	for {
+		if !initiated {
+			err = admin.RunCommand(ctx, bson.D{{"replSetInitiate", bson.D{
+				{"_id", replicaSetName},
+				{"members", bson.A{bson.D{{"_id", 0}, {"host", host}}}},
+			}}}).Err()
+			var serverErr mongo.ServerError
+			initiated = helper.IsNil(err) ||
+				(errors.As(err, &serverErr) && serverErr.HasErrorCode(alreadyInitializedCode))
+		}
+		if initiated {
+			var hello struct {
+				IsWritablePrimary bool `bson:"isWritablePrimary"`
+			}
+			err = admin.RunCommand(ctx, bson.D{{"hello", 1}}).Decode(&hello)
+			if helper.IsNil(err) && hello.IsWritablePrimary {
+				return nil
+			}
+		}
+		select {
+		case <-s.exited:
+			return errors.New("mongod exited")
+		case <-ctx.Done():
+			if helper.IsNotNil(err) {
+				return err
+			}
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// logTail returns the last lines of the mongod log, to explain the startup errors.
+func (s *Server) logTail() string {
+	b, err := os.ReadFile(s.logPath)
+	if helper.IsNotNil(err) || helper.IsEmpty(b) {
+		return ""
+	}
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	if helper.IsGreaterThan(len(lines), 10) {
+		lines = lines[len(lines)-10:]
+	}
+	return ", log:\n" + string(bytes.Join(lines, []byte("\n")))
+}
+
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if helper.IsNotNil(err) {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
@@ -52,6 +52,25 @@ func GetCollectionNameBySlice(a any) string {
 	return GetCollectionNameByStruct(reflect.New(v.Type().Elem()).Interface())
 }
 
+func IsVersionedStruct(a any) bool {
+	if helper.IsNil(a) {
+		return false
+	}
+	t := reflect.TypeOf(a)
+	if helper.IsPointer(a) || helper.IsInterface(a) {
+		t = t.Elem()
+	}
+	if helper.IsNotEqualTo(t.Kind(), reflect.Struct) {
+		return false
+	}
+	for i := 0; helper.IsLessThan(i, t.NumField()); i++ {
+		if helper.Equals(t.Field(i).Tag.Get("versioned"), "true") {
+			return true
+		}
+	}
+	return false
+}
+
 func SetInsertedIdOnDocument(insertedId, a any) {
 	rInsertedId := reflect.ValueOf(insertedId)
 	v := reflect.ValueOf(a)
@@ -0,0 +1,138 @@
+package mongo
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/mongo"
+	"time"
+)
+
+// TemplateAPI represents the operations of the Template, the services can depend on it instead of the Template, so
+// their unit tests run with a fake implementation without any database, see the mongotest package.
+//
+// The methods bound to the mongo client, e.g. GetClient, WithSession, NewLock, NewOutbox and NewMongoTokenStore, and
+// the configuration of the middlewares by Use, are not part of the interface.
+type TemplateAPI interface {
+	// SetGlobalOption see Template.SetGlobalOption
+	SetGlobalOption(opt *option.Global)
+	// SetCache see Template.SetCache
+	SetCache(cache Cache, opts ...*option.Cache)
+	// WatchCacheInvalidation see Template.WatchCacheInvalidation
+	WatchCacheInvalidation(ctx context.Context, opts ...*option.WatchWithHandler) error
+	// InsertOne see Template.InsertOne
+	InsertOne(ctx context.Context, document any, opts ...*option.InsertOne) error
+	// InsertMany see Template.InsertMany
+	InsertMany(ctx context.Context, documents any, opts ...*option.InsertMany) error
+	// DeleteOne see Template.DeleteOne
+	DeleteOne(ctx context.Context, filter, ref any, opts ...*option.Delete) (*DeleteResult, error)
+	// DeleteOneById see Template.DeleteOneById
+	DeleteOneById(ctx context.Context, id, ref any, opts ...*option.Delete) (*DeleteResult, error)
+	// DeleteMany see Template.DeleteMany
+	DeleteMany(ctx context.Context, filter, ref any, opts ...*option.Delete) (*DeleteResult, error)
+	// UpdateOneById see Template.UpdateOneById
+	UpdateOneById(ctx context.Context, id, update, ref any, opts ...*option.Update) (*UpdateResult, error)
+	// UpdateOne see Template.UpdateOne
+	UpdateOne(ctx context.Context, filter any, update, ref any, opts ...*option.Update) (*UpdateResult, error)
+	// UpdateMany see Template.UpdateMany
+	UpdateMany(ctx context.Context, filter any, update, ref any, opts ...*option.Update) (*UpdateResult, error)
+	// ReplaceOne see Template.ReplaceOne
+	ReplaceOne(ctx context.Context, filter any, update, ref any, opts ...*option.Replace) (*UpdateResult, error)
+	// ReplaceOneById see Template.ReplaceOneById
+	ReplaceOneById(ctx context.Context, id, replacement, ref any, opts ...*option.Replace) (*UpdateResult, error)
+	// FindOneById see Template.FindOneById
+	FindOneById(ctx context.Context, id, dest any, opts ...*option.FindOneById) error
+	// FindOne see Template.FindOne
+	FindOne(ctx context.Context, filter, dest any, opts ...*option.FindOne) error
+	// FindOneAndDeleteById see Template.FindOneAndDeleteById
+	FindOneAndDeleteById(ctx context.Context, id, dest any, opts ...*option.FindOneAndDelete) error
+	// FindOneAndDelete see Template.FindOneAndDelete
+	FindOneAndDelete(ctx context.Context, filter, dest any, opts ...*option.FindOneAndDelete) error
+	// FindOneAndReplaceById see Template.FindOneAndReplaceById
+	FindOneAndReplaceById(ctx context.Context, id, replacement, dest any, opts ...*option.FindOneAndReplace) error
+	// FindOneAndReplace see Template.FindOneAndReplace
+	FindOneAndReplace(ctx context.Context, filter, replacement, dest any, opts ...*option.FindOneAndReplace) error
+	// FindOneAndUpdateById see Template.FindOneAndUpdateById
+	FindOneAndUpdateById(ctx context.Context, id, update, dest any, opts ...*option.FindOneAndUpdate) error
+	// FindOneAndUpdate see Template.FindOneAndUpdate
+	FindOneAndUpdate(ctx context.Context, filter, update, dest any, opts ...*option.FindOneAndUpdate) error
+	// Find see Template.Find
+	Find(ctx context.Context, filter, dest any, opts ...*option.Find) error
+	// FindAll see Template.FindAll
+	FindAll(ctx context.Context, dest any, opts ...*option.Find) error
+	// FindPageable see Template.FindPageable
+	FindPageable(ctx context.Context, filter any, input PageInput, opts ...*option.FindPageable) (*PageResult, error)
+	// Exists see Template.Exists
+	Exists(ctx context.Context, filter, ref any, opts ...*option.Exists) (bool, error)
+	// ExistsById see Template.ExistsById
+	ExistsById(ctx context.Context, id, ref any, opts ...*option.Exists) (bool, error)
+	// Aggregate see Template.Aggregate
+	Aggregate(ctx context.Context, pipeline any, dest any, opts ...*option.Aggregate) error
+	// CountDocuments see Template.CountDocuments
+	CountDocuments(ctx context.Context, filter, ref any, opts ...*option.Count) (int64, error)
+	// EstimatedDocumentCount see Template.EstimatedDocumentCount
+	EstimatedDocumentCount(ctx context.Context, ref any, opts ...*option.EstimatedDocumentCount) (int64, error)
+	// ExplainFind see Template.ExplainFind
+	ExplainFind(ctx context.Context, filter, ref any, opts ...*option.Find) (*ExplainResult, error)
+	// ExplainAggregate see Template.ExplainAggregate
+	ExplainAggregate(ctx context.Context, pipeline, ref any, opts ...*option.Aggregate) (*ExplainResult, error)
+	// ExplainCount see Template.ExplainCount
+	ExplainCount(ctx context.Context, filter, ref any, opts ...*option.Count) (*ExplainResult, error)
+	// ExplainUpdate see Template.ExplainUpdate
+	ExplainUpdate(ctx context.Context, filter, update, ref any, opts ...*option.Update) (*ExplainResult, error)
+	// Distinct see Template.Distinct
+	Distinct(ctx context.Context, fieldName string, filter, dest, ref any, opts ...*option.Distinct) error
+	// Watch see Template.Watch
+	Watch(ctx context.Context, pipeline any, opts ...*option.Watch) (*mongo.ChangeStream, error)
+	// WatchWithHandler see Template.WatchWithHandler
+	WatchWithHandler(ctx context.Context, pipeline any, handler EventHandler, opts ...*option.WatchWithHandler) error
+	// WatchWithHandlerE see Template.WatchWithHandlerE
+	WatchWithHandlerE(ctx context.Context, pipeline any, handler EventHandlerE, opts ...*option.WatchWithHandler) error
+	// WatchChan see Template.WatchChan
+	WatchChan(ctx context.Context, ref, pipeline any, opts ...*option.WatchChan) (<-chan Event, <-chan error)
+	// WatchWithRouter see Template.WatchWithRouter
+	WatchWithRouter(ctx context.Context, router *EventRouter, opts ...*option.WatchWithHandler) error
+	// DropCollection see Template.DropCollection
+	DropCollection(ctx context.Context, ref any) error
+	// DropDatabase see Template.DropDatabase
+	DropDatabase(ctx context.Context, ref any) error
+	// ApplyValidator see Template.ApplyValidator
+	ApplyValidator(ctx context.Context, ref any, level ValidationLevel, action ValidationAction) error
+	// EnableAudit see Template.EnableAudit
+	EnableAudit(ctx context.Context, opts ...*option.Audit) error
+	// DisableAudit see Template.DisableAudit
+	DisableAudit()
+	// FindAuditEntries see Template.FindAuditEntries
+	FindAuditEntries(ctx context.Context, id, ref any) ([]AuditEntry, error)
+	// FindAsOf see Template.FindAsOf
+	FindAsOf(ctx context.Context, id any, asOf time.Time, dest any) error
+	// ListVersions see Template.ListVersions
+	ListVersions(ctx context.Context, id, ref any) ([]DocumentVersion, error)
+	// RevertTo see Template.RevertTo
+	RevertTo(ctx context.Context, id any, version int64, ref any, opts ...*option.Replace) (*UpdateResult, error)
+	// CreateOneIndex see Template.CreateOneIndex
+	CreateOneIndex(ctx context.Context, input IndexInput) (string, error)
+	// CreateManyIndex see Template.CreateManyIndex
+	CreateManyIndex(ctx context.Context, inputs []IndexInput) ([]string, error)
+	// DropOneIndex see Template.DropOneIndex
+	DropOneIndex(ctx context.Context, name string, ref any, opts ...*option.DropIndex) error
+	// DropAllIndexes see Template.DropAllIndexes
+	DropAllIndexes(ctx context.Context, ref any, opts ...*option.DropIndex) error
+	// ListIndexes see Template.ListIndexes
+	ListIndexes(ctx context.Context, ref any, opts ...*option.ListIndexes) ([]IndexResult, error)
+	// ListIndexSpecifications see Template.ListIndexSpecifications
+	ListIndexSpecifications(ctx context.Context, ref any, opts ...*option.ListIndexes) ([]IndexSpecification, error)
+	// StartSession see Template.StartSession
+	StartSession(ctx context.Context) error
+	// CloseSession see Template.CloseSession
+	CloseSession(ctx context.Context, abort bool) error
+	// CommitTransaction see Template.CommitTransaction
+	CommitTransaction(ctx context.Context) error
+	// AbortTransaction see Template.AbortTransaction
+	AbortTransaction(ctx context.Context) error
+	// Disconnect see Template.Disconnect
+	Disconnect(ctx context.Context) error
+	// SimpleDisconnect see Template.SimpleDisconnect
+	SimpleDisconnect(ctx context.Context)
+}
+
+var _ TemplateAPI = (*Template)(nil)
@@ -0,0 +1,365 @@
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+const auditIndexName = "timestamp_ttl"
+
+// AuditEntry represents a mutation of a document stored on the audit collection, see Template.EnableAudit.
+type AuditEntry struct {
+	Id primitive.ObjectID `bson:"_id"`
+	// Namespace the database and collection of the mutated document
+	Namespace Namespace `bson:"ns"`
+	// DocumentId the _id of the mutated document
+	DocumentId any `bson:"documentId"`
+	// Operation the name of the Template method, e.g. UpdateOneById
+	Operation string `bson:"operation"`
+	// OperationType the type of the mutation, insert, update, replace or delete
+	OperationType OperationType `bson:"operationType"`
+	// Actor the actor of the mutation, see WithActor
+	Actor string `bson:"actor,omitempty"`
+	// Timestamp the time of the mutation
+	Timestamp time.Time `bson:"timestamp"`
+	// Before the document before the mutation, empty on insert
+	Before bson.Raw `bson:"before,omitempty"`
+	// After the document after the mutation, empty on delete
+	After bson.Raw `bson:"after,omitempty"`
+	// Diff the fields changed by the mutation
+	Diff []AuditChange `bson:"diff,omitempty"`
+}
+
+// AuditChange represents a field changed by a mutation, the fields of the embedded documents are represented by the
+// dot notation, e.g. address.street, and the arrays are compared as a whole.
+type AuditChange struct {
+	// Field the path of the field
+	Field string `bson:"field"`
+	// Before the value before the mutation, nil if the field was added
+	Before any `bson:"before,omitempty"`
+	// After the value after the mutation, nil if the field was removed
+	After any `bson:"after,omitempty"`
+}
+
+type actorContextKey struct{}
+
+// auditWrite represents a mutation being audited, the documents matched by the filter are read before the write, and
+// read again after it, in the same session.
+type auditWrite struct {
+	opt           *option.Audit
+	collection    *mongo.Collection
+	operationType OperationType
+	filter        any
+	findOptions   *options.FindOptions
+	upsert        bool
+	before        []bson.Raw
+}
+
+// WithActor returns a copy of the ctx with the actor parameter, which is stored on the audit entries of the mutations
+// executed with it, see Template.EnableAudit.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor of the ctx, see WithActor.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorContextKey{}).(string)
+	return actor
+}
+
+// EnableAudit enables the audit log of the Template mutations, the InsertOne, InsertMany, UpdateOne, UpdateOneById,
+// UpdateMany, ReplaceOne, ReplaceOneById, DeleteOne, DeleteOneById, DeleteMany and FindOneAnd* operations store an
+// AuditEntry for each mutated document on the audit collection, within the operation session, so the entries are
+// committed or aborted in the same transaction of the mutations.
+//
+// The documents matched by the filter are read before the write, which is executed only on them, and read again
+// after it, so the audited operations execute more commands, the actor of the entries is read from the ctx, see
+// WithActor.
+//
+// The opts parameter can be used to specify the audit collection and the retention of the entries, if the retention
+// is set, a TTL index is created or updated on the audit collection (see the option.Audit documentation.)
+//
+// EnableAudit must be called before the Template is shared between goroutines.
+func (t *Template) EnableAudit(ctx context.Context, opts ...*option.Audit) error {
+	opt := option.MergeAuditByParams(opts)
+	if helper.IsEmpty(opt.DatabaseName) {
+		return ErrDatabaseNotConfigured
+	} else if helper.IsGreaterThan(opt.Retention, 0) {
+		if err := createAuditIndex(ctx, t.auditCollection(opt), opt.Retention); helper.IsNotNil(err) {
+			return err
+		}
+	}
+	t.audit = opt
+	return nil
+}
+
+// DisableAudit disables the audit log of the Template mutations, the stored entries are kept.
+func (t *Template) DisableAudit() {
+	t.audit = nil
+}
+
+// FindAuditEntries executes a find command on the audit collection returning the entries of the document with the
+// id parameter, in the collection of the ref parameter, sorted by the timestamp.
+//
+// The ref parameter must be a structure with the database and collection tags.
+func (t *Template) FindAuditEntries(ctx context.Context, id, ref any) ([]AuditEntry, error) {
+	op := &Operation{Name: "FindAuditEntries", Ref: ref, Filter: bson.D{{"_id", id}}}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) ([]AuditEntry, error) {
+		audit := t.audit
+		if helper.IsNil(audit) {
+			return nil, ErrAuditNotEnabled
+		}
+		databaseName, collectionName, err := getMongoNamesByAny(ref)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		cursor, err := t.auditCollection(audit).Find(ctx, bson.D{
+			{"ns", Namespace{DB: databaseName, Coll: collectionName}},
+			{"documentId", id},
+		}, options.Find().SetSort(bson.D{{"timestamp", 1}, {"_id", 1}}))
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		var result []AuditEntry
+		err = cursor.All(ctx, &result)
+		return result, err
+	})
+}
+
+func (t *Template) auditCollection(opt *option.Audit) *mongo.Collection {
+	return t.client.Database(opt.DatabaseName).Collection(opt.CollectionName)
+}
+
+// beginAudit reads the documents matched by the filter parameter before the write, returning the filter narrowed to
+// their ids, if the audit is disabled, it returns a nil auditWrite and the filter parameter.
+func (t *Template) beginAudit(sc mongo.SessionContext, collection *mongo.Collection, operationType OperationType,
+	filter any, findOptions *options.FindOptions) (*auditWrite, any, error) {
+	if helper.IsNil(t.audit) {
+		return nil, filter, nil
+	}
+	a := &auditWrite{
+		opt:           t.audit,
+		collection:    collection,
+		operationType: operationType,
+		filter:        filter,
+		findOptions:   findOptions,
+	}
+	if helper.Equals(operationType, OperationTypeInsert) {
+		return a, filter, nil
+	}
+	before, err := findAuditImages(sc, collection, filter, findOptions)
+	if helper.IsNotNil(err) || helper.IsEmpty(before) {
+		return a, filter, err
+	}
+	a.before = before
+	return a, bson.D{{"$and", bson.A{filter, bson.D{{"_id", bson.D{{"$in", auditIds(before)}}}}}}}, nil
+}
+
+// commit reads the documents after the write and inserts the audit entries within the session, the upsertedId
+// parameter is the id of the inserted or upserted document, if any.
+func (a *auditWrite) commit(sc mongo.SessionContext, upsertedId any) error {
+	if helper.IsNil(a) {
+		return nil
+	}
+	var after []bson.Raw
+	var err error
+	if helper.IsNotEqualTo(a.operationType, OperationTypeDelete) {
+		ids := auditIds(a.before)
+		if helper.IsNotNil(upsertedId) {
+			ids = append(ids, upsertedId)
+		}
+		if helper.IsNotEmpty(ids) {
+			after, err = findAuditImages(sc, a.collection, bson.D{{"_id", bson.D{{"$in", ids}}}}, nil)
+		} else if a.upsert {
+			after, err = findAuditImages(sc, a.collection, a.filter, a.findOptions)
+		}
+		if helper.IsNotNil(err) {
+			return err
+		}
+	}
+	operation := string(a.operationType)
+	if op, ok := sc.Value(operationContextKey{}).(*Operation); ok {
+		operation = op.Name
+	}
+	entries := newAuditEntries(AuditEntry{
+		Namespace:     Namespace{DB: a.collection.Database().Name(), Coll: a.collection.Name()},
+		Operation:     operation,
+		OperationType: a.operationType,
+		Actor:         ActorFromContext(sc),
+		Timestamp:     time.Now(),
+	}, a.before, after)
+	if helper.IsEmpty(entries) {
+		return nil
+	}
+	_, err = sc.Client().Database(a.opt.DatabaseName).Collection(a.opt.CollectionName).InsertMany(sc, entries)
+	return err
+}
+
+func createAuditIndex(ctx context.Context, collection *mongo.Collection, retention time.Duration) error {
+	expireAfterSeconds := int32(retention.Seconds())
+	_, err := collection.Indexes().CreateOne(ctx, parseIndexInputToModel(IndexInput{
+		Keys:    bson.D{{"timestamp", 1}},
+		Options: option.NewIndex().SetName(auditIndexName).SetExpireAfterSeconds(expireAfterSeconds),
+	}))
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && commandErr.HasErrorCode(errCodeIndexOptionsConflict) {
+		// the index already exists with another retention, so we update it
+		err = collection.Database().RunCommand(ctx, bson.D{
+			{"collMod", collection.Name()},
+			{"index", bson.D{{"name", auditIndexName}, {"expireAfterSeconds", expireAfterSeconds}}},
+		}).Err()
+	}
+	return err
+}
+
+func newAuditFindOptions(single bool, sort any, collation *option.Collation, let any) *options.FindOptions {
+	findOptions := options.Find().SetCollation(option.ParseCollationMongoOptions(collation))
+	if helper.IsNotNil(let) {
+		findOptions.SetLet(let)
+	}
+	if single {
+		findOptions.SetLimit(1)
+		if helper.IsNotNil(sort) {
+			findOptions.SetSort(sort)
+		}
+	}
+	return findOptions
+}
+
+func findAuditImages(sc mongo.SessionContext, collection *mongo.Collection, filter any,
+	findOptions *options.FindOptions) ([]bson.Raw, error) {
+	cursor, err := collection.Find(sc, filter, findOptions)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	defer cursor.Close(sc)
+	var result []bson.Raw
+	for cursor.Next(sc) {
+		result = append(result, append(bson.Raw{}, cursor.Current...))
+	}
+	return result, cursor.Err()
+}
+
+func auditIds(documents []bson.Raw) bson.A {
+	ids := bson.A{}
+	for _, document := range documents {
+		if id, err := document.LookupErr("_id"); helper.IsNil(err) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// newAuditEntries returns the entries of the documents in the before and after parameters, matched by the _id, the
+// documents that were not changed are ignored.
+func newAuditEntries(base AuditEntry, before, after []bson.Raw) []any {
+	type images struct {
+		id     bson.RawValue
+		before bson.Raw
+		after  bson.Raw
+	}
+	var keys []string
+	byKey := map[string]*images{}
+	add := func(document bson.Raw, isAfter bool) {
+		id, err := document.LookupErr("_id")
+		if helper.IsNotNil(err) {
+			return
+		}
+		key := string(append([]byte{byte(id.Type)}, id.Value...))
+		image, ok := byKey[key]
+		if !ok {
+			image = &images{id: id}
+			byKey[key] = image
+			keys = append(keys, key)
+		}
+		if isAfter {
+			image.after = document
+		} else {
+			image.before = document
+		}
+	}
+	for _, document := range before {
+		add(document, false)
+	}
+	for _, document := range after {
+		add(document, true)
+	}
+	var result []any
+	for _, key := range keys {
+		image := byKey[key]
+		if helper.IsNotNil(image.before) && helper.IsNotNil(image.after) && bytes.Equal(image.before, image.after) {
+			continue
+		}
+		entry := base
+		entry.Id = primitive.NewObjectID()
+		entry.DocumentId = image.id
+		entry.Before = image.before
+		entry.After = image.after
+		entry.Diff = auditDiff(image.before, image.after)
+		if helper.IsNil(image.before) {
+			// inserted or upserted document
+			entry.OperationType = OperationTypeInsert
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// auditDiff returns the fields changed from the before to the after parameter, the fields of the before document
+// first, followed by the added fields of the after document.
+func auditDiff(before, after bson.Raw) []AuditChange {
+	beforeFields := map[string]bson.RawValue{}
+	var beforeKeys []string
+	flattenAuditDocument("", before, func(field string, value bson.RawValue) {
+		beforeFields[field] = value
+		beforeKeys = append(beforeKeys, field)
+	})
+	afterFields := map[string]bson.RawValue{}
+	var afterKeys []string
+	flattenAuditDocument("", after, func(field string, value bson.RawValue) {
+		afterFields[field] = value
+		afterKeys = append(afterKeys, field)
+	})
+	var result []AuditChange
+	for _, field := range beforeKeys {
+		beforeValue := beforeFields[field]
+		afterValue, ok := afterFields[field]
+		if !ok {
+			result = append(result, AuditChange{Field: field, Before: beforeValue})
+		} else if !beforeValue.Equal(afterValue) {
+			result = append(result, AuditChange{Field: field, Before: beforeValue, After: afterValue})
+		}
+	}
+	for _, field := range afterKeys {
+		if _, ok := beforeFields[field]; !ok {
+			result = append(result, AuditChange{Field: field, After: afterFields[field]})
+		}
+	}
+	return result
+}
+
+func flattenAuditDocument(prefix string, document bson.Raw, fn func(field string, value bson.RawValue)) {
+	for _, element := range auditElements(document) {
+		field := prefix + element.Key()
+		value := element.Value()
+		if helper.Equals(value.Type, bsontype.EmbeddedDocument) && helper.IsNotEmpty(auditElements(value.Document())) {
+			flattenAuditDocument(field+".", value.Document(), fn)
+		} else {
+			fn(field, value)
+		}
+	}
+}
+
+func auditElements(document bson.Raw) []bson.RawElement {
+	elements, _ := document.Elements()
+	return elements
+}
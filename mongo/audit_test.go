@@ -0,0 +1,99 @@
+package mongo
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+	"time"
+)
+
+func TestAuditDiff(t *testing.T) {
+	before, _ := bson.Marshal(bson.D{{"_id", 1}, {"name", "Ana"}, {"address", bson.D{{"city", "SP"}, {"zip", "1"}}},
+		{"emails", bson.A{"a"}}})
+	after, _ := bson.Marshal(bson.D{{"_id", 1}, {"name", "Ana"}, {"address", bson.D{{"city", "RJ"}}},
+		{"emails", bson.A{"a", "b"}}, {"age", 30}})
+	var got []string
+	for _, change := range auditDiff(before, after) {
+		got = append(got, change.Field)
+	}
+	want := []string{"address.city", "address.zip", "emails", "age"}
+	if helper.IsNotEqualTo(got, want) {
+		t.Errorf("auditDiff() fields = %v, want %v", got, want)
+	}
+	if diff := auditDiff(nil, after); helper.IsNotEqualTo(len(diff), 5) {
+		t.Errorf("auditDiff() insert = %v, want 5 changes", diff)
+	}
+}
+
+func TestNewAuditEntries(t *testing.T) {
+	unchanged, _ := bson.Marshal(bson.D{{"_id", 1}, {"name", "Ana"}})
+	before, _ := bson.Marshal(bson.D{{"_id", 2}, {"name", "Ana"}})
+	after, _ := bson.Marshal(bson.D{{"_id", 2}, {"name", "Maria"}})
+	upserted, _ := bson.Marshal(bson.D{{"_id", 3}, {"name", "Ana"}})
+	entries := newAuditEntries(AuditEntry{OperationType: OperationTypeUpdate, Actor: "test"},
+		[]bson.Raw{unchanged, before}, []bson.Raw{unchanged, after, upserted})
+	if helper.IsNotEqualTo(len(entries), 2) {
+		t.Fatalf("newAuditEntries() = %v, want 2 entries", entries)
+	}
+	updated := entries[0].(AuditEntry)
+	if helper.IsNotEqualTo(updated.OperationType, OperationTypeUpdate) || helper.IsNotEqualTo(len(updated.Diff), 1) ||
+		helper.IsNotEqualTo(updated.Actor, "test") {
+		t.Errorf("newAuditEntries() updated = %v", updated)
+	}
+	if inserted := entries[1].(AuditEntry); helper.IsNotEqualTo(inserted.OperationType, OperationTypeInsert) {
+		t.Errorf("newAuditEntries() upserted = %v, want %v", inserted.OperationType, OperationTypeInsert)
+	}
+}
+
+func TestFindAuditEntriesNotEnabled(t *testing.T) {
+	template := &Template{}
+	var names []string
+	template.Use(func(ctx context.Context, op *Operation, next OperationFunc) error {
+		names = append(names, op.Name)
+		return next(ctx, op)
+	})
+	_, err := template.FindAuditEntries(context.TODO(), 1, testStruct{})
+	if helper.IsNotEqualTo(err, ErrAuditNotEnabled) {
+		t.Errorf("FindAuditEntries() error = %v, want %v", err, ErrAuditNotEnabled)
+	}
+	if helper.IsNotEqualTo(names, []string{"FindAuditEntries"}) {
+		t.Errorf("FindAuditEntries() operations = %v", names)
+	}
+}
+
+func TestTemplateAudit(t *testing.T) {
+	initMongoTemplate()
+	ctx := WithActor(context.TODO(), "test")
+	err := mongoTemplate.EnableAudit(ctx, option.NewAudit().SetDatabaseName("test").SetRetention(time.Hour))
+	if helper.IsNotNil(err) {
+		t.Fatal("EnableAudit() error:", err)
+	}
+	defer mongoTemplate.DisableAudit()
+	test := initTestStruct()
+	if err = mongoTemplate.InsertOne(ctx, test); helper.IsNotNil(err) {
+		t.Fatal("InsertOne() error:", err)
+	}
+	_, err = mongoTemplate.UpdateOneById(ctx, test.Id, bson.M{"$set": bson.M{"name": "Audit"}}, testStruct{})
+	if helper.IsNotNil(err) {
+		t.Fatal("UpdateOneById() error:", err)
+	}
+	if _, err = mongoTemplate.DeleteOneById(ctx, test.Id, testStruct{}); helper.IsNotNil(err) {
+		t.Fatal("DeleteOneById() error:", err)
+	}
+	entries, err := mongoTemplate.FindAuditEntries(context.TODO(), test.Id, testStruct{})
+	if helper.IsNotNil(err) {
+		t.Fatal("FindAuditEntries() error:", err)
+	} else if helper.IsNotEqualTo(len(entries), 3) {
+		t.Fatalf("FindAuditEntries() = %v, want 3 entries", entries)
+	}
+	update := entries[1]
+	if helper.IsNotEqualTo(update.Operation, "UpdateOneById") || helper.IsNotEqualTo(update.Actor, "test") ||
+		helper.IsNotEqualTo(len(update.Diff), 1) || helper.IsNotEqualTo(update.Diff[0].Field, "name") {
+		t.Errorf("FindAuditEntries() update = %v", update)
+	}
+	if helper.IsNotEqualTo(entries[2].OperationType, OperationTypeDelete) || helper.IsNotNil(entries[2].After) {
+		t.Errorf("FindAuditEntries() delete = %v", entries[2])
+	}
+}
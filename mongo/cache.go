@@ -0,0 +1,423 @@
+package mongo
+
+import (
+	"container/list"
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache represents the storage of the documents read by FindOneById and FindOne, see Template.SetCache.
+type Cache interface {
+	// Get returns the document stored with the key, and false if there is none or if it is expired.
+	Get(ctx context.Context, key string) (bson.Raw, bool, error)
+	// Set stores the document with the key for the ttl, if the ttl is zero, the document does not expire.
+	Set(ctx context.Context, key string, document bson.Raw, ttl time.Duration) error
+	// Delete removes the documents stored with the keys, missing keys are ignored.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// CacheStore represents an external key value store, e.g. Redis or Memcached, that can be used as Cache through
+// NewStoreCache, so the cached documents are shared between processes.
+type CacheStore interface {
+	// Get returns the value stored with the key, or nil if there is none.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores the value with the key for the ttl, if the ttl is zero, the value does not expire.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the values stored with the keys.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// LRUCache is a Cache that keeps the documents in memory, when the capacity is reached, the least recently used
+// document is removed.
+type LRUCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+type lruEntry struct {
+	key       string
+	document  bson.Raw
+	expiresAt time.Time
+}
+
+type storeCache struct {
+	store CacheStore
+}
+
+// templateCache is the cache of a Template, it also indexes by namespace the keys set by this process, so the keys
+// of the filters and projections are evicted by the writes on the collection, and the expired keys are released after
+// the option.Cache.TTL.
+type templateCache struct {
+	cache   Cache
+	opt     *option.Cache
+	mutex   sync.Mutex
+	keysBy  map[string]map[string]cacheKey
+	sweptAt time.Time
+}
+
+// cacheKey represents a key set on the cache by the Template.
+type cacheKey struct {
+	// byId true if it is the key of the _id of the document, without projection
+	byId      bool
+	expiresAt time.Time
+}
+
+// NewLRUCache creates a LRUCache that keeps at most the capacity parameter documents, if it is less than or equal to
+// zero, the capacity is 1000.
+func NewLRUCache(capacity int) *LRUCache {
+	if helper.IsLessThanOrEqual(capacity, 0) {
+		capacity = 1000
+	}
+	return &LRUCache{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+// NewStoreCache creates a Cache that stores the documents on the store parameter.
+func NewStoreCache(store CacheStore) Cache {
+	return &storeCache{store: store}
+}
+
+// Get returns the document stored with the key, and false if there is none or if it is expired.
+func (l *LRUCache) Get(_ context.Context, key string) (bson.Raw, bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		l.remove(element)
+		return nil, false, nil
+	}
+	l.order.MoveToFront(element)
+	return entry.document, true, nil
+}
+
+// Set stores the document with the key for the ttl, if the ttl is zero, the document does not expire.
+func (l *LRUCache) Set(_ context.Context, key string, document bson.Raw, ttl time.Duration) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	var expiresAt time.Time
+	if helper.IsGreaterThan(ttl, 0) {
+		expiresAt = time.Now().Add(ttl)
+	}
+	if element, ok := l.entries[key]; ok {
+		element.Value = &lruEntry{key: key, document: document, expiresAt: expiresAt}
+		l.order.MoveToFront(element)
+		return nil
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, document: document, expiresAt: expiresAt})
+	for helper.IsGreaterThan(l.order.Len(), l.capacity) {
+		l.remove(l.order.Back())
+	}
+	return nil
+}
+
+// Delete removes the documents stored with the keys, missing keys are ignored.
+func (l *LRUCache) Delete(_ context.Context, keys ...string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, key := range keys {
+		if element, ok := l.entries[key]; ok {
+			l.remove(element)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of documents on the cache, including the expired ones not removed yet.
+func (l *LRUCache) Len() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.order.Len()
+}
+
+func (l *LRUCache) remove(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.entries, element.Value.(*lruEntry).key)
+}
+
+func (s *storeCache) Get(ctx context.Context, key string) (bson.Raw, bool, error) {
+	value, err := s.store.Get(ctx, key)
+	if helper.IsNotNil(err) || helper.IsEmpty(value) {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (s *storeCache) Set(ctx context.Context, key string, document bson.Raw, ttl time.Duration) error {
+	return s.store.Set(ctx, key, document, ttl)
+}
+
+func (s *storeCache) Delete(ctx context.Context, keys ...string) error {
+	return s.store.Delete(ctx, keys...)
+}
+
+// SetCache sets the cache read through by FindOneById and FindOne, if the cache parameter is nil, the cache is
+// disabled.
+//
+// The writes of the Template evict from the cache the documents written, with the documents read by filter or
+// projection on the collection, e.g. UpdateOneById and InsertOne, the writes by filter, e.g. UpdateMany and DeleteOne,
+// evict all the documents of the collection cached by this process. The writes executed directly on the mongo driver,
+// and the writes of other processes, are only seen after the option.Cache.TTL, unless WatchCacheInvalidation is
+// running. The writes on a session kept open, see option.Update.DisableAutoCloseSession, evict the documents again when
+// the session is committed, aborted or closed.
+//
+// The cache can be set while the operations are executed, the operations in progress finish with the prior cache.
+//
+// The opts parameter can be used to specify options for the cache (see the option.Cache documentation.)
+func (t *Template) SetCache(cache Cache, opts ...*option.Cache) {
+	if helper.IsNil(cache) {
+		t.cache.Store(nil)
+		return
+	}
+	t.cache.Store(&templateCache{
+		cache:  cache,
+		opt:    option.MergeCacheByParams(opts),
+		keysBy: map[string]map[string]cacheKey{},
+	})
+}
+
+// WatchCacheInvalidation evicts from the cache the documents inserted, updated, replaced or deleted on the deployment,
+// including the writes of other processes, until the ctx is done. It must be called in a goroutine, since it blocks
+// while the change stream is open, see WatchWithHandlerE.
+//
+// The opts parameter can be used to specify options for change stream creation (see the option.WatchWithHandler
+// documentation), e.g. the DatabaseName and CollectionName to watch only the cached collections.
+func (t *Template) WatchCacheInvalidation(ctx context.Context, opts ...*option.WatchWithHandler) error {
+	if helper.IsNil(t.cache.Load()) {
+		return ErrCacheNotConfigured
+	}
+	pipeline := Pipeline{bson.D{{"$match", bson.D{{"operationType", bson.D{{"$in", []OperationType{
+		OperationTypeInsert,
+		OperationTypeUpdate,
+		OperationTypeReplace,
+		OperationTypeDelete,
+	}}}}}}}}
+	return t.WatchWithHandlerE(ctx, pipeline, func(eventCtx *EventContext) error {
+		if cache := t.cache.Load(); helper.IsNotNil(cache) {
+			cache.evict(eventCtx, eventCtx.Event.NS.DB, eventCtx.Event.NS.Coll, eventCtx.Raw.Lookup("documentKey", "_id"))
+		}
+		return nil
+	}, opts...)
+}
+
+// get decodes the document cached with the key on the dest parameter, returning false if there is none.
+func (c *templateCache) get(ctx context.Context, key string, dest any) bool {
+	document, ok, err := c.cache.Get(ctx, key)
+	if helper.IsNotNil(err) {
+		logger.Error("error get document from cache, key:", key, "err:", err)
+		return false
+	} else if !ok {
+		return false
+	}
+	err = bson.Unmarshal(document, dest)
+	if helper.IsNotNil(err) {
+		logger.Error("error decode document from cache, key:", key, "err:", err)
+		return false
+	}
+	return true
+}
+
+func (c *templateCache) set(ctx context.Context, databaseName, collectionName, key string, document bson.Raw) {
+	err := c.cache.Set(ctx, key, document, c.opt.TTL)
+	if helper.IsNotNil(err) {
+		logger.Error("error set document on cache, key:", key, "err:", err)
+		return
+	}
+	now := time.Now()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !now.Before(c.sweptAt.Add(c.opt.TTL)) {
+		c.sweep(now)
+	}
+	namespace := databaseName + "." + collectionName
+	keys, ok := c.keysBy[namespace]
+	if !ok {
+		keys = map[string]cacheKey{}
+		c.keysBy[namespace] = keys
+	}
+	id, err := document.LookupErr("_id")
+	keys[key] = cacheKey{
+		byId:      helper.IsNil(err) && helper.Equals(c.idKey(databaseName, collectionName, id), key),
+		expiresAt: now.Add(c.opt.TTL),
+	}
+}
+
+// evict removes from the cache the documents of the ids parameter, with the keys of the filters and projections set
+// by this process on the collection, since the write may change the documents they return.
+func (c *templateCache) evict(ctx context.Context, databaseName, collectionName string, ids ...bson.RawValue) {
+	namespace := databaseName + "." + collectionName
+	var keys []string
+	c.mutex.Lock()
+	for _, id := range ids {
+		if helper.IsNotEqualTo(id.Type, bsontype.Type(0)) {
+			idKey := c.idKey(databaseName, collectionName, id)
+			keys = append(keys, idKey)
+			delete(c.keysBy[namespace], idKey)
+		}
+	}
+	for key, cached := range c.keysBy[namespace] {
+		if !cached.byId {
+			keys = append(keys, key)
+			delete(c.keysBy[namespace], key)
+		}
+	}
+	c.mutex.Unlock()
+	c.delete(ctx, keys)
+}
+
+// evictNamespace removes from the cache all the keys set by this process on the collection, or on the database if the
+// collectionName parameter is empty, since the documents written are unknown.
+func (c *templateCache) evictNamespace(ctx context.Context, databaseName, collectionName string) {
+	var keys []string
+	c.mutex.Lock()
+	for namespace, namespaceKeys := range c.keysBy {
+		if helper.IsNotEqualTo(namespace, databaseName+"."+collectionName) &&
+			(helper.IsNotEmpty(collectionName) || !strings.HasPrefix(namespace, databaseName+".")) {
+			continue
+		}
+		for key := range namespaceKeys {
+			keys = append(keys, key)
+		}
+		delete(c.keysBy, namespace)
+	}
+	c.mutex.Unlock()
+	c.delete(ctx, keys)
+}
+
+// sweep releases the keys expired on the cache, it must be called with the mutex locked.
+func (c *templateCache) sweep(now time.Time) {
+	for namespace, keys := range c.keysBy {
+		for key, cached := range keys {
+			if now.After(cached.expiresAt) {
+				delete(keys, key)
+			}
+		}
+		if helper.IsEmpty(keys) {
+			delete(c.keysBy, namespace)
+		}
+	}
+	c.sweptAt = now
+}
+
+func (c *templateCache) delete(ctx context.Context, keys []string) {
+	if helper.IsEmpty(keys) {
+		return
+	}
+	err := c.cache.Delete(context.WithoutCancel(ctx), keys...)
+	if helper.IsNotNil(err) {
+		logger.Error("error delete documents from cache, keys:", keys, "err:", err)
+	}
+}
+
+// findOneKey returns the cache key of the find one operation, or empty if the operation cannot be cached.
+func (c *templateCache) findOneKey(databaseName, collectionName string, filter any, opt *option.FindOne) string {
+	if *opt.DisableCache || isTrue(opt.ReturnKey) || isTrue(opt.ShowRecordID) || isTrue(opt.AllowPartialResults) {
+		return ""
+	}
+	b, err := bson.Marshal(filter)
+	if helper.IsNotNil(err) {
+		return ""
+	}
+	var key string
+	elements, _ := bson.Raw(b).Elements()
+	if helper.Equals(len(elements), 1) && helper.Equals(elements[0].Key(), "_id") &&
+		helper.IsNotEqualTo(elements[0].Value().Type, bson.TypeEmbeddedDocument) {
+		key = c.idKey(databaseName, collectionName, elements[0].Value())
+	} else {
+		key = c.opt.KeyPrefix + databaseName + "." + collectionName + ":filter:" + bson.Raw(b).String()
+	}
+	if helper.IsNil(opt.Projection) && helper.IsNil(opt.Collation) && helper.IsNil(opt.Max) &&
+		helper.IsNil(opt.Min) && helper.IsNil(opt.Skip) && helper.IsNil(opt.Sort) {
+		return key
+	}
+	b, err = bson.Marshal(bson.D{
+		{"projection", opt.Projection},
+		{"collation", opt.Collation},
+		{"max", opt.Max},
+		{"min", opt.Min},
+		{"skip", opt.Skip},
+		{"sort", opt.Sort},
+	})
+	if helper.IsNotNil(err) {
+		return ""
+	}
+	return key + ":" + bson.Raw(b).String()
+}
+
+func (c *templateCache) idKey(databaseName, collectionName string, id bson.RawValue) string {
+	return c.opt.KeyPrefix + databaseName + "." + collectionName + ":id:" + id.String()
+}
+
+func (t *Template) evictCacheById(ctx context.Context, ref, id any) {
+	cache := t.cache.Load()
+	if helper.IsNil(cache) {
+		return
+	}
+	databaseName, collectionName, err := getMongoNamesByAny(ref)
+	if helper.IsNotNil(err) {
+		return
+	}
+	b, err := bson.Marshal(bson.D{{"_id", id}})
+	if helper.IsNil(err) {
+		t.evictCache(ctx, func(ctx context.Context) {
+			cache.evict(ctx, databaseName, collectionName, bson.Raw(b).Lookup("_id"))
+		})
+	}
+}
+
+// evictCacheByRef removes from the cache the keys of the collection of the ref parameter, if the all parameter is
+// false, only the keys of the filters and projections, e.g. after an insert, otherwise all the keys, e.g. after a write
+// by filter.
+func (t *Template) evictCacheByRef(ctx context.Context, ref any, all bool) {
+	cache := t.cache.Load()
+	if helper.IsNil(cache) {
+		return
+	}
+	databaseName, collectionName, err := getMongoNamesByAny(ref)
+	if helper.IsNotNil(err) {
+		return
+	}
+	t.evictCache(ctx, func(ctx context.Context) {
+		if all {
+			cache.evictNamespace(ctx, databaseName, collectionName)
+		} else {
+			cache.evict(ctx, databaseName, collectionName)
+		}
+	})
+}
+
+// evictCache calls the fn parameter, if the write was executed on a session still open, the fn is kept to be called
+// again when its transaction is committed or aborted, since a read before it puts the old document back in the cache.
+func (t *Template) evictCache(ctx context.Context, fn func(ctx context.Context)) {
+	fn(ctx)
+	if helper.IsNotNil(t.session) {
+		t.pendingEvictions = append(t.pendingEvictions, fn)
+	}
+}
+
+func (t *Template) evictPendingCache(ctx context.Context) {
+	pendingEvictions := t.pendingEvictions
+	t.pendingEvictions = nil
+	for _, fn := range pendingEvictions {
+		fn(ctx)
+	}
+}
+
+func isTrue(b *bool) bool {
+	return helper.IsNotNil(b) && *b
+}
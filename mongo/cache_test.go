@@ -0,0 +1,124 @@
+package mongo
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"testing"
+	"time"
+)
+
+func TestLRUCache(t *testing.T) {
+	ctx := context.TODO()
+	cache := NewLRUCache(2)
+	document, _ := bson.Marshal(bson.M{"name": "Test"})
+	_ = cache.Set(ctx, "a", document, 0)
+	_ = cache.Set(ctx, "b", document, 0)
+	_, _, _ = cache.Get(ctx, "a")
+	_ = cache.Set(ctx, "c", document, 0)
+	if _, ok, _ := cache.Get(ctx, "b"); ok {
+		t.Error("Get() expected least recently used key b evicted")
+	}
+	if _, ok, _ := cache.Get(ctx, "a"); !ok {
+		t.Error("Get() expected key a cached")
+	}
+	_ = cache.Set(ctx, "d", document, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok, _ := cache.Get(ctx, "d"); ok {
+		t.Error("Get() expected key d expired")
+	}
+	_ = cache.Delete(ctx, "a", "unknown")
+	if helper.IsNotEqualTo(cache.Len(), 0) {
+		t.Errorf("Len() = %v, expected 0", cache.Len())
+	}
+}
+
+func TestTemplateCacheEvict(t *testing.T) {
+	ctx := context.TODO()
+	lru := NewLRUCache(0)
+	template := &Template{}
+	template.SetCache(lru, option.NewCache().SetKeyPrefix("test:"))
+	id := primitive.NewObjectID()
+	document, _ := bson.Marshal(testStruct{Id: id, Name: "Test"})
+	opt := option.MergeFindOneByParams(nil)
+	idKey := template.cache.Load().findOneKey("test", "test", bson.D{{"_id", id}}, opt)
+	filterKey := template.cache.Load().findOneKey("test", "test", bson.D{{"name", "Test"}}, opt)
+	projectionKey := template.cache.Load().findOneKey("test", "test", bson.M{"_id": id},
+		option.MergeFindOneByParams([]*option.FindOne{option.NewFindOne().SetProjection(bson.M{"name": 1})}))
+	if helper.IsEmpty(idKey) || helper.Equals(idKey, filterKey) || helper.Equals(idKey, projectionKey) {
+		t.Fatalf("findOneKey() id = %v, filter = %v, projection = %v", idKey, filterKey, projectionKey)
+	}
+	disabled := option.MergeFindOneByParams([]*option.FindOne{option.NewFindOne().SetDisableCache(true)})
+	if helper.IsNotEmpty(template.cache.Load().findOneKey("test", "test", bson.D{{"_id", id}}, disabled)) {
+		t.Error("findOneKey() expected empty key with cache disabled")
+	}
+	for _, key := range []string{idKey, filterKey, projectionKey} {
+		template.cache.Load().set(ctx, "test", "test", key, document)
+	}
+	var dest testStruct
+	if !template.cache.Load().get(ctx, filterKey, &dest) || helper.IsNotEqualTo(dest.Id, id) {
+		t.Errorf("get() dest = %+v", dest)
+	}
+	template.evictCacheById(ctx, testStruct{}, id)
+	if helper.IsNotEqualTo(lru.Len(), 0) {
+		t.Errorf("evictCacheById() cache len = %v, expected 0", lru.Len())
+	}
+	for _, key := range []string{idKey, filterKey} {
+		template.cache.Load().set(ctx, "test", "test", key, document)
+	}
+	template.evictCacheByRef(ctx, testStruct{}, false)
+	if _, ok, _ := lru.Get(ctx, filterKey); ok || helper.IsNotEqualTo(lru.Len(), 1) {
+		t.Errorf("evictCacheByRef() cache len = %v, expected only the id key", lru.Len())
+	}
+	template.evictCacheByRef(ctx, testStruct{}, true)
+	if helper.IsNotEqualTo(lru.Len(), 0) || helper.IsNotEmpty(template.cache.Load().keysBy) {
+		t.Errorf("evictCacheByRef() cache len = %v, expected 0", lru.Len())
+	}
+	template.SetCache(nil)
+	if helper.IsNotNil(template.cache.Load()) {
+		t.Error("SetCache() expected cache disabled")
+	}
+}
+
+func TestTemplateCachePendingEvictions(t *testing.T) {
+	ctx := context.TODO()
+	lru := NewLRUCache(0)
+	template := &Template{session: struct{ mongo.Session }{}}
+	template.SetCache(lru)
+	id := primitive.NewObjectID()
+	document, _ := bson.Marshal(testStruct{Id: id, Name: "Test"})
+	key := template.cache.Load().findOneKey("test", "test", bson.D{{"_id", id}}, option.MergeFindOneByParams(nil))
+	template.evictCacheById(ctx, testStruct{}, id)
+	template.evictCacheByRef(ctx, testStruct{}, true)
+	if helper.IsNotEqualTo(len(template.pendingEvictions), 2) {
+		t.Fatalf("evictCache() pending evictions = %v, expected 2", len(template.pendingEvictions))
+	}
+	// a read before the commit puts the document back in the cache
+	template.cache.Load().set(ctx, "test", "test", key, document)
+	template.evictPendingCache(ctx)
+	if helper.IsNotEqualTo(lru.Len(), 0) || helper.IsNotEmpty(template.pendingEvictions) {
+		t.Errorf("evictPendingCache() cache len = %v, expected 0", lru.Len())
+	}
+	template.session = nil
+	template.evictCacheById(ctx, testStruct{}, id)
+	if helper.IsNotEmpty(template.pendingEvictions) {
+		t.Error("evictCache() expected no pending evictions without an open session")
+	}
+}
+
+func TestTemplateCacheSweep(t *testing.T) {
+	ctx := context.TODO()
+	template := &Template{}
+	template.SetCache(NewLRUCache(0), option.NewCache().SetTTL(time.Millisecond))
+	document, _ := bson.Marshal(testStruct{Id: primitive.NewObjectID(), Name: "Test"})
+	template.cache.Load().set(ctx, "test", "test", "a", document)
+	time.Sleep(5 * time.Millisecond)
+	template.cache.Load().set(ctx, "test", "other", "b", document)
+	keysBy := template.cache.Load().keysBy
+	if _, ok := keysBy["test.test"]; ok || helper.IsNotEqualTo(len(keysBy["test.other"]), 1) {
+		t.Errorf("set() keys = %v, expected the expired keys released", keysBy)
+	}
+}
@@ -0,0 +1,76 @@
+package mongo
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// operationConcern represents the read preference and read concern of an operation, they are applied on the database
+// and collection clones returned by getMongoInfosByAny. The write concern is applied on the transaction started with
+// the template session, since the write concern of the operations within a transaction is ignored, see startSession.
+type operationConcern struct {
+	readPreference *readpref.ReadPref
+	readConcern    *readconcern.ReadConcern
+}
+
+func newReadConcern(readPreference *option.ReadPreference, readConcern option.ReadConcern) (operationConcern,
+	error) {
+	if helper.IsNil(readPreference) {
+		readPreference = globalOption.ReadPreference
+	}
+	if helper.IsEmpty(readConcern) {
+		readConcern = globalOption.ReadConcern
+	}
+	mongoReadPreference, err := option.ParseReadPreference(readPreference)
+	return operationConcern{
+		readPreference: mongoReadPreference,
+		readConcern:    option.ParseReadConcern(readConcern),
+	}, err
+}
+
+func (o operationConcern) databaseOptions() *options.DatabaseOptions {
+	result := options.Database()
+	if helper.IsNotNil(o.readPreference) {
+		result.SetReadPreference(o.readPreference)
+	}
+	if helper.IsNotNil(o.readConcern) {
+		result.SetReadConcern(o.readConcern)
+	}
+	return result
+}
+
+// readContext returns the context of a read operation, if the causalConsistency parameter is true, the read runs on
+// the open session of the template, or on a new causally consistent session that starts after the last write of the
+// template, so the read sees that write. The returned function ends the new session.
+func (t *Template) readContext(ctx context.Context, causalConsistency *bool) (context.Context, func(), error) {
+	if helper.IsNil(causalConsistency) {
+		causalConsistency = &globalOption.CausalConsistency
+	}
+	if !*causalConsistency {
+		return ctx, func() {}, nil
+	} else if helper.IsNotNil(t.session) {
+		return mongo.NewSessionContext(ctx, t.session), func() {}, nil
+	}
+	session, err := t.client.StartSession(options.Session().SetCausalConsistency(true))
+	if helper.IsNotNil(err) {
+		return nil, nil, err
+	}
+	if helper.IsNotEmpty(t.lastClusterTime) {
+		err = session.AdvanceClusterTime(t.lastClusterTime)
+	}
+	if helper.IsNil(err) && helper.IsNotNil(t.lastOperationTime) {
+		err = session.AdvanceOperationTime(t.lastOperationTime)
+	}
+	if helper.IsNotNil(err) {
+		session.EndSession(ctx)
+		return nil, nil, err
+	}
+	return mongo.NewSessionContext(ctx, session), func() {
+		session.EndSession(context.WithoutCancel(ctx))
+	}, nil
+}
@@ -0,0 +1,49 @@
+package mongo
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"testing"
+	"time"
+)
+
+func TestNewReadConcern(t *testing.T) {
+	defer func(global *option.Global) {
+		globalOption = global
+	}(globalOption)
+	globalOption = &option.Global{
+		ReadPreference: option.NewReadPreference(option.ReadPreferenceSecondaryPreferred),
+		ReadConcern:    option.ReadConcernMajority,
+	}
+	concern, err := newReadConcern(nil, "")
+	if helper.IsNotNil(err) {
+		t.Fatal("newReadConcern() error:", err)
+	}
+	if helper.IsNotEqualTo(concern.readPreference.Mode(), readpref.SecondaryPreferredMode) ||
+		helper.IsNotEqualTo(concern.readConcern.Level, "majority") {
+		t.Errorf("newReadConcern() global = %+v", concern)
+	}
+	concern, err = newReadConcern(option.NewReadPreference(option.ReadPreferenceNearest).
+		SetTagSets(map[string]string{"region": "br"}).
+		SetMaxStaleness(2*time.Minute), option.ReadConcernLocal)
+	if helper.IsNotNil(err) {
+		t.Fatal("newReadConcern() error:", err)
+	}
+	maxStaleness, _ := concern.readPreference.MaxStaleness()
+	if helper.IsNotEqualTo(concern.readPreference.Mode(), readpref.NearestMode) ||
+		helper.IsNotEqualTo(len(concern.readPreference.TagSets()), 1) ||
+		helper.IsNotEqualTo(maxStaleness, 2*time.Minute) ||
+		helper.IsNotEqualTo(concern.readConcern.Level, "local") {
+		t.Errorf("newReadConcern() = %+v", concern)
+	}
+	_, err = newReadConcern(option.NewReadPreference(option.ReadPreferencePrimary).
+		SetTagSets(map[string]string{"region": "br"}), "")
+	if helper.IsNil(err) {
+		t.Error("newReadConcern() expected error tag sets with primary mode")
+	}
+	writeConcern := option.ParseWriteConcern(option.NewWriteConcern(option.WriteConcernMajority).SetJournal(true))
+	if helper.IsNotEqualTo(writeConcern.W, "majority") || !*writeConcern.Journal {
+		t.Errorf("ParseWriteConcern() = %+v", writeConcern)
+	}
+}
@@ -6,3 +6,68 @@ const (
 	SortDesc Sort = -1
 	SortAsc  Sort = 1
 )
+
+type ValidationLevel string
+
+const (
+	// ValidationLevelOff disables the validation for inserts and updates.
+	ValidationLevelOff ValidationLevel = "off"
+	// ValidationLevelStrict applies the validation rules to all inserts and all updates.
+	ValidationLevelStrict ValidationLevel = "strict"
+	// ValidationLevelModerate applies the validation rules to inserts and to updates on existing valid documents.
+	ValidationLevelModerate ValidationLevel = "moderate"
+)
+
+type ValidationAction string
+
+const (
+	// ValidationActionError rejects any insert or update that violates the validation criteria.
+	ValidationActionError ValidationAction = "error"
+	// ValidationActionWarn records the violations in the server log, but allows the insert or update to proceed.
+	ValidationActionWarn ValidationAction = "warn"
+)
+
+type OutboxStatus string
+
+const (
+	// OutboxStatusPending the message is waiting to be dispatched or retried.
+	OutboxStatusPending OutboxStatus = "pending"
+	// OutboxStatusProcessing the message was claimed by a relay and is being published.
+	OutboxStatusProcessing OutboxStatus = "processing"
+	// OutboxStatusDispatched the message was published successfully.
+	OutboxStatusDispatched OutboxStatus = "dispatched"
+	// OutboxStatusDeadLetter the message reached the maximum publish attempts and was moved to the dead letter
+	// collection, it is kept on the outbox collection only if the relay stopped while moving it.
+	OutboxStatusDeadLetter OutboxStatus = "dead_letter"
+)
+
+type OperationType string
+
+const (
+	OperationTypeInsert                   OperationType = "insert"
+	OperationTypeUpdate                   OperationType = "update"
+	OperationTypeReplace                  OperationType = "replace"
+	OperationTypeDelete                   OperationType = "delete"
+	OperationTypeDrop                     OperationType = "drop"
+	OperationTypeRename                   OperationType = "rename"
+	OperationTypeDropDatabase             OperationType = "dropDatabase"
+	OperationTypeInvalidate               OperationType = "invalidate"
+	OperationTypeCreate                   OperationType = "create"
+	OperationTypeCreateIndexes            OperationType = "createIndexes"
+	OperationTypeDropIndexes              OperationType = "dropIndexes"
+	OperationTypeModify                   OperationType = "modify"
+	OperationTypeShardCollection          OperationType = "shardCollection"
+	OperationTypeReshardCollection        OperationType = "reshardCollection"
+	OperationTypeRefineCollectionShardKey OperationType = "refineCollectionShardKey"
+)
+
+// IsDDL returns true if the operation type is a data definition event, that is, any event that does not change a
+// document.
+func (o OperationType) IsDDL() bool {
+	switch o {
+	case OperationTypeInsert, OperationTypeUpdate, OperationTypeReplace, OperationTypeDelete:
+		return false
+	default:
+		return true
+	}
+}
@@ -1,6 +1,10 @@
 package mongo
 
-import "errors"
+import (
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"strings"
+)
 
 var ErrRefDocument = errors.New("mongo: ref document needs to be structure or slice of the struct")
 var ErrDatabaseNotConfigured = errors.New("mongo: database not correct configured")
@@ -13,3 +17,56 @@ var ErrDestIsNotPointer = errors.New("mongo: dest param is not a pointer")
 var ErrDestIsNotStruct = errors.New("mongo: dest param is not a struct")
 var ErrNoDocuments = errors.New("mongo: no documents in result")
 var ErrNoOpenSession = errors.New("mongo: no open session")
+var ErrLockNotHeld = errors.New("mongo: lock is not held")
+var ErrLockAlreadyAcquired = errors.New("mongo: lock already acquired by this instance")
+var ErrEventHandlerIsNil = errors.New("mongo: event handler is nil")
+var ErrEventRouterIsEmpty = errors.New("mongo: event router has no routes")
+var ErrEventHandlerTimeout = errors.New("mongo: event handler timed out")
+var ErrOutboxTopicIsEmpty = errors.New("mongo: outbox event topic is empty")
+var ErrOutboxPublisherIsNil = errors.New("mongo: outbox publisher is nil")
+var ErrCacheNotConfigured = errors.New("mongo: cache not configured on template")
+var ErrInvalidPollingToken = errors.New("mongo: invalid polling resume token")
+var ErrAuditNotEnabled = errors.New("mongo: audit not enabled on template")
+var ErrVersionNotFound = errors.New("mongo: document version not found")
+
+const errCodeNamespaceNotFound = 26
+const errCodeIndexOptionsConflict = 85
+
+// ValidationError is returned when a document does not satisfy the validate tags of its structure, see the
+// option.Global.ValidateDocument documentation.
+type ValidationError struct {
+	// Fields the fields that failed validation
+	Fields []FieldValidationError
+}
+
+// FieldValidationError represents a validate rule that failed on a document field.
+type FieldValidationError struct {
+	// Path the field path on the document, e.g. "Address.Street", on InsertMany operation it is prefixed by the
+	// document index, e.g. "[1].Address.Street"
+	Path string
+	// Rule the validate rule that failed, e.g. "required"
+	Rule string
+	// Param the rule parameter, e.g. "5" on "min=5"
+	Param string
+	// Value the field value that failed
+	Value any
+}
+
+func (v *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("mongo: document validation failed: ")
+	for i, field := range v.Fields {
+		if helper.IsGreaterThan(i, 0) {
+			b.WriteString(", ")
+		}
+		b.WriteString(field.Path)
+		b.WriteString(" (")
+		b.WriteString(field.Rule)
+		if helper.IsNotEmpty(field.Param) {
+			b.WriteString("=")
+			b.WriteString(field.Param)
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
@@ -0,0 +1,344 @@
+package mongo
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"slices"
+	"time"
+)
+
+// ExplainResult represents the winning plan and the execution statistics of an explained operation, see
+// Template.ExplainFind, Template.ExplainAggregate, Template.ExplainCount and Template.ExplainUpdate.
+type ExplainResult struct {
+	// Stages the stages of the winning plan, from the root stage to its inputs, e.g. [FETCH IXSCAN]
+	Stages []string
+	// IndexName the name of the index used by the winning plan, empty if the plan does not use any index
+	IndexName string
+	// KeysExamined the number of index keys examined
+	KeysExamined int64
+	// DocsExamined the number of documents examined
+	DocsExamined int64
+	// Returned the number of documents returned
+	Returned int64
+	// ExecutionTime the execution time of the winning plan
+	ExecutionTime time.Duration
+	// Raw the explain document returned by the server
+	Raw bson.Raw
+}
+
+// ExplainFind explains a find command with the "executionStats" verbosity, if successful it returns the winning plan
+// and the execution statistics of the query with return error nil. Otherwise, it returns corresponding error.
+//
+// The filter, ref and opts parameters are the same of the Template.Find operation, the ref parameter must be a
+// structure with the database and collection tags configured.
+//
+// For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/explain/.
+func (t *Template) ExplainFind(ctx context.Context, filter, ref any, opts ...*option.Find) (*ExplainResult, error) {
+	op := &Operation{Name: "ExplainFind", Ref: ref, Filter: filter, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*ExplainResult, error) {
+		opt := option.MergeFindByParams(operationOptions(op, opts))
+		concern, err := newReadConcern(opt.ReadPreference, opt.ReadConcern)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		database, collection, err := t.getMongoInfosByAny(ref, concern)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		command := bson.D{{"find", collection.Name()}, {"filter", explainFilter(op.Filter)}}
+		command = appendExplainOptions(command,
+			bson.E{Key: "sort", Value: opt.Sort},
+			bson.E{Key: "projection", Value: opt.Projection},
+			bson.E{Key: "hint", Value: opt.Hint},
+			bson.E{Key: "skip", Value: opt.Skip},
+			bson.E{Key: "limit", Value: opt.Limit},
+			bson.E{Key: "collation", Value: explainCollation(opt.Collation)},
+			bson.E{Key: "comment", Value: opt.Comment},
+			bson.E{Key: "max", Value: opt.Max},
+			bson.E{Key: "min", Value: opt.Min},
+			bson.E{Key: "let", Value: opt.Let},
+			bson.E{Key: "allowDiskUse", Value: opt.AllowDiskUse},
+			bson.E{Key: "maxTimeMS", Value: explainMaxTime(opt.MaxTime)},
+		)
+		return runExplain(ctx, database, command, concern.readPreference)
+	})
+}
+
+// ExplainAggregate explains an aggregate command with the "executionStats" verbosity, if successful it returns the
+// winning plan and the execution statistics of the pipeline with return error nil. Otherwise, it returns
+// corresponding error.
+//
+// The pipeline, ref and opts parameters are the same of the Template.Aggregate operation, the ref parameter must be a
+// structure with the database and collection tags configured.
+//
+// For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/explain/.
+func (t *Template) ExplainAggregate(ctx context.Context, pipeline, ref any, opts ...*option.Aggregate) (
+	*ExplainResult, error) {
+	op := &Operation{Name: "ExplainAggregate", Ref: ref, Pipeline: pipeline, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*ExplainResult, error) {
+		opt := option.MergeAggregateByParams(operationOptions(op, opts))
+		concern, err := newReadConcern(opt.ReadPreference, opt.ReadConcern)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		database, collection, err := t.getMongoInfosByAny(ref, concern)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		if helper.IsNil(op.Pipeline) {
+			op.Pipeline = Pipeline{}
+		}
+		command := bson.D{{"aggregate", collection.Name()}, {"pipeline", op.Pipeline}, {"cursor", bson.D{}}}
+		command = appendExplainOptions(command,
+			bson.E{Key: "hint", Value: opt.Hint},
+			bson.E{Key: "collation", Value: explainCollation(opt.Collation)},
+			bson.E{Key: "comment", Value: opt.Comment},
+			bson.E{Key: "let", Value: opt.Let},
+			bson.E{Key: "allowDiskUse", Value: opt.AllowDiskUse},
+			bson.E{Key: "maxTimeMS", Value: explainMaxTime(opt.MaxTime)},
+		)
+		return runExplain(ctx, database, command, concern.readPreference)
+	})
+}
+
+// ExplainCount explains a count command with the "executionStats" verbosity, if successful it returns the winning
+// plan and the execution statistics of the count with return error nil. Otherwise, it returns corresponding error.
+//
+// The filter, ref and opts parameters are the same of the Template.CountDocuments operation, the ref parameter must
+// be a structure with the database and collection tags configured.
+//
+// For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/explain/.
+func (t *Template) ExplainCount(ctx context.Context, filter, ref any, opts ...*option.Count) (*ExplainResult, error) {
+	op := &Operation{Name: "ExplainCount", Ref: ref, Filter: filter, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*ExplainResult, error) {
+		opt := option.MergeCountByParams(operationOptions(op, opts))
+		concern, err := newReadConcern(opt.ReadPreference, opt.ReadConcern)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		database, collection, err := t.getMongoInfosByAny(ref, concern)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		command := bson.D{{"count", collection.Name()}, {"query", explainFilter(op.Filter)}}
+		command = appendExplainOptions(command,
+			bson.E{Key: "hint", Value: opt.Hint},
+			bson.E{Key: "skip", Value: opt.Skip},
+			bson.E{Key: "limit", Value: opt.Limit},
+			bson.E{Key: "collation", Value: explainCollation(opt.Collation)},
+			bson.E{Key: "comment", Value: opt.Comment},
+			bson.E{Key: "maxTimeMS", Value: explainMaxTime(opt.MaxTime)},
+		)
+		return runExplain(ctx, database, command, concern.readPreference)
+	})
+}
+
+// ExplainUpdate explains an update command with the "executionStats" verbosity, if successful it returns the winning
+// plan and the execution statistics of the update with return error nil. Otherwise, it returns corresponding error.
+// The update is explained as the Template.UpdateMany operation, and no document is modified.
+//
+// The filter, update, ref and opts parameters are the same of the Template.UpdateMany operation, the ref parameter
+// must be a structure with the database and collection tags configured.
+//
+// For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/explain/.
+func (t *Template) ExplainUpdate(ctx context.Context, filter, update, ref any, opts ...*option.Update) (
+	*ExplainResult, error) {
+	op := &Operation{Name: "ExplainUpdate", Ref: ref, Filter: filter, Update: update, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*ExplainResult, error) {
+		opt := option.MergeUpdateByParams(operationOptions(op, opts), globalOption)
+		database, collection, err := t.getMongoInfosByAny(ref)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		statement := bson.D{{"q", explainFilter(op.Filter)}, {"u", op.Update}, {"multi", true}}
+		var arrayFilters any
+		if helper.IsNotNil(opt.ArrayFilters) {
+			arrayFilters = opt.ArrayFilters.Filters
+		}
+		statement = appendExplainOptions(statement,
+			bson.E{Key: "upsert", Value: opt.Upsert},
+			bson.E{Key: "arrayFilters", Value: arrayFilters},
+			bson.E{Key: "hint", Value: opt.Hint},
+			bson.E{Key: "collation", Value: explainCollation(opt.Collation)},
+		)
+		command := bson.D{{"update", collection.Name()}, {"updates", bson.A{statement}}}
+		command = appendExplainOptions(command,
+			bson.E{Key: "bypassDocumentValidation", Value: opt.BypassDocumentValidation},
+			bson.E{Key: "comment", Value: opt.Comment},
+			bson.E{Key: "let", Value: opt.Let},
+		)
+		return runExplain(ctx, database, command, nil)
+	})
+}
+
+// UsesIndex returns true if the winning plan scans an index, i.e. it has an IXSCAN stage, otherwise false, which
+// includes the plans with the COLLSCAN stage.
+func (e *ExplainResult) UsesIndex() bool {
+	return e.HasStage("IXSCAN") || e.HasStage("COUNT_SCAN") || e.HasStage("DISTINCT_SCAN") ||
+		e.HasStage("EXPRESS_IXSCAN")
+}
+
+// HasStage returns true if the winning plan has the stage parameter, e.g. COLLSCAN, otherwise false.
+func (e *ExplainResult) HasStage(stage string) bool {
+	return slices.Contains(e.Stages, stage)
+}
+
+// DocsExaminedRatio returns the number of documents examined per returned document, an index covered query has the
+// ratio 0, and a selective index has the ratio close to 1.
+func (e *ExplainResult) DocsExaminedRatio() float64 {
+	return float64(e.DocsExamined) / float64(max(e.Returned, 1))
+}
+
+// runExplain runs the explain of the command parameter with the "executionStats" verbosity.
+func runExplain(ctx context.Context, database *mongo.Database, command bson.D, readPreference *readpref.ReadPref) (
+	*ExplainResult, error) {
+	runCmdOptions := options.RunCmd()
+	if helper.IsNotNil(readPreference) {
+		runCmdOptions.SetReadPreference(readPreference)
+	}
+	raw, err := database.RunCommand(ctx, bson.D{{"explain", command}, {"verbosity", "executionStats"}},
+		runCmdOptions).Raw()
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return newExplainResult(raw), nil
+}
+
+// newExplainResult parses the explain document, the query planner and the execution statistics are looked up at any
+// depth, as the aggregate explain nests them in the $cursor stage.
+func newExplainResult(raw bson.Raw) *ExplainResult {
+	result := &ExplainResult{Raw: raw}
+	if plan, ok := lookupExplain(raw, "winningPlan"); ok {
+		if queryPlan, ok := plan.Lookup("queryPlan").DocumentOK(); ok {
+			plan = queryPlan
+		}
+		result.Stages = planStages(plan)
+		if indexName, ok := lookupExplainString(plan, "indexName"); ok {
+			result.IndexName = indexName
+		}
+	}
+	stats, _ := lookupExplain(raw, "executionStats")
+	result.KeysExamined = rawInt64(stats.Lookup("totalKeysExamined"))
+	result.DocsExamined = rawInt64(stats.Lookup("totalDocsExamined"))
+	result.Returned = rawInt64(stats.Lookup("nReturned"))
+	result.ExecutionTime = time.Duration(rawInt64(stats.Lookup("executionTimeMillis"))) * time.Millisecond
+	return result
+}
+
+// lookupExplain returns the first document of the key parameter in the explain document, at any depth.
+func lookupExplain(document bson.Raw, key string) (bson.Raw, bool) {
+	elements, err := document.Elements()
+	if helper.IsNotNil(err) {
+		return nil, false
+	}
+	for _, element := range elements {
+		value := element.Value()
+		if element.Key() == key && value.Type == bsontype.EmbeddedDocument {
+			return value.Document(), true
+		}
+		if value.Type == bsontype.EmbeddedDocument || value.Type == bsontype.Array {
+			if found, ok := lookupExplain(value.Value, key); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// lookupExplainString returns the first string of the key parameter in the explain document, at any depth.
+func lookupExplainString(document bson.Raw, key string) (string, bool) {
+	elements, err := document.Elements()
+	if helper.IsNotNil(err) {
+		return "", false
+	}
+	for _, element := range elements {
+		value := element.Value()
+		if s, ok := value.StringValueOK(); ok && element.Key() == key {
+			return s, true
+		}
+		if value.Type == bsontype.EmbeddedDocument || value.Type == bsontype.Array {
+			if found, ok := lookupExplainString(value.Value, key); ok {
+				return found, true
+			}
+		}
+	}
+	return "", false
+}
+
+// planStages returns the stage names of the plan parameter, following its input stages.
+func planStages(plan bson.Raw) []string {
+	stage, ok := plan.Lookup("stage").StringValueOK()
+	if !ok {
+		return nil
+	}
+	stages := []string{stage}
+	if input, ok := plan.Lookup("inputStage").DocumentOK(); ok {
+		stages = append(stages, planStages(input)...)
+	} else if inputs, ok := plan.Lookup("inputStages").ArrayOK(); ok {
+		values, _ := inputs.Values()
+		for _, value := range values {
+			if input, ok := value.DocumentOK(); ok {
+				stages = append(stages, planStages(input)...)
+			}
+		}
+	}
+	return stages
+}
+
+// appendExplainOptions appends the non nil options to the command parameter, dereferencing the pointers.
+func appendExplainOptions(command bson.D, opts ...bson.E) bson.D {
+	for _, opt := range opts {
+		if helper.IsNil(opt.Value) {
+			continue
+		}
+		switch v := opt.Value.(type) {
+		case *int64:
+			opt.Value = *v
+		case *bool:
+			opt.Value = *v
+		case *string:
+			opt.Value = *v
+		}
+		command = append(command, opt)
+	}
+	return command
+}
+
+func explainFilter(filter any) any {
+	if helper.IsNil(filter) {
+		return bson.D{}
+	}
+	return filter
+}
+
+func explainCollation(collation *option.Collation) any {
+	if helper.IsNil(collation) {
+		return nil
+	}
+	return option.ParseCollationMongoOptions(collation).ToDocument()
+}
+
+func explainMaxTime(maxTime *time.Duration) any {
+	if helper.IsNil(maxTime) {
+		return nil
+	}
+	return maxTime.Milliseconds()
+}
+
+func rawInt64(value bson.RawValue) int64 {
+	switch value.Type {
+	case bsontype.Int32:
+		return int64(value.Int32())
+	case bsontype.Int64:
+		return value.Int64()
+	case bsontype.Double:
+		return int64(value.Double())
+	}
+	return 0
+}
@@ -0,0 +1,115 @@
+package mongo
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+func TestNewExplainResult(t *testing.T) {
+	raw, _ := bson.Marshal(bson.D{
+		{"stages", bson.A{bson.D{{"$cursor", bson.D{
+			{"queryPlanner", bson.D{{"winningPlan", bson.D{{"queryPlan", bson.D{
+				{"stage", "FETCH"},
+				{"inputStage", bson.D{{"stage", "IXSCAN"}, {"indexName", "name_1"}}},
+			}}}}}},
+			{"executionStats", bson.D{
+				{"nReturned", int32(4)},
+				{"executionTimeMillis", int32(3)},
+				{"totalKeysExamined", int32(10)},
+				{"totalDocsExamined", int32(10)},
+			}},
+		}}}}},
+	})
+	result := newExplainResult(raw)
+	if helper.IsNotEqualTo(result.Stages, []string{"FETCH", "IXSCAN"}) ||
+		helper.IsNotEqualTo(result.IndexName, "name_1") ||
+		helper.IsNotEqualTo(result.KeysExamined, int64(10)) ||
+		helper.IsNotEqualTo(result.DocsExamined, int64(10)) ||
+		helper.IsNotEqualTo(result.Returned, int64(4)) ||
+		helper.IsNotEqualTo(result.ExecutionTime, 3*time.Millisecond) {
+		t.Errorf("newExplainResult() = %+v", result)
+	}
+	if !result.UsesIndex() || result.HasStage("COLLSCAN") {
+		t.Errorf("UsesIndex() = %v, HasStage(COLLSCAN) = %v", result.UsesIndex(), result.HasStage("COLLSCAN"))
+	}
+	if ratio := result.DocsExaminedRatio(); helper.IsNotEqualTo(ratio, 2.5) {
+		t.Errorf("DocsExaminedRatio() = %v, want 2.5", ratio)
+	}
+	result = newExplainResult(nil)
+	if result.UsesIndex() || helper.IsNotEqualTo(result.DocsExaminedRatio(), float64(0)) {
+		t.Errorf("newExplainResult() nil = %+v", result)
+	}
+}
+
+func TestAppendExplainOptions(t *testing.T) {
+	maxTime := 2 * time.Second
+	got := appendExplainOptions(bson.D{{"find", "test"}},
+		bson.E{Key: "sort", Value: nil},
+		bson.E{Key: "limit", Value: helper.ConvertToPointer[int64](5)},
+		bson.E{Key: "comment", Value: helper.ConvertToPointer("test")},
+		bson.E{Key: "collation", Value: explainCollation(nil)},
+		bson.E{Key: "maxTimeMS", Value: explainMaxTime(&maxTime)},
+	)
+	want := bson.D{{"find", "test"}, {"limit", int64(5)}, {"comment", "test"}, {"maxTimeMS", int64(2000)}}
+	if helper.IsNotEqualTo(got, want) {
+		t.Errorf("appendExplainOptions() = %v, want %v", got, want)
+	}
+}
+
+func TestTemplateExplainFind(t *testing.T) {
+	initDocument()
+	result, err := mongoTemplate.ExplainFind(context.TODO(), bson.M{"name": bson.M{"$exists": true}}, testStruct{},
+		option.NewFind().SetSort(bson.M{"_id": -1}).SetLimit(10))
+	if helper.IsNotNil(err) {
+		t.Fatal("ExplainFind() error:", err)
+	}
+	if helper.IsEmpty(result.Stages) {
+		t.Errorf("ExplainFind() stages empty, raw = %v", result.Raw)
+	}
+	_, err = mongoTemplate.ExplainFind(context.TODO(), bson.M{}, testInvalidStruct{})
+	if helper.IsNil(err) {
+		t.Error("ExplainFind() expected error ref without collection")
+	}
+}
+
+func TestTemplateExplainAggregate(t *testing.T) {
+	initDocument()
+	result, err := mongoTemplate.ExplainAggregate(context.TODO(), Pipeline{
+		bson.D{{"$match", bson.D{{"name", bson.D{{"$exists", true}}}}}},
+	}, testStruct{})
+	if helper.IsNotNil(err) {
+		t.Fatal("ExplainAggregate() error:", err)
+	}
+	if helper.IsEmpty(result.Stages) {
+		t.Errorf("ExplainAggregate() stages empty, raw = %v", result.Raw)
+	}
+}
+
+func TestTemplateExplainCount(t *testing.T) {
+	initDocument()
+	result, err := mongoTemplate.ExplainCount(context.TODO(), bson.M{"_id": bson.M{"$exists": true}}, testStruct{},
+		option.NewCount().SetLimit(5))
+	if helper.IsNotNil(err) {
+		t.Fatal("ExplainCount() error:", err)
+	}
+	if helper.IsEmpty(result.Stages) {
+		t.Errorf("ExplainCount() stages empty, raw = %v", result.Raw)
+	}
+}
+
+func TestTemplateExplainUpdate(t *testing.T) {
+	initDocument()
+	result, err := mongoTemplate.ExplainUpdate(context.TODO(), bson.M{"_id": primitive.NewObjectID()},
+		bson.M{"$set": bson.M{"name": "explain"}}, testStruct{})
+	if helper.IsNotNil(err) {
+		t.Fatal("ExplainUpdate() error:", err)
+	}
+	if !result.UsesIndex() && !result.HasStage("IDHACK") && !result.HasStage("EXPRESS_IXSCAN") {
+		t.Errorf("ExplainUpdate() stages = %v", result.Stages)
+	}
+}
@@ -0,0 +1,264 @@
+package mongo
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"sync"
+	"time"
+)
+
+// Lock represents a distributed lock backed by a collection, see Template.NewLock.
+type Lock struct {
+	template      *Template
+	name          string
+	ttl           time.Duration
+	owner         string
+	opt           *option.Lock
+	mutex         sync.Mutex
+	indexCreated  bool
+	held          bool
+	token         int64
+	lost          chan struct{}
+	cancelRefresh context.CancelFunc
+	refreshDone   chan struct{}
+}
+
+type lockDocument struct {
+	Name       string    `bson:"_id"`
+	Owner      string    `bson:"owner"`
+	Token      int64     `bson:"token"`
+	AcquiredAt time.Time `bson:"acquiredAt"`
+	ExpiresAt  time.Time `bson:"expiresAt"`
+}
+
+// NewLock creates a distributed lock identified by the name parameter, only one Lock with the same name can be held
+// at a time across all processes connected to the deployment. The lock is stored as a document on the collection
+// configured in the opts parameter, which receives a TTL index on the first acquisition.
+//
+// The ttl parameter is the duration that the lock is valid without being refreshed, if it is less than or equal to
+// zero, one minute is used.
+//
+// The opts parameter can be used to specify options for the lock (see the option.Lock documentation.)
+//
+// Each acquisition generates a fencing token greater than all the previous ones of the same name, see Lock.Token.
+func (t *Template) NewLock(name string, ttl time.Duration, opts ...*option.Lock) *Lock {
+	if helper.IsLessThanOrEqual(ttl, 0) {
+		ttl = time.Minute
+	}
+	return &Lock{
+		template: t,
+		name:     name,
+		ttl:      ttl,
+		owner:    primitive.NewObjectID().Hex(),
+		opt:      option.MergeLockByParams(opts, ttl),
+	}
+}
+
+// Acquire blocks until the lock is acquired or the ctx is done, trying again every option.Lock.RetryInterval while
+// it is held by another holder. If option.Lock.DisableAutoRefresh is not true, a background goroutine refreshes the
+// lock until Release is called or the lock is lost, see Lost.
+func (l *Lock) Acquire(ctx context.Context) error {
+	for {
+		acquired, err := l.TryAcquire(ctx)
+		if helper.IsNotNil(err) {
+			return err
+		} else if acquired {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(l.opt.RetryInterval):
+		}
+	}
+}
+
+// TryAcquire try to acquire the lock only once, returning false if it is held by another holder.
+//
+// The lock is acquired by a findAndModify command with upsert, matching the lock document only if it is expired, so
+// when it is held, the upsert fails with duplicate key and false is returned. The new fencing token is the greatest
+// value between the previous token plus one and the current time in milliseconds, so it keeps growing even after the
+// expired lock document is removed by the TTL index.
+func (l *Lock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.held {
+		return false, ErrLockAlreadyAcquired
+	}
+	l.stopAutoRefresh()
+	collection, err := l.collection(ctx)
+	if helper.IsNotNil(err) {
+		return false, err
+	}
+	now := time.Now()
+	var result lockDocument
+	err = collection.FindOneAndUpdate(ctx, bson.M{"_id": l.name, "expiresAt": bson.M{"$lte": now}}, mongo.Pipeline{
+		{{"$set", bson.D{
+			{"owner", l.owner},
+			{"token", bson.M{"$max": bson.A{bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$token", 0}}, 1}},
+				now.UnixMilli()}}},
+			{"acquiredAt", now},
+			{"expiresAt", now.Add(l.ttl)},
+		}}},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&result)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	} else if helper.IsNotNil(err) {
+		return false, err
+	}
+	l.held = true
+	l.token = result.Token
+	l.lost = make(chan struct{})
+	if !*l.opt.DisableAutoRefresh {
+		l.startAutoRefresh(ctx)
+	}
+	return true, nil
+}
+
+// Refresh extends the lock expiration by the ttl, returning ErrLockNotHeld if it was not acquired or if it was lost,
+// that is, it expired and was acquired by another holder.
+func (l *Lock) Refresh(ctx context.Context) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.refresh(ctx)
+}
+
+// Release releases the lock, stopping the auto refresh goroutine. ErrLockNotHeld is returned if it was not acquired
+// or if it was lost.
+func (l *Lock) Release(ctx context.Context) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.stopAutoRefresh()
+	if !l.held {
+		return ErrLockNotHeld
+	}
+	l.held = false
+	collection, err := l.collection(ctx)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	result, err := collection.UpdateOne(ctx, l.holderFilter(), bson.M{"$set": bson.M{"expiresAt": time.Now()}})
+	if helper.IsNotNil(err) {
+		return err
+	} else if helper.Equals(result.MatchedCount, int64(0)) {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Token returns the fencing token of the current acquisition, it is greater than the tokens of all previous
+// acquisitions of the same lock name. Pass it along with the writes protected by the lock so the resource can reject
+// writes with a token lower than the last one seen, detecting stale holders.
+func (l *Lock) Token() int64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.token
+}
+
+// IsHeld returns true if the lock is acquired by this instance and was not released or lost.
+func (l *Lock) IsHeld() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.held
+}
+
+// Lost returns a channel that is closed when the lock is lost after being acquired, that is, when a refresh finds
+// that the lock is no longer held by this instance. It returns nil if the lock was never acquired.
+func (l *Lock) Lost() <-chan struct{} {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.lost
+}
+
+func (l *Lock) refresh(ctx context.Context) error {
+	if !l.held {
+		return ErrLockNotHeld
+	}
+	collection, err := l.collection(ctx)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	result, err := collection.UpdateOne(ctx, l.holderFilter(), bson.M{"$set": bson.M{"expiresAt": time.Now().Add(l.ttl)}})
+	if helper.IsNotNil(err) {
+		return err
+	} else if helper.Equals(result.MatchedCount, int64(0)) {
+		l.held = false
+		close(l.lost)
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+func (l *Lock) startAutoRefresh(ctx context.Context) {
+	refreshCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+	l.cancelRefresh = cancel
+	l.refreshDone = done
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(l.opt.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-refreshCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			l.mutex.Lock()
+			if helper.IsNotNil(refreshCtx.Err()) {
+				l.mutex.Unlock()
+				return
+			}
+			err := l.refresh(refreshCtx)
+			l.mutex.Unlock()
+			if helper.Equals(err, ErrLockNotHeld) {
+				return
+			} else if helper.IsNotNil(err) {
+				logger.Error("error refresh lock", l.name, "err:", err)
+			}
+		}
+	}()
+}
+
+func (l *Lock) stopAutoRefresh() {
+	if l.cancelRefresh == nil {
+		return
+	}
+	l.cancelRefresh()
+	l.cancelRefresh = nil
+	done := l.refreshDone
+	l.refreshDone = nil
+	// the goroutine may be waiting for the mutex, so we release it until the goroutine returns
+	l.mutex.Unlock()
+	<-done
+	l.mutex.Lock()
+}
+
+func (l *Lock) holderFilter() bson.M {
+	return bson.M{"_id": l.name, "owner": l.owner, "token": l.token}
+}
+
+func (l *Lock) collection(ctx context.Context) (*mongo.Collection, error) {
+	if helper.IsEmpty(l.opt.DatabaseName) {
+		return nil, ErrDatabaseNotConfigured
+	}
+	collection := l.template.client.Database(l.opt.DatabaseName).Collection(l.opt.CollectionName)
+	if l.indexCreated {
+		return collection, nil
+	}
+	_, err := collection.Indexes().CreateOne(ctx, parseIndexInputToModel(IndexInput{
+		Keys:    bson.D{{"expiresAt", 1}},
+		Options: option.NewIndex().SetName("expiresAt_ttl").SetExpireAfterSeconds(0),
+	}))
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	l.indexCreated = true
+	return collection, nil
+}
@@ -0,0 +1,26 @@
+package mongo
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"testing"
+	"time"
+)
+
+func TestLockNotAcquired(t *testing.T) {
+	client, _ := mongo.NewClient(options.Client())
+	lock := (&Template{client: client}).NewLock("test", time.Second, option.NewLock().SetDatabaseName("test"))
+	ctx := context.TODO()
+	if acquired, err := lock.TryAcquire(ctx); acquired || helper.IsNil(err) {
+		t.Errorf("TryAcquire() = %v, %v, want disconnected client error", acquired, err)
+	}
+	if err := lock.Refresh(ctx); helper.IsNotEqualTo(err, ErrLockNotHeld) {
+		t.Errorf("Refresh() error = %v, want %v", err, ErrLockNotHeld)
+	}
+	if err := lock.Release(ctx); helper.IsNotEqualTo(err, ErrLockNotHeld) {
+		t.Errorf("Release() error = %v, want %v", err, ErrLockNotHeld)
+	}
+}
@@ -2,8 +2,10 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-mongo-template/internal/mongod"
 	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -267,6 +269,24 @@ type testWatchHandler struct {
 	wantErr         bool
 }
 
+type testWatchHandlerE struct {
+	name            string
+	pipeline        any
+	handler         EventHandlerE
+	option          *option.WatchWithHandler
+	durationTimeout time.Duration
+	wantErr         bool
+}
+
+type testWatchChan struct {
+	name            string
+	ref             any
+	pipeline        any
+	option          *option.WatchChan
+	durationTimeout time.Duration
+	wantErr         bool
+}
+
 type testDrop struct {
 	name            string
 	ref             any
@@ -305,6 +325,21 @@ type testListIndexes struct {
 	wantErr         bool
 }
 
+type testJSONSchemaFor struct {
+	name    string
+	ref     any
+	wantErr bool
+}
+
+type testApplyValidator struct {
+	name            string
+	ref             any
+	level           ValidationLevel
+	action          ValidationAction
+	durationTimeout time.Duration
+	wantErr         bool
+}
+
 type testStruct struct {
 	Id        primitive.ObjectID `json:"id" bson:"_id,omitempty" database:"test" collection:"test"`
 	Random    int                `json:"random,omitempty" bson:"random,omitempty"`
@@ -330,24 +365,78 @@ type testInvalidCollectionStruct struct {
 	Balance   float64            `json:"balance,omitempty" bson:"balance,omitempty"`
 }
 
+type testSchemaStruct struct {
+	Id      primitive.ObjectID `bson:"_id,omitempty" database:"test" collection:"test"`
+	Name    string             `bson:"name" validate:"required,min=2,max=50"`
+	Code    string             `bson:"code" validate:"len=3"`
+	Status  string             `bson:"status" validate:"oneof=active inactive"`
+	Age     *int               `bson:"age,omitempty" validate:"omitempty,gte=18,lte=120"`
+	Level   *int32             `bson:"level,omitempty" validate:"omitempty,oneof=1 2 3"`
+	Score   float64            `bson:"score" validate:"max=9.5"`
+	Emails  []string           `bson:"emails" validate:"required,min=1,dive,email,max=5"`
+	Address testSchemaAddress  `bson:"address"`
+}
+
+type testSchemaAddress struct {
+	City string `bson:"city" validate:"required"`
+}
+
+type testValidateStruct struct {
+	Id     primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty" database:"test" collection:"test"`
+	Name   string             `json:"name,omitempty" bson:"name,omitempty" validate:"required"`
+	Emails []string           `json:"emails,omitempty" bson:"emails,omitempty" validate:"required,dive,email"`
+}
+
 type testEmptyStruct struct {
 }
 
 var mongoTemplate *Template
+var mongoServer *mongod.Server
 
 func TestMain(t *testing.M) {
+	initMongoServer()
 	initMongoTemplate()
 	clearCollection()
 	t.Run()
 	clearCollection()
 	disconnectMongoTemplate()
+	stopMongoServer()
+}
+
+func initMongoServer() {
+	if helper.IsNotEmpty(os.Getenv(MongoDBUrl)) {
+		return
+	}
+	server, err := mongod.Start(context.TODO(), mongod.Config{
+		ReplicaSetName: "rs0",
+		StartupTimeout: 30 * time.Second,
+	})
+	if helper.IsNotNil(err) {
+		logger.Error("error start mongod:", err)
+		return
+	}
+	mongoServer = server
+	err = os.Setenv(MongoDBUrl, server.URI)
+	if helper.IsNotNil(err) {
+		logger.Error("err set MongoDBUrl env:", err)
+	}
+}
+
+func stopMongoServer() {
+	if helper.IsNil(mongoServer) {
+		return
+	}
+	if err := mongoServer.Stop(); helper.IsNotNil(err) {
+		logger.Error("error stop mongod:", err)
+	}
+	mongoServer = nil
 }
 
 func initMongoTemplate() {
 	if helper.IsNotNil(mongoTemplate) {
 		return
 	}
-	mt, err := NewTemplate(context.TODO(), options.Client().ApplyURI(os.Getenv("MONGODB_URL")))
+	mt, err := NewTemplate(context.TODO(), options.Client().ApplyURI(os.Getenv(MongoDBUrl)))
 	if helper.IsNotNil(err) {
 		logger.Error("error new Template:", err)
 		return
@@ -470,6 +559,12 @@ func initTestInvalidCollectionStruct() *testInvalidCollectionStruct {
 	}
 }
 
+func initTestValidateStruct() *testValidateStruct {
+	return &testValidateStruct{
+		Emails: []string{"testemail@gmail.com", "invalid email"},
+	}
+}
+
 func initTestEmptyStruct() *testEmptyStruct {
 	return &testEmptyStruct{}
 }
@@ -566,6 +661,13 @@ func initListTestInsertOne() []testInsertOne {
 			durationTimeout: 5 * time.Second,
 			wantErr:         true,
 		},
+		{
+			name:            "failed validate value",
+			value:           initTestValidateStruct(),
+			option:          initOptionInsertOne(),
+			durationTimeout: 5 * time.Second,
+			wantErr:         true,
+		},
 		{
 			name:                     "failed timeout",
 			value:                    initTestStruct(),
@@ -620,6 +722,13 @@ func initListTestInsertMany() []testInsertMany {
 			durationTimeout: 5 * time.Second,
 			wantErr:         true,
 		},
+		{
+			name:            "failed validate value",
+			value:           []any{initTestStruct(), initTestValidateStruct()},
+			option:          initOptionInsertMany(),
+			durationTimeout: 5 * time.Second,
+			wantErr:         true,
+		},
 		{
 			name:            "failed non slice",
 			value:           "invalid value",
@@ -937,6 +1046,25 @@ func initListTestFindOne() []testFindOne {
 			option:          initOptionFindOne(),
 			durationTimeout: 5 * time.Second,
 		},
+		{
+			name:   "success with causal consistency",
+			filter: bson.D{{"_id", objectId}},
+			dest:   &testStruct{},
+			option: option.NewFindOne().
+				SetReadPreference(option.NewReadPreference(option.ReadPreferenceSecondaryPreferred)).
+				SetReadConcern(option.ReadConcernMajority).
+				SetCausalConsistency(true),
+			durationTimeout: 5 * time.Second,
+		},
+		{
+			name:   "failed read preference",
+			filter: bson.D{{"_id", objectId}},
+			dest:   &testStruct{},
+			option: option.NewFindOne().
+				SetReadPreference(option.NewReadPreference("invalid")),
+			durationTimeout: 5 * time.Second,
+			wantErr:         true,
+		},
 		{
 			name:   "failed",
 			filter: bson.D{{"_id", objectId}},
@@ -1839,6 +1967,128 @@ func initListTestWatch() []testWatch {
 	}
 }
 
+func initListTestWatchHandlerE() []testWatchHandlerE {
+	pipeline := Pipeline{bson.D{{"$match", bson.D{
+		{"operationType", bson.M{"$in": []string{"insert", "update", "delete", "replace"}}},
+	}}}}
+	errs := make(chan error, 10)
+	return []testWatchHandlerE{
+		{
+			name:     "success",
+			pipeline: pipeline,
+			handler: func(ctx *EventContext) error {
+				logger.Info("watch handler ctx:", ctx)
+				return nil
+			},
+			option:          initOptionWatchHandler().SetErrors(errs),
+			durationTimeout: 5 * time.Second,
+		},
+		{
+			name:     "success typed event",
+			pipeline: pipeline,
+			handler: TypedEventHandler(func(ctx context.Context, event *TypedEvent[testStruct]) error {
+				logger.Info("watch handler operation type:", event.OperationType, "fullDocument:", event.FullDocument)
+				return nil
+			}),
+			option:          initOptionWatchHandler(),
+			durationTimeout: 5 * time.Second,
+		},
+		{
+			name:     "success with concurrency",
+			pipeline: pipeline,
+			handler: func(ctx *EventContext) error {
+				logger.Info("watch handler document key:", ctx.Event.DocumentKey)
+				return nil
+			},
+			option:          initOptionWatchHandler().SetConcurrency(4).SetQueueSize(2),
+			durationTimeout: 5 * time.Second,
+		},
+		{
+			name:     "success with dead letter",
+			pipeline: pipeline,
+			handler: func(ctx *EventContext) error {
+				return errors.New("handler failed")
+			},
+			option: initOptionWatchHandler().
+				SetMaxRetries(1).
+				SetRetryDelay(100 * time.Millisecond).
+				SetMaxRetryDelay(time.Second).
+				SetErrors(errs).
+				SetDeadLetter(func(ctx context.Context, event bson.Raw, err error) error {
+					logger.Info("watch handler dead letter:", event, "err:", err)
+					return nil
+				}),
+			durationTimeout: 5 * time.Second,
+		},
+		{
+			name:     "success with polling fallback",
+			pipeline: pipeline,
+			handler: func(ctx *EventContext) error {
+				logger.Info("watch handler operation type:", ctx.Event.OperationType, "key:", ctx.Event.DocumentKey)
+				return nil
+			},
+			option: initOptionWatchHandler().
+				SetDatabaseName("test").
+				SetCollectionName("test").
+				SetPollingFallback(true).
+				SetPollingField("_id").
+				SetPollingInterval(500 * time.Millisecond).
+				SetTokenStore(NewMemoryTokenStore()),
+			durationTimeout: 5 * time.Second,
+		},
+		{
+			name:     "failed handler",
+			pipeline: pipeline,
+			handler: func(ctx *EventContext) error {
+				return errors.New("handler failed")
+			},
+			option: initOptionWatchHandler().
+				SetMaxRetries(0).
+				SetErrors(errs),
+			durationTimeout: 5 * time.Second,
+			wantErr:         true,
+		},
+		{
+			name:            "failed handler nil",
+			pipeline:        pipeline,
+			handler:         nil,
+			option:          initOptionWatchHandler(),
+			durationTimeout: 5 * time.Second,
+			wantErr:         true,
+		},
+	}
+}
+
+func initListTestWatchChan() []testWatchChan {
+	return []testWatchChan{
+		{
+			name: "success",
+			ref:  testStruct{},
+			pipeline: Pipeline{bson.D{{"$match", bson.D{
+				{"operationType", bson.M{"$in": []string{"insert", "update", "delete", "replace"}}},
+			}}}},
+			option:          initOptionWatchChan(),
+			durationTimeout: 5 * time.Second,
+		},
+		{
+			name:            "failed ref",
+			ref:             testInvalidStruct{},
+			pipeline:        Pipeline{},
+			option:          initOptionWatchChan(),
+			durationTimeout: 5 * time.Second,
+			wantErr:         true,
+		},
+		{
+			name:            "failed pipeline",
+			ref:             testStruct{},
+			pipeline:        nil,
+			option:          initOptionWatchChan(),
+			durationTimeout: 5 * time.Second,
+			wantErr:         true,
+		},
+	}
+}
+
 func initListTestWatchHandler() []testWatchHandler {
 	return []testWatchHandler{
 		{
@@ -1869,6 +2119,30 @@ func initListTestWatchHandler() []testWatchHandler {
 			option:          initOptionWatchHandler().SetContextFuncTimeout(1 * time.Nanosecond),
 			durationTimeout: 5 * time.Second,
 		},
+		{
+			name: "success with memory token store",
+			pipeline: Pipeline{bson.D{{"$match", bson.D{
+				{"operationType", bson.M{"$in": []string{"insert", "update", "delete", "replace"}}},
+			}}}},
+			handler: func(ctx *EventContext) {
+				logger.Info("watch handler resume token:", ctx.Event.ResumeToken)
+			},
+			option:          initOptionWatchHandler().SetTokenStore(NewMemoryTokenStore()),
+			durationTimeout: 5 * time.Second,
+		},
+		{
+			name: "success with mongo token store",
+			pipeline: Pipeline{bson.D{{"$match", bson.D{
+				{"operationType", bson.M{"$in": []string{"insert", "update", "delete", "replace"}}},
+			}}}},
+			handler: func(ctx *EventContext) {
+				logger.Info("watch handler resume token:", ctx.Event.ResumeToken)
+			},
+			option: initOptionWatchHandler().
+				SetTokenStore(mongoTemplate.NewMongoTokenStore("test", "test_resume_tokens")).
+				SetTokenKey("test-watch-handler"),
+			durationTimeout: 5 * time.Second,
+		},
 		{
 			name:     "failed",
 			pipeline: nil,
@@ -2132,6 +2406,48 @@ func initListTestDropIndex() []testDropIndex {
 	}
 }
 
+func initListTestJSONSchemaFor() []testJSONSchemaFor {
+	return []testJSONSchemaFor{
+		{
+			name: "success",
+			ref:  testStruct{},
+		},
+		{
+			name: "success pointer",
+			ref:  initTestStruct(),
+		},
+		{
+			name: "success slice",
+			ref:  []testStruct{},
+		},
+		{
+			name:    "failed",
+			ref:     initTestString(),
+			wantErr: true,
+		},
+	}
+}
+
+func initListTestApplyValidator() []testApplyValidator {
+	return []testApplyValidator{
+		{
+			name:            "success",
+			ref:             testStruct{},
+			level:           ValidationLevelModerate,
+			action:          ValidationActionWarn,
+			durationTimeout: 5 * time.Second,
+		},
+		{
+			name:            "failed",
+			ref:             testInvalidStruct{},
+			level:           ValidationLevelStrict,
+			action:          ValidationActionError,
+			durationTimeout: 5 * time.Second,
+			wantErr:         true,
+		},
+	}
+}
+
 func initOptionInsertOne() *option.InsertOne {
 	return option.NewInsertOne()
 }
@@ -2321,6 +2637,19 @@ func initOptionWatchHandler() *option.WatchWithHandler {
 		SetMaxAwaitTime(2 * time.Second)
 }
 
+func initOptionCache() *option.Cache {
+	return option.NewCache().
+		SetTTL(time.Minute).
+		SetKeyPrefix("test:")
+}
+
+func initOptionWatchChan() *option.WatchChan {
+	return option.NewWatchChan().
+		SetBufferSize(10).
+		SetFullDocument(option.FullDocumentUpdateLookup).
+		SetMaxAwaitTime(2 * time.Second)
+}
+
 func initOptionIndex() *option.Index {
 	return option.NewIndex()
 }
@@ -2335,6 +2664,31 @@ func initOptionListIndexes() *option.ListIndexes {
 		SetMaxTime(5 * time.Second)
 }
 
+func initOptionLock() *option.Lock {
+	return option.NewLock().
+		SetDatabaseName("test").
+		SetRefreshInterval(500 * time.Millisecond).
+		SetRetryInterval(100 * time.Millisecond)
+}
+
+func initOptionOutbox() *option.Outbox {
+	return option.NewOutbox().
+		SetDatabaseName("test").
+		SetCollectionName("test_outbox")
+}
+
+func initOptionOutboxRelay() *option.OutboxRelay {
+	return option.NewOutboxRelay().
+		SetPollInterval(200 * time.Millisecond).
+		SetInitialBackoff(100 * time.Millisecond).
+		SetMaxAttempts(2)
+}
+
+func initOptionOutboxAdd() *option.InsertOne {
+	return option.NewInsertOne().
+		SetDisableAutoCloseSession(true)
+}
+
 func initGlobalOption() *option.Global {
 	return &option.Global{
 		BypassDocumentValidation:   helper.RandomBool(),
@@ -2342,5 +2696,6 @@ func initGlobalOption() *option.Global {
 		DisableAutoRollbackSession: helper.RandomBool(),
 		DisableAutoCloseSession:    helper.RandomBool(),
 		ForceRecreateSession:       helper.RandomBool(),
+		ValidateDocument:           true,
 	}
 }
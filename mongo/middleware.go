@@ -0,0 +1,102 @@
+package mongo
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+)
+
+// Operation represents a Template operation passing through the middlewares, see Template.Use.
+//
+// The middlewares can rewrite the Filter, Update, Pipeline, Documents and Options fields before calling the next
+// func, e.g. to add the tenant to the filter, and read the Result field after it returns.
+type Operation struct {
+	// Name the name of the Template method, e.g. FindOne
+	Name string
+	// Namespace the database and collection of the ref, empty if the operation is not bound to a collection, e.g.
+	// StartSession
+	Namespace Namespace
+	// Ref the ref, dest or document parameter of the operation, it is not rewritable
+	Ref any
+	// Filter the filter parameter of the operation, the ById operations have the bson.D{{"_id", id}} filter
+	Filter any
+	// Update the update or replacement parameter of the operation
+	Update any
+	// Pipeline the pipeline parameter of the Aggregate, ExplainAggregate and Watch operations
+	Pipeline any
+	// Documents the document or documents parameter of the InsertOne and InsertMany operations
+	Documents any
+	// Options the opts parameter of the operation, e.g. []*option.Find for Find, if it is set with another type, the
+	// opts parameter is used
+	Options any
+	// Result the result of the operation, e.g. *DeleteResult, set when the next func returns, the operations that
+	// only return error, e.g. Find, fill the Ref instead
+	Result any
+}
+
+type operationContextKey struct{}
+
+// OperationFunc executes the Operation, see Middleware.
+type OperationFunc func(ctx context.Context, op *Operation) error
+
+// Middleware wraps the execution of the Template operations, it must call the next func to continue the operation,
+// or return without calling it to short-circuit, see Template.Use.
+type Middleware func(ctx context.Context, op *Operation, next OperationFunc) error
+
+// Use appends the middlewares to the chain of the Template operations, the first middleware is the outermost.
+//
+// All the public operations of the Template pass through the chain, except the SetGlobalOption, SetCache, EnableAudit,
+// DisableAudit, GetClient and SimpleDisconnect methods. The watch handlers pass through the Watch operation when the
+// change stream is opened.
+//
+// Use must be called before the Template is shared between goroutines.
+func (t *Template) Use(middlewares ...Middleware) {
+	for _, middleware := range middlewares {
+		if middleware != nil {
+			t.middlewares = append(t.middlewares, middleware)
+		}
+	}
+}
+
+// execute calls the fn parameter through the middlewares of the Template, and the telemetry, if configured.
+func (t *Template) execute(ctx context.Context, op *Operation, fn OperationFunc) error {
+	if helper.IsNotNil(op.Ref) {
+		if databaseName, collectionName, err := getMongoNamesByAny(op.Ref); helper.IsNil(err) {
+			op.Namespace = Namespace{DB: databaseName, Coll: collectionName}
+		}
+	}
+	ctx = context.WithValue(ctx, operationContextKey{}, op)
+	next := fn
+	for i := len(t.middlewares) - 1; i >= 0; i-- {
+		next = chainMiddleware(t.middlewares[i], next)
+	}
+	if helper.IsNotNil(t.telemetry) {
+		next = chainMiddleware(t.telemetry.middleware, next)
+	}
+	return next(ctx, op)
+}
+
+// executeResult calls the fn parameter like Template.execute, returning the Result of the Operation.
+func executeResult[T any](ctx context.Context, t *Template, op *Operation,
+	fn func(ctx context.Context, op *Operation) (T, error)) (T, error) {
+	err := t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		result, err := fn(ctx, op)
+		op.Result = result
+		return err
+	})
+	result, _ := op.Result.(T)
+	return result, err
+}
+
+func chainMiddleware(middleware Middleware, next OperationFunc) OperationFunc {
+	return func(ctx context.Context, op *Operation) error {
+		return middleware(ctx, op, next)
+	}
+}
+
+// operationOptions returns the Options of the Operation, or the opts parameter if the Options has another type.
+func operationOptions[T any](op *Operation, opts []T) []T {
+	if result, ok := op.Options.([]T); ok {
+		return result
+	}
+	return opts
+}
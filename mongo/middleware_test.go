@@ -0,0 +1,81 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func TestTemplateUse(t *testing.T) {
+	template := &Template{}
+	var calls []string
+	template.Use(func(ctx context.Context, op *Operation, next OperationFunc) error {
+		calls = append(calls, "first "+op.Name)
+		op.Filter = bson.D{{"$and", bson.A{op.Filter, bson.D{{"tenant", "a"}}}}}
+		return next(ctx, op)
+	}, nil, func(ctx context.Context, op *Operation, next OperationFunc) error {
+		calls = append(calls, "second "+op.Namespace.DB+"."+op.Namespace.Coll)
+		return next(ctx, op)
+	})
+	var filter any
+	err := template.execute(context.TODO(), &Operation{Name: "Find", Ref: testStruct{}, Filter: bson.M{"name": "Ana"}},
+		func(ctx context.Context, op *Operation) error {
+			filter = op.Filter
+			return nil
+		})
+	if helper.IsNotNil(err) {
+		t.Fatalf("execute() error = %v", err)
+	}
+	if helper.IsNotEqualTo(calls, []string{"first Find", "second test.test"}) {
+		t.Errorf("execute() calls = %v", calls)
+	}
+	want := bson.D{{"$and", bson.A{bson.M{"name": "Ana"}, bson.D{{"tenant", "a"}}}}}
+	if helper.IsNotEqualTo(filter, want) {
+		t.Errorf("execute() filter = %v, want %v", filter, want)
+	}
+	err = template.Aggregate(context.TODO(), Pipeline{}, testStruct{})
+	if helper.IsNotEqualTo(err, ErrDestIsNotPointer) {
+		t.Errorf("Aggregate() error = %v, want %v", err, ErrDestIsNotPointer)
+	}
+}
+
+func TestTemplateUseShortCircuit(t *testing.T) {
+	template := &Template{}
+	errDenied := errors.New("denied")
+	template.Use(func(ctx context.Context, op *Operation, next OperationFunc) error {
+		switch op.Name {
+		case "CountDocuments":
+			op.Result = int64(42)
+			return nil
+		case "Find":
+			if opts, ok := op.Options.([]*option.Find); !ok || helper.IsNotEqualTo(len(opts), 1) {
+				t.Errorf("Find() options = %v", op.Options)
+			}
+		}
+		return errDenied
+	})
+	count, err := template.CountDocuments(context.TODO(), bson.M{}, testStruct{})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(count, int64(42)) {
+		t.Errorf("CountDocuments() = %v, %v, want 42", count, err)
+	}
+	var dest []testStruct
+	err = template.Find(context.TODO(), bson.M{}, &dest, option.NewFind())
+	if helper.IsNotEqualTo(err, errDenied) {
+		t.Errorf("Find() error = %v, want %v", err, errDenied)
+	}
+}
+
+func TestOperationOptions(t *testing.T) {
+	opts := []*option.Find{option.NewFind()}
+	op := &Operation{Options: []*option.Find{option.NewFind().SetLimit(1), option.NewFind()}}
+	if got := operationOptions(op, opts); helper.IsNotEqualTo(len(got), 2) {
+		t.Errorf("operationOptions() = %v, want rewritten options", got)
+	}
+	op.Options = []*option.Count{}
+	if got := operationOptions(op, opts); helper.IsNotEqualTo(len(got), 1) {
+		t.Errorf("operationOptions() = %v, want opts parameter", got)
+	}
+}
@@ -0,0 +1,13 @@
+package migrate
+
+import "errors"
+
+var ErrDatabaseNotConfigured = errors.New("migrate: database name not configured")
+var ErrInvalidVersion = errors.New("migrate: migration version needs to be greater than 0")
+var ErrDuplicateVersion = errors.New("migrate: migration version already registered")
+var ErrUpIsNil = errors.New("migrate: migration up func is nil")
+var ErrDownIsNil = errors.New("migrate: migration down func is nil, it cannot be reverted")
+var ErrChecksumMismatch = errors.New("migrate: checksum of the applied migration does not match the registered one")
+var ErrMigrationNotRegistered = errors.New("migrate: applied migration is not registered")
+var ErrNoAppliedMigrations = errors.New("migrate: no applied migrations")
+var ErrLockLost = errors.New("migrate: lock lost while running the migrations")
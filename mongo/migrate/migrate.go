@@ -0,0 +1,394 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"sort"
+	"time"
+)
+
+const lockDocumentId = "lock"
+
+// Func is the function executed to apply or revert a migration.
+//
+// When the deployment supports transactions, the function is executed inside a Template session, so to keep the
+// writes in the migration transaction, the operations must be called with the DisableAutoCloseSession option,
+// the migration record is written in the same transaction, which is committed if the function returns nil,
+// otherwise it is aborted.
+//
+// The ctx parameter is cancelled if the lock of the migrator is lost while the function runs, so it must be passed to
+// the operations.
+type Func func(ctx context.Context, template *mongo.Template) error
+
+// Migration represents a versioned change on the database.
+type Migration struct {
+	// Version unique number of the migration, migrations are applied in ascending order of version (required)
+	Version int64
+	// Description short description of the migration
+	Description string
+	// Checksum identifies the content of the migration, if it differs from the checksum stored when the migration was
+	// applied, Up returns ErrChecksumMismatch. The default value is the sha256 of the version and description.
+	Checksum string
+	// Up applies the migration (required)
+	Up Func
+	// Down reverts the migration, if nil the migration cannot be reverted
+	Down Func
+}
+
+// Status represents the state of a migration, registered and/or applied.
+type Status struct {
+	// Version of the migration
+	Version int64
+	// Description of the migration
+	Description string
+	// Applied if true, the migration is stored as applied
+	Applied bool
+	// AppliedAt time when the migration was applied
+	AppliedAt time.Time
+	// Checksum stored when the migration was applied, or the registered checksum if not applied
+	Checksum string
+	// ChecksumMismatch if true, the applied checksum differs from the registered one
+	ChecksumMismatch bool
+	// Missing if true, the migration is applied but is not registered
+	Missing bool
+}
+
+// Migrator runs the registered migrations, storing the applied versions with their checksums in the migrations
+// collection and holding a lock document in the same collection while running, so only one process runs them
+// at a time.
+type Migrator struct {
+	template      *mongo.Template
+	opt           *option.Migrate
+	lock          *mongo.Lock
+	collection    *mongodriver.Collection
+	migrations    map[int64]Migration
+	transactional *bool
+}
+
+type record struct {
+	Version     int64     `bson:"_id"`
+	Description string    `bson:"description"`
+	Checksum    string    `bson:"checksum"`
+	AppliedAt   time.Time `bson:"appliedAt"`
+}
+
+// New creates a new Migrator instance.
+//
+// The opts parameter can be used to specify options for the migrator, the database name is required
+// (see the option.Migrate documentation).
+func New(template *mongo.Template, opts ...*option.Migrate) *Migrator {
+	opt := option.MergeMigrateByParams(opts)
+	client := template.GetClient()
+	return &Migrator{
+		template: template,
+		opt:      opt,
+		lock: template.NewLock(lockDocumentId, opt.LockTTL, option.NewLock().
+			SetDatabaseName(opt.DatabaseName).
+			SetCollectionName(opt.CollectionName).
+			SetRetryInterval(opt.LockRetryInterval)),
+		collection: client.Database(opt.DatabaseName).Collection(opt.CollectionName),
+		migrations: map[int64]Migration{},
+	}
+}
+
+// Register adds the migrations to the migrator, the version must be greater than 0 and unique, and the Up func
+// cannot be nil.
+func (m *Migrator) Register(migrations ...Migration) error {
+	for _, migration := range migrations {
+		if helper.IsLessThanOrEqual(migration.Version, 0) {
+			return ErrInvalidVersion
+		} else if migration.Up == nil {
+			return fmt.Errorf("%w, version: %d", ErrUpIsNil, migration.Version)
+		} else if _, ok := m.migrations[migration.Version]; ok {
+			return fmt.Errorf("%w, version: %d", ErrDuplicateVersion, migration.Version)
+		}
+		if helper.IsEmpty(migration.Checksum) {
+			migration.Checksum = checksum(migration)
+		}
+		m.migrations[migration.Version] = migration
+	}
+	return nil
+}
+
+// Up applies all pending migrations in ascending order of version. If the checksum of an already applied migration
+// differs from the registered one, no migration is applied and ErrChecksumMismatch is returned.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(ctx context.Context) error {
+		applied, err := m.findApplied(ctx)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		for _, r := range applied {
+			if migration, ok := m.migrations[r.Version]; ok && helper.IsNotEqualTo(migration.Checksum, r.Checksum) {
+				return fmt.Errorf("%w, version: %d", ErrChecksumMismatch, r.Version)
+			}
+		}
+		for _, migration := range m.sortedMigrations() {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err = m.up(ctx, migration); helper.IsNotNil(err) {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts the last n applied migrations in descending order of version.
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	return m.withLock(ctx, func(ctx context.Context) error {
+		return m.down(ctx, n)
+	})
+}
+
+// Redo reverts the last applied migration and applies it again.
+func (m *Migrator) Redo(ctx context.Context) error {
+	return m.withLock(ctx, func(ctx context.Context) error {
+		applied, err := m.sortedApplied(ctx)
+		if helper.IsNotNil(err) {
+			return err
+		} else if helper.IsEmpty(applied) {
+			return ErrNoAppliedMigrations
+		}
+		migration, ok := m.migrations[applied[0].Version]
+		if !ok {
+			return fmt.Errorf("%w, version: %d", ErrMigrationNotRegistered, applied[0].Version)
+		}
+		if err = m.down(ctx, 1); helper.IsNotNil(err) {
+			return err
+		}
+		return m.up(ctx, migration)
+	})
+}
+
+// Status returns the state of all registered and applied migrations in ascending order of version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.findApplied(ctx)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	var result []Status
+	for _, migration := range m.migrations {
+		status := Status{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Checksum:    migration.Checksum,
+		}
+		if r, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = r.AppliedAt
+			status.Checksum = r.Checksum
+			status.ChecksumMismatch = helper.IsNotEqualTo(r.Checksum, migration.Checksum)
+		}
+		result = append(result, status)
+	}
+	for _, r := range applied {
+		if _, ok := m.migrations[r.Version]; !ok {
+			result = append(result, Status{
+				Version:     r.Version,
+				Description: r.Description,
+				Applied:     true,
+				AppliedAt:   r.AppliedAt,
+				Checksum:    r.Checksum,
+				Missing:     true,
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+func (m *Migrator) up(ctx context.Context, migration Migration) error {
+	err := m.run(ctx, migration.Up, func(ctx context.Context) error {
+		_, err := m.collection.InsertOne(ctx, record{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Checksum:    migration.Checksum,
+			AppliedAt:   time.Now().UTC(),
+		})
+		return err
+	})
+	if helper.IsNotNil(err) {
+		return fmt.Errorf("migrate: error applying version %d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+func (m *Migrator) down(ctx context.Context, n int) error {
+	applied, err := m.sortedApplied(ctx)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	for i := 0; helper.IsLessThan(i, n) && helper.IsLessThan(i, len(applied)); i++ {
+		migration, ok := m.migrations[applied[i].Version]
+		if !ok {
+			return fmt.Errorf("%w, version: %d", ErrMigrationNotRegistered, applied[i].Version)
+		} else if migration.Down == nil {
+			return fmt.Errorf("%w, version: %d", ErrDownIsNil, migration.Version)
+		}
+		err = m.run(ctx, migration.Down, func(ctx context.Context) error {
+			_, err := m.collection.DeleteOne(ctx, bson.M{"_id": migration.Version})
+			return err
+		})
+		if helper.IsNotNil(err) {
+			return fmt.Errorf("migrate: error reverting version %d: %w", migration.Version, err)
+		}
+	}
+	return nil
+}
+
+// run executes the f parameter and then the writeRecord parameter within the same Template transaction, if the
+// deployment supports transactions.
+func (m *Migrator) run(ctx context.Context, f Func, writeRecord func(ctx context.Context) error) error {
+	transactional, err := m.isTransactional(ctx)
+	if helper.IsNotNil(err) {
+		return err
+	} else if !transactional {
+		if err = f(ctx, m.template); helper.IsNil(err) {
+			err = writeRecord(ctx)
+		}
+		return err
+	}
+	err = m.template.StartSession(ctx)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	err = f(ctx, m.template)
+	if helper.IsNil(err) {
+		err = m.template.WithSession(ctx, func(sc mongodriver.SessionContext) error {
+			return writeRecord(sc)
+		})
+		if errors.Is(err, mongo.ErrNoOpenSession) {
+			// the migration closed the session, so its writes are already committed
+			err = writeRecord(ctx)
+		}
+	}
+	errClose := m.template.CloseSession(ctx, helper.IsNotNil(err))
+	if helper.IsNil(err) && helper.IsNotNil(errClose) && !errors.Is(errClose, mongo.ErrNoOpenSession) {
+		err = errClose
+	}
+	return err
+}
+
+func (m *Migrator) isTransactional(ctx context.Context) (bool, error) {
+	if *m.opt.DisableTransaction {
+		return false, nil
+	} else if helper.IsNotNil(m.transactional) {
+		return *m.transactional, nil
+	}
+	client := m.template.GetClient()
+	var hello bson.M
+	err := client.Database("admin").RunCommand(ctx, bson.D{{"hello", 1}}).Decode(&hello)
+	if helper.IsNotNil(err) {
+		return false, err
+	}
+	_, isReplicaSet := hello["setName"]
+	transactional := isReplicaSet || helper.Equals(hello["msg"], "isdbgrid")
+	m.transactional = &transactional
+	return transactional, nil
+}
+
+// withLock calls the f parameter holding the lock, the ctx passed to the f is cancelled if the lock is lost while it
+// runs, that is, a refresh finds that it expired and was acquired by another process, so ErrLockLost is returned.
+func (m *Migrator) withLock(ctx context.Context, f func(ctx context.Context) error) error {
+	if helper.IsEmpty(m.opt.DatabaseName) {
+		return ErrDatabaseNotConfigured
+	}
+	err := m.lock.Acquire(ctx)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	lockCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	lost := m.lock.Lost()
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-lost:
+			cancel()
+		case <-done:
+		}
+	}()
+	err = f(lockCtx)
+	close(done)
+	select {
+	case <-lost:
+		if helper.IsNil(err) {
+			return ErrLockLost
+		}
+		return fmt.Errorf("%w: %w", ErrLockLost, err)
+	default:
+	}
+	releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), m.opt.LockTTL)
+	defer cancelRelease()
+	errRelease := m.lock.Release(releaseCtx)
+	if errors.Is(errRelease, mongo.ErrLockNotHeld) {
+		errRelease = ErrLockLost
+	}
+	if helper.IsNil(err) {
+		err = errRelease
+	}
+	return err
+}
+
+func (m *Migrator) findApplied(ctx context.Context) (map[int64]record, error) {
+	if helper.IsEmpty(m.opt.DatabaseName) {
+		return nil, ErrDatabaseNotConfigured
+	}
+	cursor, err := m.collection.Find(ctx, bson.M{"checksum": bson.M{"$exists": true}})
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	var records []record
+	err = cursor.All(ctx, &records)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	result := map[int64]record{}
+	for _, r := range records {
+		result[r.Version] = r
+	}
+	return result, nil
+}
+
+func (m *Migrator) sortedApplied(ctx context.Context) ([]record, error) {
+	applied, err := m.findApplied(ctx)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	result := make([]record, 0, len(applied))
+	for _, r := range applied {
+		result = append(result, r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version > result[j].Version
+	})
+	return result, nil
+}
+
+func (m *Migrator) sortedMigrations() []Migration {
+	result := make([]Migration, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		result = append(result, migration)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result
+}
+
+func checksum(migration Migration) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", migration.Version, migration.Description)))
+	return hex.EncodeToString(sum[:])
+}
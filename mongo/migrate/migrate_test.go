@@ -0,0 +1,272 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"os"
+	"testing"
+	"time"
+)
+
+type testStruct struct {
+	Id   primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty" database:"test" collection:"test"`
+	Name string             `json:"name,omitempty" bson:"name,omitempty"`
+}
+
+type testRegister struct {
+	name       string
+	migrations []Migration
+	wantErr    bool
+}
+
+var mongoTemplate *mongo.Template
+
+func TestMain(m *testing.M) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	mt, err := mongo.NewTemplate(ctx, options.Client().ApplyURI(os.Getenv("MONGODB_URL")))
+	cancel()
+	if helper.IsNotNil(err) {
+		logger.Error("error new Template:", err)
+		return
+	}
+	mongoTemplate = mt
+	clearMigrations()
+	m.Run()
+	clearMigrations()
+	mongoTemplate.SimpleDisconnect(context.TODO())
+}
+
+func TestMigratorRegister(t *testing.T) {
+	for _, tt := range initListTestRegister() {
+		t.Run(tt.name, func(t *testing.T) {
+			err := initMigrator().Register(tt.migrations...)
+			if helper.IsNotEqualTo(helper.IsNotNil(err), tt.wantErr) {
+				t.Errorf("Register() error = %v, wantErr %v", err, tt.wantErr)
+			} else if helper.IsNotNil(err) {
+				t.Log("err expected:", err)
+			}
+		})
+	}
+}
+
+func TestMigratorUpDownRedo(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
+	defer cancel()
+	migrator := initMigrator()
+	err := migrator.Register(initMigrations()...)
+	if helper.IsNotNil(err) {
+		t.Fatal("Register() error:", err)
+	}
+	if err = migrator.Up(ctx); helper.IsNotNil(err) {
+		t.Fatal("Up() error:", err)
+	}
+	assertApplied(t, ctx, migrator, 2)
+	if err = migrator.Redo(ctx); helper.IsNotNil(err) {
+		t.Fatal("Redo() error:", err)
+	}
+	assertApplied(t, ctx, migrator, 2)
+	if err = migrator.Down(ctx, 1); helper.IsNotNil(err) {
+		t.Fatal("Down() error:", err)
+	}
+	assertApplied(t, ctx, migrator, 1)
+	changed := initMigrator()
+	migrations := initMigrations()
+	migrations[0].Description = "changed description"
+	_ = changed.Register(migrations...)
+	if err = changed.Up(ctx); helper.IsNil(err) {
+		t.Error("Up() expected checksum mismatch error")
+	} else {
+		t.Log("err expected:", err)
+	}
+	if err = migrator.Down(ctx, 2); helper.IsNotNil(err) {
+		t.Fatal("Down() error:", err)
+	}
+	assertApplied(t, ctx, migrator, 0)
+}
+
+func TestMigratorRecordInTransaction(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
+	defer cancel()
+	migrator := initMigrator()
+	if transactional, err := migrator.isTransactional(ctx); helper.IsNotNil(err) || !transactional {
+		t.Skip("deployment without transactions")
+	}
+	// a record without checksum is not applied, but makes the record insert fail with duplicate key
+	_, err := migrator.collection.InsertOne(ctx, bson.M{"_id": int64(3)})
+	if helper.IsNotNil(err) {
+		t.Fatal("InsertOne() error:", err)
+	}
+	defer clearMigrations()
+	err = migrator.Register(Migration{Version: 3, Description: "record failure",
+		Up: func(ctx context.Context, template *mongo.Template) error {
+			return template.InsertOne(ctx, &testStruct{Name: "record failure"},
+				option.NewInsertOne().SetDisableAutoCloseSession(true))
+		}})
+	if helper.IsNotNil(err) {
+		t.Fatal("Register() error:", err)
+	}
+	if err = migrator.Up(ctx); helper.IsNil(err) {
+		t.Fatal("Up() expected duplicate key error")
+	}
+	exists, err := mongoTemplate.Exists(ctx, bson.M{"name": "record failure"}, testStruct{})
+	if helper.IsNotNil(err) {
+		t.Fatal("Exists() error:", err)
+	} else if exists {
+		t.Error("Up() migration committed without its record")
+	}
+}
+
+func TestMigratorNilFuncs(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
+	defer cancel()
+	migrator := initMigrator()
+	err := migrator.Register(Migration{Version: 1, Description: "without up"})
+	if !errors.Is(err, ErrUpIsNil) {
+		t.Errorf("Register() error = %v, want %v", err, ErrUpIsNil)
+	}
+	err = migrator.Register(Migration{Version: 1, Description: "without down",
+		Up: func(ctx context.Context, template *mongo.Template) error {
+			return nil
+		}})
+	if helper.IsNotNil(err) {
+		t.Fatal("Register() error:", err)
+	}
+	if err = migrator.Up(ctx); helper.IsNotNil(err) {
+		t.Fatal("Up() error:", err)
+	}
+	if err = migrator.Down(ctx, 1); !errors.Is(err, ErrDownIsNil) {
+		t.Errorf("Down() error = %v, want %v", err, ErrDownIsNil)
+	}
+	clearMigrations()
+}
+
+func TestMigratorLockLost(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
+	defer cancel()
+	migrator := New(mongoTemplate, option.NewMigrate().
+		SetDatabaseName("test").
+		SetCollectionName("test_migrations").
+		SetLockTTL(300*time.Millisecond))
+	defer clearMigrations()
+	err := migrator.Register(Migration{Version: 1, Description: "lock lost",
+		Up: func(ctx context.Context, template *mongo.Template) error {
+			_, err := migrator.collection.DeleteOne(ctx, bson.M{"_id": lockDocumentId})
+			if helper.IsNotNil(err) {
+				return err
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		}})
+	if helper.IsNotNil(err) {
+		t.Fatal("Register() error:", err)
+	}
+	if err = migrator.Up(ctx); !errors.Is(err, ErrLockLost) {
+		t.Errorf("Up() error = %v, want %v", err, ErrLockLost)
+	}
+}
+
+func TestMigratorDatabaseNotConfigured(t *testing.T) {
+	err := New(mongoTemplate).Up(context.TODO())
+	if helper.IsNil(err) {
+		t.Error("Up() expected error database not configured")
+	}
+}
+
+func assertApplied(t *testing.T, ctx context.Context, migrator *Migrator, expected int) {
+	result, err := migrator.Status(ctx)
+	if helper.IsNotNil(err) {
+		t.Fatal("Status() error:", err)
+	}
+	var applied int
+	for _, status := range result {
+		if status.Applied {
+			applied++
+		}
+	}
+	if helper.IsNotEqualTo(applied, expected) {
+		t.Errorf("Status() applied = %v, expected %v", applied, expected)
+	}
+}
+
+func initMigrator() *Migrator {
+	return New(mongoTemplate, option.NewMigrate().
+		SetDatabaseName("test").
+		SetCollectionName("test_migrations").
+		SetLockTTL(5*time.Second).
+		SetLockRetryInterval(100*time.Millisecond))
+}
+
+func initMigrations() []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "insert document",
+			Up: func(ctx context.Context, template *mongo.Template) error {
+				return template.InsertOne(ctx, &testStruct{Name: "migration"},
+					option.NewInsertOne().SetDisableAutoCloseSession(true))
+			},
+			Down: func(ctx context.Context, template *mongo.Template) error {
+				_, err := template.DeleteMany(ctx, bson.M{"name": "migration"}, testStruct{},
+					option.NewDelete().SetDisableAutoCloseSession(true))
+				return err
+			},
+		},
+		{
+			Version:     2,
+			Description: "create index",
+			Up: func(ctx context.Context, template *mongo.Template) error {
+				_, err := template.CreateOneIndex(ctx, mongo.IndexInput{
+					Keys:    bson.D{{"name", 1}},
+					Options: option.NewIndex().SetName("migration_name"),
+					Ref:     testStruct{},
+				})
+				return err
+			},
+			Down: func(ctx context.Context, template *mongo.Template) error {
+				return template.DropOneIndex(ctx, "migration_name", testStruct{})
+			},
+		},
+	}
+}
+
+func initListTestRegister() []testRegister {
+	up := func(ctx context.Context, template *mongo.Template) error {
+		return nil
+	}
+	return []testRegister{
+		{
+			name:       "success",
+			migrations: initMigrations(),
+		},
+		{
+			name:       "failed version",
+			migrations: []Migration{{Version: 0, Up: up}},
+			wantErr:    true,
+		},
+		{
+			name:       "failed up nil",
+			migrations: []Migration{{Version: 1}},
+			wantErr:    true,
+		},
+		{
+			name:       "failed duplicate version",
+			migrations: []Migration{{Version: 1, Up: up}, {Version: 1, Up: up}},
+			wantErr:    true,
+		},
+	}
+}
+
+func clearMigrations() {
+	client := mongoTemplate.GetClient()
+	err := client.Database("test").Collection("test_migrations").Drop(context.TODO())
+	if helper.IsNotNil(err) {
+		logger.Error("error clean migrations:", err)
+	}
+}
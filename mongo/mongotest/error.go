@@ -0,0 +1,11 @@
+package mongotest
+
+import "errors"
+
+var ErrNotSupported = errors.New("mongotest: operation not supported by the in-memory template")
+var ErrUpdateIsEmpty = errors.New("mongotest: update document is empty")
+var ErrUpdateWithoutOperators = errors.New("mongotest: update document requires atomic operators")
+var ErrImmutableId = errors.New("mongotest: performing an update on the path '_id' would modify the immutable field '_id'")
+var ErrIndexNotFound = errors.New("mongotest: index not found")
+var ErrFixtureRefNotFound = errors.New("mongotest: ref not found for fixture file")
+var ErrFixtureNotArray = errors.New("mongotest: fixture file must have an array of documents")
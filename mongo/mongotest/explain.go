@@ -0,0 +1,68 @@
+package mongotest
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ExplainFind returns the collection scan of the find, see mongo.Template.ExplainFind. The Template has no indexes
+// on the queries, so the winning plan is always the COLLSCAN stage, which examines all the documents of the
+// collection.
+func (t *Template) ExplainFind(_ context.Context, filter, ref any, opts ...*option.Find) (*mongo.ExplainResult,
+	error) {
+	opt := option.MergeFindByParams(opts)
+	documents, err := t.query(ref, filter, opt.Sort, opt.Skip, opt.Limit, nil)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return t.explain(ref, int64(len(documents)), "COLLSCAN")
+}
+
+// ExplainAggregate returns the collection scan of the pipeline, see mongo.Template.ExplainAggregate and
+// Template.ExplainFind.
+func (t *Template) ExplainAggregate(_ context.Context, pipeline, ref any, _ ...*option.Aggregate) (
+	*mongo.ExplainResult, error) {
+	documents, err := t.aggregate(ref, pipeline)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return t.explain(ref, int64(len(documents)), "COLLSCAN")
+}
+
+// ExplainCount returns the collection scan of the count, see mongo.Template.ExplainCount and Template.ExplainFind.
+func (t *Template) ExplainCount(ctx context.Context, filter, ref any, opts ...*option.Count) (*mongo.ExplainResult,
+	error) {
+	count, err := t.CountDocuments(ctx, filter, ref, opts...)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return t.explain(ref, count, "COUNT", "COLLSCAN")
+}
+
+// ExplainUpdate returns the collection scan of the update, without modifying any document, see
+// mongo.Template.ExplainUpdate and Template.ExplainFind.
+func (t *Template) ExplainUpdate(_ context.Context, filter, _, ref any, _ ...*option.Update) (*mongo.ExplainResult,
+	error) {
+	documents, err := t.query(ref, filter, nil, nil, nil, nil)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return t.explain(ref, int64(len(documents)), "UPDATE", "COLLSCAN")
+}
+
+// explain returns the result of the stages parameter examining all the documents of the collection of the ref
+// parameter.
+func (t *Template) explain(ref any, returned int64, stages ...string) (*mongo.ExplainResult, error) {
+	documents, err := t.query(ref, bson.D{}, nil, nil, nil, nil)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return &mongo.ExplainResult{
+		Stages:       stages,
+		DocsExamined: int64(len(documents)),
+		Returned:     returned,
+	}, nil
+}
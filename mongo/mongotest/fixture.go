@@ -0,0 +1,245 @@
+package mongotest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	driver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv is the environment variable that makes AssertCollection write the golden files instead of
+// comparing them, e.g. MONGOTEST_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "MONGOTEST_UPDATE_GOLDEN"
+
+// LoadFixtures inserts the documents of the fixture files of the dir parameter, using the InsertMany of the template
+// parameter, which can be a mongo.Template or a Template.
+//
+// Each file has an array of documents in Extended JSON (.json), or in YAML (.yaml or .yml) with the Extended JSON
+// notation for the bson types, e.g. {"$oid": "65a1..."}. The file name is the collection name, or the database and
+// collection names separated by a dot, e.g. users.json or app.users.yaml, the other files are ignored.
+//
+// The refs parameter must be the collection structures with database and collection tags configured, the documents
+// of each file are decoded on the structure of its collection, so they are validated like on the application.
+func LoadFixtures(ctx context.Context, template mongo.TemplateAPI, dir string, refs ...any) error {
+	entries, err := os.ReadDir(dir)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	for _, entry := range entries {
+		extension := filepath.Ext(entry.Name())
+		if entry.IsDir() || !isFixtureExtension(extension) {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), extension)
+		ref, err := findFixtureRef(name, refs)
+		if helper.IsNotNil(err) {
+			return fmt.Errorf("%w: %s", err, entry.Name())
+		}
+		documents, err := readFixture(filepath.Join(dir, entry.Name()), ref)
+		if helper.IsNotNil(err) {
+			return fmt.Errorf("mongotest: error reading fixture %s: %w", entry.Name(), err)
+		} else if reflect.ValueOf(documents).Len() == 0 {
+			continue
+		}
+		if err = template.InsertMany(ctx, documents); helper.IsNotNil(err) {
+			return fmt.Errorf("mongotest: error inserting fixture %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+// AssertCollection compares the documents of the collection of the ref parameter with the golden file, failing the
+// test if they are different.
+//
+// The documents are read as they are stored, without decoding them on the ref structure, so the golden file has all
+// their fields in the stored order. The template parameter must be a Template or a mongo.Template.
+//
+// The documents are sorted by _id and written as relaxed Extended JSON, with the ObjectIDs replaced by ObjectId(n),
+// where n is the order of the first occurrence of the id, and the dates and timestamps replaced by <timestamp>, so
+// the golden file does not change between the runs. To create or update the golden file run the test with the
+// UpdateGoldenEnv environment variable.
+func AssertCollection(t testing.TB, template mongo.TemplateAPI, ref any, golden string) {
+	t.Helper()
+	got, err := dumpCollection(context.Background(), template, ref)
+	if helper.IsNotNil(err) {
+		t.Fatal("mongotest: error dumping collection:", err)
+	}
+	if helper.IsNotEmpty(os.Getenv(UpdateGoldenEnv)) {
+		if err = os.MkdirAll(filepath.Dir(golden), 0755); helper.IsNil(err) {
+			err = os.WriteFile(golden, got, 0644)
+		}
+		if helper.IsNotNil(err) {
+			t.Fatal("mongotest: error writing golden file:", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if helper.IsNotNil(err) {
+		t.Fatalf("mongotest: error reading golden file, run with %s=1 to create it: %v", UpdateGoldenEnv, err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(got), bytes.TrimSpace(want)) {
+		t.Errorf("mongotest: collection does not match golden file %s\ngot:\n%s\nwant:\n%s", golden, got, want)
+	}
+}
+
+func isFixtureExtension(extension string) bool {
+	return extension == ".json" || extension == ".yaml" || extension == ".yml"
+}
+
+// findFixtureRef returns the ref whose collection has the fixture name.
+func findFixtureRef(name string, refs []any) (any, error) {
+	for _, ref := range refs {
+		databaseName, collectionName, err := getNamesByAny(ref)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		if name == collectionName || name == databaseName+"."+collectionName {
+			return ref, nil
+		}
+	}
+	return nil, ErrFixtureRefNotFound
+}
+
+// readFixture returns a slice of pointers of the ref type with the documents of the file.
+func readFixture(path string, ref any) (any, error) {
+	b, err := os.ReadFile(path)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	if helper.IsNotEqualTo(filepath.Ext(path), ".json") {
+		var v any
+		if err = yaml.Unmarshal(b, &v); helper.IsNotNil(err) {
+			return nil, err
+		} else if b, err = json.Marshal(v); helper.IsNotNil(err) {
+			return nil, err
+		}
+	}
+	// the Extended JSON parser only accepts documents, so the array is wrapped on a document
+	var raw bson.Raw
+	b = append(append([]byte(`{"documents":`), bytes.TrimSpace(b)...), '}')
+	if err = bson.UnmarshalExtJSON(b, false, &raw); helper.IsNotNil(err) {
+		return nil, err
+	}
+	array, ok := raw.Lookup("documents").ArrayOK()
+	if !ok {
+		return nil, ErrFixtureNotArray
+	}
+	values, err := array.Values()
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	refType := reflect.TypeOf(ref)
+	if refType.Kind() == reflect.Pointer {
+		refType = refType.Elem()
+	}
+	documents := reflect.New(reflect.SliceOf(reflect.PointerTo(refType)))
+	for _, value := range values {
+		document, ok := value.DocumentOK()
+		if !ok {
+			return nil, ErrFixtureNotArray
+		}
+		item := reflect.New(refType)
+		if err = bson.Unmarshal(document, item.Interface()); helper.IsNotNil(err) {
+			return nil, err
+		}
+		documents.Elem().Set(reflect.Append(documents.Elem(), item))
+	}
+	return documents.Elem().Interface(), nil
+}
+
+// dumpCollection returns the normalized documents of the collection of the ref parameter as Extended JSON.
+func dumpCollection(ctx context.Context, template mongo.TemplateAPI, ref any) ([]byte, error) {
+	documents, err := rawDocuments(ctx, template, ref)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	normalizer := &normalizer{ids: map[primitive.ObjectID]int{}}
+	var buffer bytes.Buffer
+	buffer.WriteString("[")
+	for i, document := range documents {
+		b, err := bson.MarshalExtJSONIndent(normalizer.document(document), false, false, "  ", "  ")
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+		buffer.WriteString("\n  ")
+		buffer.Write(b)
+	}
+	buffer.WriteString("\n]\n")
+	return buffer.Bytes(), nil
+}
+
+// rawDocuments returns the documents of the collection of the ref parameter sorted by _id as they are stored, so the
+// fields that the ref structure does not decode, or that it decodes with the zero value, are kept. The template
+// parameter must be a Template or a mongo.Template, otherwise ErrNotSupported is returned.
+func rawDocuments(ctx context.Context, template mongo.TemplateAPI, ref any) ([]bson.D, error) {
+	databaseName, collectionName, err := getNamesByAny(ref)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	switch t := template.(type) {
+	case *Template:
+		return t.query(ref, bson.D{}, bson.D{{"_id", 1}}, nil, nil, nil)
+	case interface{ GetClient() driver.Client }:
+		client := t.GetClient()
+		cursor, err := client.Database(databaseName).Collection(collectionName).Find(ctx, bson.D{},
+			options.Find().SetSort(bson.D{{"_id", 1}}))
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		var result []bson.D
+		err = cursor.All(ctx, &result)
+		return result, err
+	default:
+		return nil, fmt.Errorf("%w: dump of %T", ErrNotSupported, template)
+	}
+}
+
+// normalizer replaces the values that change between the runs of a test.
+type normalizer struct {
+	ids map[primitive.ObjectID]int
+}
+
+func (n *normalizer) document(document bson.D) bson.D {
+	result := make(bson.D, len(document))
+	for i, e := range document {
+		result[i] = bson.E{Key: e.Key, Value: n.value(e.Value)}
+	}
+	return result
+}
+
+func (n *normalizer) value(v any) any {
+	switch t := v.(type) {
+	case bson.D:
+		return n.document(t)
+	case bson.A:
+		result := make(bson.A, len(t))
+		for i, item := range t {
+			result[i] = n.value(item)
+		}
+		return result
+	case primitive.ObjectID:
+		if _, ok := n.ids[t]; !ok {
+			n.ids[t] = len(n.ids) + 1
+		}
+		return fmt.Sprintf("ObjectId(%d)", n.ids[t])
+	case primitive.DateTime, primitive.Timestamp:
+		return "<timestamp>"
+	default:
+		return v
+	}
+}
@@ -0,0 +1,66 @@
+package mongotest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func TestLoadFixtures(t *testing.T) {
+	ctx := context.TODO()
+	template := NewTemplate()
+	if err := LoadFixtures(ctx, template, "testdata/fixtures", testStruct{}); helper.IsNotNil(err) {
+		t.Fatalf("LoadFixtures() error = %v", err)
+	}
+	count, _ := template.CountDocuments(ctx, bson.M{}, testStruct{})
+	if helper.IsNotEqualTo(count, int64(3)) {
+		t.Errorf("CountDocuments() = %v, want 3", count)
+	}
+	var dest testStruct
+	err := template.FindOne(ctx, bson.M{"name": "Ana"}, &dest)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(dest.Id.Hex(), "65a000000000000000000001") ||
+		helper.IsNotEqualTo(dest.CreatedAt.Year(), 2024) {
+		t.Errorf("FindOne() dest = %+v, err = %v", dest, err)
+	}
+	type testOther struct {
+		Name string `bson:"name" database:"test" collection:"other"`
+	}
+	err = LoadFixtures(ctx, NewTemplate(), "testdata/fixtures", testOther{})
+	if !errors.Is(err, ErrFixtureRefNotFound) {
+		t.Errorf("LoadFixtures() error = %v, want %v", err, ErrFixtureRefNotFound)
+	}
+}
+
+func TestAssertCollection(t *testing.T) {
+	ctx := context.TODO()
+	template := NewTemplate()
+	if err := LoadFixtures(ctx, template, "testdata/fixtures", testStruct{}); helper.IsNotNil(err) {
+		t.Fatalf("LoadFixtures() error = %v", err)
+	}
+	_, _ = template.UpdateOne(ctx, bson.M{"name": "Bruno"}, bson.M{"$push": bson.M{"tags": "c"}}, testStruct{})
+	AssertCollection(t, template, testStruct{}, "testdata/golden/test.json")
+}
+
+func TestDumpCollection(t *testing.T) {
+	ctx := context.TODO()
+	template := NewTemplate()
+	type testExtra struct {
+		Name  string `bson:"name" database:"test" collection:"test"`
+		Extra string `bson:"extra"`
+	}
+	if err := template.InsertOne(ctx, &testExtra{Name: "Ana", Extra: "kept"}); helper.IsNotNil(err) {
+		t.Fatalf("InsertOne() error = %v", err)
+	}
+	got, err := dumpCollection(ctx, template, testStruct{})
+	if helper.IsNotNil(err) || !bytes.Contains(got, []byte(`"extra": "kept"`)) {
+		t.Errorf("dumpCollection() = %s, err = %v, want the extra field", got, err)
+	}
+	_, err = dumpCollection(ctx, struct{ mongo.TemplateAPI }{template}, testStruct{})
+	if !errors.Is(err, ErrNotSupported) {
+		t.Errorf("dumpCollection() error = %v, want %v", err, ErrNotSupported)
+	}
+}
@@ -0,0 +1,247 @@
+package mongotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	driver "go.mongodb.org/mongo-driver/mongo"
+	"strings"
+)
+
+// duplicateKeyCode is the server error code of the unique index violations.
+const duplicateKeyCode = 11000
+
+// idIndexName is the name of the index of the _id field, that every collection has.
+const idIndexName = "_id_"
+
+type index struct {
+	name   string
+	keys   bson.D
+	unique bool
+}
+
+// CreateOneIndex creates the index on the collection of the input.Ref, the unique indexes are enforced on the next
+// writes, see mongo.Template.CreateOneIndex.
+func (t *Template) CreateOneIndex(_ context.Context, input mongo.IndexInput) (string, error) {
+	databaseName, collectionName, err := getNamesByAny(input.Ref)
+	if helper.IsNotNil(err) {
+		return "", err
+	}
+	keys, err := toDocument(input.Keys)
+	if helper.IsNotNil(err) {
+		return "", err
+	} else if helper.IsEmpty(keys) {
+		return "", errors.New("mongotest: index keys cannot be empty")
+	}
+	newIndex := index{name: indexName(keys), keys: keys}
+	if helper.IsNotNil(input.Options) {
+		if helper.IsNotNil(input.Options.Name) {
+			newIndex.name = *input.Options.Name
+		}
+		newIndex.unique = isTrue(input.Options.Unique)
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	c := t.collection(databaseName, collectionName, true)
+	for _, current := range c.indexes {
+		if helper.IsNotEqualTo(current.name, newIndex.name) {
+			continue
+		} else if helper.Equals(compareDocuments(current.keys, newIndex.keys), 0) &&
+			helper.Equals(current.unique, newIndex.unique) {
+			return current.name, nil
+		}
+		return "", fmt.Errorf("mongotest: an index with the name %s already exists with different options",
+			newIndex.name)
+	}
+	if newIndex.unique {
+		for i, document := range c.documents {
+			if err = c.checkIndex(newIndex, document, i); helper.IsNotNil(err) {
+				return "", err
+			}
+		}
+	}
+	c.indexes = append(c.indexes, newIndex)
+	return newIndex.name, nil
+}
+
+// CreateManyIndex creates the indexes, see CreateOneIndex.
+func (t *Template) CreateManyIndex(ctx context.Context, inputs []mongo.IndexInput) ([]string, error) {
+	var result []string
+	for _, input := range inputs {
+		name, err := t.CreateOneIndex(ctx, input)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		result = append(result, name)
+	}
+	return result, nil
+}
+
+// DropOneIndex drops the index with the name from the collection of the ref parameter.
+func (t *Template) DropOneIndex(_ context.Context, name string, ref any, _ ...*option.DropIndex) error {
+	databaseName, collectionName, err := getNamesByAny(ref)
+	if helper.IsNotNil(err) {
+		return err
+	} else if helper.Equals(name, idIndexName) {
+		return errors.New("mongotest: cannot drop _id index")
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	c := t.collection(databaseName, collectionName, false)
+	if helper.IsNil(c) {
+		return ErrIndexNotFound
+	}
+	for i, current := range c.indexes {
+		if helper.Equals(current.name, name) {
+			c.indexes = append(c.indexes[:i:i], c.indexes[i+1:]...)
+			return nil
+		}
+	}
+	return ErrIndexNotFound
+}
+
+// DropAllIndexes drops all the indexes from the collection of the ref parameter, except the _id index.
+func (t *Template) DropAllIndexes(_ context.Context, ref any, _ ...*option.DropIndex) error {
+	databaseName, collectionName, err := getNamesByAny(ref)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if c := t.collection(databaseName, collectionName, false); helper.IsNotNil(c) {
+		c.indexes = nil
+	}
+	return nil
+}
+
+// ListIndexes returns the indexes of the collection of the ref parameter, starting with the _id index.
+func (t *Template) ListIndexes(_ context.Context, ref any, _ ...*option.ListIndexes) ([]mongo.IndexResult, error) {
+	databaseName, collectionName, indexes, err := t.listIndexes(ref)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	ns := namespace(databaseName, collectionName)
+	result := make([]mongo.IndexResult, 0, len(indexes))
+	for _, current := range indexes {
+		key := primitive.M{}
+		for _, e := range current.keys {
+			key[e.Key] = e.Value
+		}
+		result = append(result, mongo.IndexResult{
+			Ns: ns,
+			FirstBatch: mongo.FirstBatchIndex{
+				V:    2,
+				Key:  key,
+				Name: current.name,
+				Ns:   ns,
+			},
+		})
+	}
+	return result, nil
+}
+
+// ListIndexSpecifications returns the specifications of the indexes of the collection of the ref parameter,
+// starting with the _id index.
+func (t *Template) ListIndexSpecifications(_ context.Context, ref any, _ ...*option.ListIndexes) (
+	[]mongo.IndexSpecification, error) {
+	databaseName, collectionName, indexes, err := t.listIndexes(ref)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	result := make([]mongo.IndexSpecification, 0, len(indexes))
+	for _, current := range indexes {
+		keysDocument, err := bson.Marshal(current.keys)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		specification := mongo.IndexSpecification{
+			Name:         current.name,
+			Namespace:    namespace(databaseName, collectionName),
+			KeysDocument: keysDocument,
+			Version:      2,
+		}
+		if current.unique {
+			specification.Unique = &current.unique
+		}
+		result = append(result, specification)
+	}
+	return result, nil
+}
+
+func (t *Template) listIndexes(ref any) (string, string, []index, error) {
+	databaseName, collectionName, err := getNamesByAny(ref)
+	if helper.IsNotNil(err) {
+		return "", "", nil, err
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	c := t.collection(databaseName, collectionName, false)
+	if helper.IsNil(c) {
+		return databaseName, collectionName, nil, nil
+	}
+	indexes := append([]index{{name: idIndexName, keys: bson.D{{"_id", int32(1)}}, unique: true}}, c.indexes...)
+	return databaseName, collectionName, indexes, nil
+}
+
+// checkUnique returns the duplicate key error if the document violates the _id or an unique index of the collection,
+// the document at the skip position, the one being replaced, is not compared.
+func (c *collection) checkUnique(document bson.D, skip int) error {
+	indexes := append([]index{{name: idIndexName, keys: bson.D{{"_id", int32(1)}}, unique: true}}, c.indexes...)
+	for _, current := range indexes {
+		if !current.unique {
+			continue
+		} else if err := c.checkIndex(current, document, skip); helper.IsNotNil(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *collection) checkIndex(current index, document bson.D, skip int) error {
+	key := indexKey(current, document)
+	for i, other := range c.documents {
+		if helper.IsNotEqualTo(i, skip) && helper.Equals(compareArrays(key, indexKey(current, other)), 0) {
+			return c.duplicateKeyError(current, key)
+		}
+	}
+	return nil
+}
+
+// duplicateKeyError returns the error of the server, so mongo.IsDuplicateKeyError of the driver is true.
+func (c *collection) duplicateKeyError(current index, key bson.A) error {
+	dupKey := make([]string, 0, len(current.keys))
+	for i, e := range current.keys {
+		dupKey = append(dupKey, fmt.Sprintf("%s: %v", e.Key, key[i]))
+	}
+	return driver.WriteException{
+		WriteErrors: driver.WriteErrors{{
+			Code: duplicateKeyCode,
+			Message: fmt.Sprintf("E11000 duplicate key error collection: %s index: %s dup key: { %s }",
+				namespace(c.databaseName, c.collectionName), current.name, strings.Join(dupKey, ", ")),
+		}},
+	}
+}
+
+// indexKey returns the values of the index keys on the document, the missing ones are null.
+func indexKey(current index, document bson.D) bson.A {
+	result := make(bson.A, 0, len(current.keys))
+	for _, e := range current.keys {
+		value, _ := getPath(document, splitPath(e.Key))
+		result = append(result, value)
+	}
+	return result
+}
+
+// indexName returns the default name of the index keys, e.g. "name_1_age_-1".
+func indexName(keys bson.D) string {
+	parts := make([]string, 0, len(keys)*2)
+	for _, e := range keys {
+		parts = append(parts, e.Key, fmt.Sprint(e.Value))
+	}
+	return strings.Join(parts, "_")
+}
@@ -0,0 +1,765 @@
+package mongotest
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// toDocument converts the value to bson.D, like it is sent to the server, so the nested documents are bson.D and the
+// arrays are bson.A, a nil value is an empty document.
+func toDocument(v any) (bson.D, error) {
+	if v == nil {
+		return bson.D{}, nil
+	}
+	b, err := bson.Marshal(v)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	var result bson.D
+	err = bson.Unmarshal(b, &result)
+	return result, err
+}
+
+// toValue converts the value to its bson representation, e.g. time.Time to primitive.DateTime.
+func toValue(v any) (any, error) {
+	document, err := toDocument(bson.D{{"v", v}})
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return document[0].Value, nil
+}
+
+// toPipeline converts the pipeline parameter to its stages.
+func toPipeline(pipeline any) ([]bson.D, error) {
+	document, err := toDocument(bson.D{{"pipeline", pipeline}})
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	stages, _ := document[0].Value.(bson.A)
+	result := make([]bson.D, 0, len(stages))
+	for _, stage := range stages {
+		d, ok := stage.(bson.D)
+		if !ok || helper.IsNotEqualTo(len(d), 1) {
+			return nil, fmt.Errorf("mongotest: invalid pipeline stage %v", stage)
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+// copyValue returns a deep copy of the bson.D and bson.A of the value, the other bson values are immutable.
+func copyValue(v any) any {
+	switch value := v.(type) {
+	case bson.D:
+		result := make(bson.D, len(value))
+		for i, e := range value {
+			result[i] = bson.E{Key: e.Key, Value: copyValue(e.Value)}
+		}
+		return result
+	case bson.A:
+		result := make(bson.A, len(value))
+		for i, item := range value {
+			result[i] = copyValue(item)
+		}
+		return result
+	}
+	return v
+}
+
+func copyDocument(document bson.D) bson.D {
+	return copyValue(document).(bson.D)
+}
+
+// lookup returns the values of the dotted path on the value, traversing the arrays of documents like the server, it
+// returns empty if the path does not exist.
+func lookup(v any, parts []string) []any {
+	if helper.IsEmpty(parts) {
+		return []any{v}
+	}
+	switch value := v.(type) {
+	case bson.D:
+		for _, e := range value {
+			if helper.Equals(e.Key, parts[0]) {
+				return lookup(e.Value, parts[1:])
+			}
+		}
+	case bson.A:
+		if i, err := strconv.Atoi(parts[0]); helper.IsNil(err) {
+			if helper.IsGreaterThanOrEqual(i, 0) && helper.IsLessThan(i, len(value)) {
+				return lookup(value[i], parts[1:])
+			}
+			return nil
+		}
+		var result []any
+		for _, item := range value {
+			if document, ok := item.(bson.D); ok {
+				result = append(result, lookup(document, parts)...)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func splitPath(path string) []string {
+	return strings.Split(path, ".")
+}
+
+// candidates returns the values with the elements of the array values, the ones compared by the query operators.
+func candidates(values []any) []any {
+	result := make([]any, 0, len(values))
+	for _, v := range values {
+		result = append(result, v)
+		if array, ok := v.(bson.A); ok {
+			result = append(result, array...)
+		}
+	}
+	return result
+}
+
+// matches returns true if the document matches the filter.
+func matches(document bson.D, filter bson.D) (bool, error) {
+	for _, e := range filter {
+		var ok bool
+		var err error
+		switch e.Key {
+		case "$and", "$or", "$nor":
+			ok, err = matchLogical(document, e.Key, e.Value)
+		case "$comment":
+			ok = true
+		default:
+			if strings.HasPrefix(e.Key, "$") {
+				return false, fmt.Errorf("%w: query operator %s", ErrNotSupported, e.Key)
+			}
+			ok, err = matchValues(lookup(document, splitPath(e.Key)), e.Value)
+		}
+		if helper.IsNotNil(err) || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchLogical(document bson.D, operator string, v any) (bool, error) {
+	filters, ok := v.(bson.A)
+	if !ok || helper.IsEmpty(filters) {
+		return false, fmt.Errorf("mongotest: %s argument must be a non-empty array", operator)
+	}
+	for _, item := range filters {
+		filter, ok := item.(bson.D)
+		if !ok {
+			return false, fmt.Errorf("mongotest: %s elements must be documents", operator)
+		}
+		ok, err := matches(document, filter)
+		if helper.IsNotNil(err) {
+			return false, err
+		} else if ok && helper.Equals(operator, "$or") {
+			return true, nil
+		} else if ok && helper.Equals(operator, "$nor") {
+			return false, nil
+		} else if !ok && helper.Equals(operator, "$and") {
+			return false, nil
+		}
+	}
+	return !helper.Equals(operator, "$or"), nil
+}
+
+func isOperatorDocument(v any) bool {
+	document, ok := v.(bson.D)
+	return ok && helper.IsNotEmpty(document) && strings.HasPrefix(document[0].Key, "$")
+}
+
+// matchValues returns true if the values of a path match the condition, an operator document or a value.
+func matchValues(values []any, condition any) (bool, error) {
+	if isOperatorDocument(condition) {
+		return matchOperators(values, condition.(bson.D))
+	} else if regex, ok := condition.(primitive.Regex); ok {
+		return matchRegex(values, regex)
+	}
+	return matchEquality(values, condition), nil
+}
+
+func matchEquality(values []any, v any) bool {
+	if v == nil && helper.IsEmpty(values) {
+		return true
+	}
+	for _, candidate := range candidates(values) {
+		if equal(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchOperators(values []any, operators bson.D) (bool, error) {
+	for _, operator := range operators {
+		var ok bool
+		var err error
+		switch operator.Key {
+		case "$eq":
+			ok = matchEquality(values, operator.Value)
+		case "$ne":
+			ok = !matchEquality(values, operator.Value)
+		case "$gt", "$gte", "$lt", "$lte":
+			ok = matchComparison(values, operator.Key, operator.Value)
+		case "$in", "$nin":
+			ok, err = matchIn(values, operator.Value)
+			ok = ok != helper.Equals(operator.Key, "$nin")
+		case "$exists":
+			ok = truthy(operator.Value) == helper.IsNotEmpty(values)
+		case "$regex":
+			ok, err = matchRegex(values, newRegex(operator.Value, operators))
+		case "$options":
+			ok = true
+		case "$size":
+			ok = matchSize(values, operator.Value)
+		case "$all":
+			ok, err = matchAll(values, operator.Value)
+		case "$elemMatch":
+			ok, err = matchElement(values, operator.Value)
+		case "$not":
+			ok, err = matchValues(values, operator.Value)
+			ok = !ok
+		default:
+			err = fmt.Errorf("%w: query operator %s", ErrNotSupported, operator.Key)
+		}
+		if helper.IsNotNil(err) || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchComparison(values []any, operator string, v any) bool {
+	for _, candidate := range candidates(values) {
+		if helper.IsNotEqualTo(typeOrder(candidate), typeOrder(v)) {
+			continue
+		}
+		c := compare(candidate, v)
+		switch operator {
+		case "$gt":
+			if helper.IsGreaterThan(c, 0) {
+				return true
+			}
+		case "$gte":
+			if helper.IsGreaterThanOrEqual(c, 0) {
+				return true
+			}
+		case "$lt":
+			if helper.IsLessThan(c, 0) {
+				return true
+			}
+		case "$lte":
+			if helper.IsLessThanOrEqual(c, 0) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchIn(values []any, v any) (bool, error) {
+	array, ok := v.(bson.A)
+	if !ok {
+		return false, fmt.Errorf("mongotest: $in and $nin arguments must be arrays")
+	}
+	for _, item := range array {
+		if regex, ok := item.(primitive.Regex); ok {
+			if ok, err := matchRegex(values, regex); helper.IsNotNil(err) || ok {
+				return ok, err
+			}
+		} else if matchEquality(values, item) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func matchSize(values []any, v any) bool {
+	for _, value := range values {
+		if array, ok := value.(bson.A); ok && isNumber(v) && helper.Equals(int64(len(array)), toInt(v)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAll(values []any, v any) (bool, error) {
+	array, ok := v.(bson.A)
+	if !ok {
+		return false, fmt.Errorf("mongotest: $all argument must be an array")
+	}
+	for _, item := range array {
+		ok, err := matchValues(values, item)
+		if helper.IsNotNil(err) || !ok {
+			return false, err
+		}
+	}
+	return helper.IsNotEmpty(array), nil
+}
+
+func matchElement(values []any, v any) (bool, error) {
+	condition, ok := v.(bson.D)
+	if !ok {
+		return false, fmt.Errorf("mongotest: $elemMatch argument must be a document")
+	}
+	for _, value := range values {
+		array, ok := value.(bson.A)
+		if !ok {
+			continue
+		}
+		for _, item := range array {
+			var ok bool
+			var err error
+			if isOperatorDocument(condition) {
+				ok, err = matchOperators([]any{item}, condition)
+			} else if document, isDocument := item.(bson.D); isDocument {
+				ok, err = matches(document, condition)
+			}
+			if helper.IsNotNil(err) || ok {
+				return ok, err
+			}
+		}
+	}
+	return false, nil
+}
+
+func newRegex(v any, operators bson.D) primitive.Regex {
+	regex, ok := v.(primitive.Regex)
+	if !ok {
+		regex = primitive.Regex{Pattern: fmt.Sprint(v)}
+	}
+	for _, operator := range operators {
+		if helper.Equals(operator.Key, "$options") {
+			regex.Options = fmt.Sprint(operator.Value)
+		}
+	}
+	return regex
+}
+
+func matchRegex(values []any, regex primitive.Regex) (bool, error) {
+	var flags string
+	for _, option := range regex.Options {
+		if strings.ContainsRune("ims", option) {
+			flags += string(option)
+		}
+	}
+	pattern := regex.Pattern
+	if helper.IsNotEmpty(flags) {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	compiled, err := regexp.Compile(pattern)
+	if helper.IsNotNil(err) {
+		return false, err
+	}
+	for _, candidate := range candidates(values) {
+		if s, ok := candidate.(string); ok && compiled.MatchString(s) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func truthy(v any) bool {
+	switch value := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return value
+	}
+	if isNumber(v) {
+		return helper.IsNotEqualTo(toFloat(v), float64(0))
+	}
+	return true
+}
+
+func isNumber(v any) bool {
+	switch v.(type) {
+	case int32, int64, float64, primitive.Decimal128:
+		return true
+	}
+	return false
+}
+
+func isIntegral(v any) bool {
+	switch v.(type) {
+	case int32, int64:
+		return true
+	}
+	return false
+}
+
+func toFloat(v any) float64 {
+	switch value := v.(type) {
+	case int32:
+		return float64(value)
+	case int64:
+		return float64(value)
+	case float64:
+		return value
+	case primitive.Decimal128:
+		f, _ := strconv.ParseFloat(value.String(), 64)
+		return f
+	}
+	return 0
+}
+
+func toInt(v any) int64 {
+	switch value := v.(type) {
+	case int32:
+		return int64(value)
+	case int64:
+		return value
+	}
+	return int64(toFloat(v))
+}
+
+// typeOrder returns the position of the value type on the bson comparison order, the numbers share a position.
+func typeOrder(v any) int {
+	switch v.(type) {
+	case primitive.MinKey:
+		return 1
+	case nil, primitive.Null, primitive.Undefined:
+		return 2
+	case int32, int64, float64, primitive.Decimal128:
+		return 3
+	case string, primitive.Symbol:
+		return 4
+	case bson.D:
+		return 5
+	case bson.A:
+		return 6
+	case primitive.Binary:
+		return 7
+	case primitive.ObjectID:
+		return 8
+	case bool:
+		return 9
+	case primitive.DateTime:
+		return 10
+	case primitive.Timestamp:
+		return 11
+	case primitive.Regex:
+		return 12
+	case primitive.MaxKey:
+		return 14
+	}
+	return 13
+}
+
+func equal(a, b any) bool {
+	return helper.Equals(typeOrder(a), typeOrder(b)) && helper.Equals(compare(a, b), 0)
+}
+
+// compare compares the values following the bson comparison order.
+func compare(a, b any) int {
+	if orderA, orderB := typeOrder(a), typeOrder(b); helper.IsNotEqualTo(orderA, orderB) {
+		return compareInt(int64(orderA), int64(orderB))
+	}
+	switch x := a.(type) {
+	case int32, int64, float64, primitive.Decimal128:
+		if isIntegral(a) && isIntegral(b) {
+			return compareInt(toInt(a), toInt(b))
+		}
+		return compareFloat(toFloat(a), toFloat(b))
+	case string, primitive.Symbol:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	case bson.D:
+		return compareDocuments(x, b.(bson.D))
+	case bson.A:
+		return compareArrays(x, b.(bson.A))
+	case primitive.Binary:
+		return bytes.Compare(x.Data, b.(primitive.Binary).Data)
+	case primitive.ObjectID:
+		y := b.(primitive.ObjectID)
+		return bytes.Compare(x[:], y[:])
+	case bool:
+		return compareInt(boolToInt(x), boolToInt(b.(bool)))
+	case primitive.DateTime:
+		return compareInt(int64(x), int64(b.(primitive.DateTime)))
+	case primitive.Timestamp:
+		y := b.(primitive.Timestamp)
+		if helper.IsNotEqualTo(x.T, y.T) {
+			return compareInt(int64(x.T), int64(y.T))
+		}
+		return compareInt(int64(x.I), int64(y.I))
+	case primitive.Regex:
+		return strings.Compare(x.String(), b.(primitive.Regex).String())
+	}
+	return 0
+}
+
+func compareDocuments(a, b bson.D) int {
+	for i := 0; helper.IsLessThan(i, len(a)) && helper.IsLessThan(i, len(b)); i++ {
+		if orderA, orderB := typeOrder(a[i].Value), typeOrder(b[i].Value); helper.IsNotEqualTo(orderA, orderB) {
+			return compareInt(int64(orderA), int64(orderB))
+		} else if c := strings.Compare(a[i].Key, b[i].Key); helper.IsNotEqualTo(c, 0) {
+			return c
+		} else if c = compare(a[i].Value, b[i].Value); helper.IsNotEqualTo(c, 0) {
+			return c
+		}
+	}
+	return compareInt(int64(len(a)), int64(len(b)))
+}
+
+func compareArrays(a, b bson.A) int {
+	for i := 0; helper.IsLessThan(i, len(a)) && helper.IsLessThan(i, len(b)); i++ {
+		if c := compare(a[i], b[i]); helper.IsNotEqualTo(c, 0) {
+			return c
+		}
+	}
+	return compareInt(int64(len(a)), int64(len(b)))
+}
+
+func compareInt(a, b int64) int {
+	if helper.IsLessThan(a, b) {
+		return -1
+	} else if helper.IsGreaterThan(a, b) {
+		return 1
+	}
+	return 0
+}
+
+func compareFloat(a, b float64) int {
+	if helper.IsLessThan(a, b) {
+		return -1
+	} else if helper.IsGreaterThan(a, b) {
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// sortDocuments sorts the documents by the sort specification, see newSortLess.
+func sortDocuments(documents []bson.D, sortSpecification any) error {
+	less, err := newSortLess(sortSpecification)
+	if helper.IsNotNil(err) || less == nil {
+		return err
+	}
+	sort.SliceStable(documents, func(i, j int) bool {
+		return less(documents[i], documents[j])
+	})
+	return nil
+}
+
+// newSortLess returns the less function of the sort specification, or nil without specification. The arrays are
+// sorted by their lowest element on ascending order and by their highest element on descending order, like the server.
+func newSortLess(sortSpecification any) (func(a, b bson.D) bool, error) {
+	if helper.IsNil(sortSpecification) {
+		return nil, nil
+	}
+	specification, err := toDocument(sortSpecification)
+	if helper.IsNotNil(err) || helper.IsEmpty(specification) {
+		return nil, err
+	}
+	return func(a, b bson.D) bool {
+		for _, e := range specification {
+			direction := 1
+			if helper.IsLessThan(toFloat(e.Value), float64(0)) {
+				direction = -1
+			}
+			parts := splitPath(e.Key)
+			c := compare(sortKey(a, parts, direction), sortKey(b, parts, direction))
+			if helper.IsNotEqualTo(c, 0) {
+				return helper.IsLessThan(c*direction, 0)
+			}
+		}
+		return false
+	}, nil
+}
+
+func sortKey(document bson.D, parts []string, direction int) any {
+	var result any
+	for i, value := range lookup(document, parts) {
+		values := []any{value}
+		if array, ok := value.(bson.A); ok && helper.IsNotEmpty(array) {
+			values = array
+		}
+		for j, v := range values {
+			if (helper.Equals(i, 0) && helper.Equals(j, 0)) ||
+				helper.IsLessThan(compare(v, result)*direction, 0) {
+				result = v
+			}
+		}
+	}
+	return result
+}
+
+// project returns the document with the fields of the projection, inclusions or exclusions of dotted paths.
+func project(document bson.D, projection any) (bson.D, error) {
+	if helper.IsNil(projection) {
+		return document, nil
+	}
+	specification, err := toDocument(projection)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	includeId := true
+	inclusion := false
+	for _, e := range specification {
+		if isOperatorDocument(e.Value) {
+			return nil, fmt.Errorf("%w: projection operator %s", ErrNotSupported, e.Value.(bson.D)[0].Key)
+		} else if helper.Equals(e.Key, "_id") {
+			includeId = truthy(e.Value)
+		} else if truthy(e.Value) {
+			inclusion = true
+		}
+	}
+	if !inclusion {
+		result := copyDocument(document)
+		for _, e := range specification {
+			if !truthy(e.Value) {
+				result = unsetPath(result, splitPath(e.Key))
+			}
+		}
+		return result, nil
+	}
+	result := bson.D{}
+	for _, e := range specification {
+		if helper.Equals(e.Key, "_id") || !truthy(e.Value) {
+			continue
+		}
+		parts := splitPath(e.Key)
+		if value, ok := getPath(document, parts); ok {
+			result, err = setPath(result, parts, copyValue(value))
+			if helper.IsNotNil(err) {
+				return nil, err
+			}
+		}
+	}
+	if id, ok := getPath(document, []string{"_id"}); ok && includeId {
+		result = append(bson.D{{"_id", id}}, result...)
+	}
+	return result, nil
+}
+
+// aggregateStage applies the aggregation stage on the documents.
+func aggregateStage(documents []bson.D, stage bson.E) ([]bson.D, error) {
+	switch stage.Key {
+	case "$match":
+		filter, ok := stage.Value.(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("mongotest: $match argument must be a document")
+		}
+		result := make([]bson.D, 0, len(documents))
+		for _, document := range documents {
+			ok, err := matches(document, filter)
+			if helper.IsNotNil(err) {
+				return nil, err
+			} else if ok {
+				result = append(result, document)
+			}
+		}
+		return result, nil
+	case "$sort":
+		return documents, sortDocuments(documents, stage.Value)
+	case "$skip":
+		return documents[min(int(toInt(stage.Value)), len(documents)):], nil
+	case "$limit":
+		return documents[:min(int(toInt(stage.Value)), len(documents))], nil
+	case "$project", "$unset":
+		projection := stage.Value
+		if helper.Equals(stage.Key, "$unset") {
+			projection = unsetProjection(stage.Value)
+		}
+		result := make([]bson.D, 0, len(documents))
+		for _, document := range documents {
+			document, err := project(document, projection)
+			if helper.IsNotNil(err) {
+				return nil, err
+			}
+			result = append(result, document)
+		}
+		return result, nil
+	case "$count":
+		return []bson.D{{{fmt.Sprint(stage.Value), int32(len(documents))}}}, nil
+	case "$group":
+		group, ok := stage.Value.(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("mongotest: $group argument must be a document")
+		}
+		return groupDocuments(documents, group)
+	}
+	return nil, fmt.Errorf("%w: aggregation stage %s", ErrNotSupported, stage.Key)
+}
+
+// unsetProjection returns the exclusion projection of the $unset stage argument, a field or an array of fields.
+func unsetProjection(v any) bson.D {
+	fields, ok := v.(bson.A)
+	if !ok {
+		fields = bson.A{v}
+	}
+	result := make(bson.D, 0, len(fields))
+	for _, field := range fields {
+		result = append(result, bson.E{Key: fmt.Sprint(field), Value: int32(0)})
+	}
+	return result
+}
+
+// groupDocuments applies the $group stage, the _id can be a constant or a field path, and the accumulators only
+// support the $sum of constants or field paths.
+func groupDocuments(documents []bson.D, group bson.D) ([]bson.D, error) {
+	var groupId any
+	for _, e := range group {
+		if helper.Equals(e.Key, "_id") {
+			groupId = e.Value
+			continue
+		}
+		accumulator, ok := e.Value.(bson.D)
+		if !ok || helper.IsNotEqualTo(len(accumulator), 1) || helper.IsNotEqualTo(accumulator[0].Key, "$sum") {
+			return nil, fmt.Errorf("%w: $group accumulator of %s", ErrNotSupported, e.Key)
+		}
+	}
+	var result []bson.D
+	for _, document := range documents {
+		key := expressionValue(document, groupId)
+		var current bson.D
+		for _, item := range result {
+			if equal(item[0].Value, key) {
+				current = item
+				break
+			}
+		}
+		if helper.IsNil(current) {
+			current = bson.D{{"_id", key}}
+			for _, e := range group {
+				if helper.IsNotEqualTo(e.Key, "_id") {
+					current = append(current, bson.E{Key: e.Key, Value: int32(0)})
+				}
+			}
+			result = append(result, current)
+		}
+		for i, e := range current[1:] {
+			v, _ := getPath(group, []string{e.Key})
+			accumulator := v.(bson.D)
+			// the non-numeric values are ignored by $sum
+			if v := expressionValue(document, accumulator[0].Value); isNumber(v) {
+				current[i+1].Value, _ = arithmetic(e.Value, v, "$inc")
+			}
+		}
+	}
+	return result, nil
+}
+
+// expressionValue returns the value of the field path, e.g. "$age", or the constant.
+func expressionValue(document bson.D, expression any) any {
+	path, ok := expression.(string)
+	if !ok || !strings.HasPrefix(path, "$") {
+		return expression
+	}
+	v, _ := getPath(document, splitPath(path[1:]))
+	return v
+}
@@ -0,0 +1,483 @@
+package mongotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Recorder captures the commands sent to the server by a mongo.Template, so the tests can assert the filters,
+// updates, pipelines and options issued by each operation, e.g. the sort and limit of FindPageable. Configure it on
+// the client options with SetMonitor:
+//
+//	recorder := mongotest.NewRecorder()
+//	template, err := mongo.NewTemplate(ctx, options.Client().ApplyURI(uri).SetMonitor(recorder.Monitor()))
+//
+// The recorded commands can be executed on the in-memory Template with Replay.
+type Recorder struct {
+	mutex    sync.Mutex
+	commands []*Command
+	started  map[int64]*Command
+	next     *event.CommandMonitor
+}
+
+// Command is a command recorded by the Recorder, with the arguments of the CRUD commands extracted. For the update
+// and delete commands, the Filter, Update, Upsert and Multi are of the first statement, the Template sends one
+// statement by command.
+type Command struct {
+	// Name command name, e.g. find, aggregate, insert, update, delete, findAndModify
+	Name string
+	// DatabaseName database name of the command
+	DatabaseName string
+	// CollectionName collection name of the command, empty for the database commands
+	CollectionName string
+	// Filter filter of the find, query of the count, distinct and findAndModify, and q of the update and delete
+	Filter bson.D
+	// Update update document, or pipeline as bson.A, of the update and findAndModify
+	Update any
+	// Pipeline stages of the aggregate
+	Pipeline []bson.D
+	// Documents documents of the insert
+	Documents []bson.D
+	// Sort sort of the find and findAndModify
+	Sort bson.D
+	// Projection projection of the find, and fields of the findAndModify
+	Projection bson.D
+	// Skip skip of the find and count
+	Skip int64
+	// Limit limit of the find, count and delete, zero if not sent
+	Limit int64
+	// Upsert upsert of the update and findAndModify
+	Upsert bool
+	// Multi multi of the update
+	Multi bool
+	// Remove remove of the findAndModify
+	Remove bool
+	// New new of the findAndModify, true if the document after the update is returned
+	New bool
+	// Body complete command document
+	Body bson.D
+	// Duration duration of the command
+	Duration time.Duration
+	// Err error returned by the server, nil if the command succeeded
+	Err error
+}
+
+// Reply is the result of a command executed by Recorder.Replay.
+type Reply struct {
+	// Command replayed command
+	Command Command
+	// Documents documents returned by the find and aggregate, and the document returned by the findAndModify
+	Documents []bson.D
+	// Count number of documents counted, inserted, matched by the update or deleted
+	Count int64
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{started: map[int64]*Command{}}
+}
+
+// Monitor returns the command monitor that records the commands, calling the next monitor parameter, if any, so it
+// can be chained with an existing monitor.
+func (r *Recorder) Monitor(next ...*event.CommandMonitor) *event.CommandMonitor {
+	if helper.IsNotEmpty(next) {
+		r.next = next[0]
+	}
+	return &event.CommandMonitor{
+		Started:   r.commandStarted,
+		Succeeded: r.commandSucceeded,
+		Failed:    r.commandFailed,
+	}
+}
+
+// Commands returns the finished commands with the name and namespace, database and collection names separated by a
+// dot, in the order they were sent. The empty parameters match any command.
+func (r *Recorder) Commands(name, namespace string) []Command {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var result []Command
+	for _, command := range r.commands {
+		if command.match(name, namespace) {
+			result = append(result, *command)
+		}
+	}
+	return result
+}
+
+// Last returns the last finished command with the name and namespace, see Commands.
+func (r *Recorder) Last(name, namespace string) (Command, bool) {
+	commands := r.Commands(name, namespace)
+	if helper.IsEmpty(commands) {
+		return Command{}, false
+	}
+	return commands[len(commands)-1], true
+}
+
+// Reset removes the recorded commands.
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.commands = nil
+}
+
+// AssertCount fails the test if the number of commands with the name and namespace is not the want parameter.
+func (r *Recorder) AssertCount(t testing.TB, name, namespace string, want int) {
+	t.Helper()
+	if got := len(r.Commands(name, namespace)); helper.IsNotEqualTo(got, want) {
+		t.Errorf("mongotest: %d %s commands on %s, want %d", got, name, namespace, want)
+	}
+}
+
+// AssertFilter fails the test if the filter of the last command with the name and namespace is not equal to the
+// want parameter. The fields of the documents are compared in any order, the elements of the arrays in order.
+func (r *Recorder) AssertFilter(t testing.TB, name, namespace string, want any) {
+	t.Helper()
+	command := r.assertLast(t, name, namespace)
+	if helper.IsNil(command) {
+		return
+	}
+	wantDocument, err := toDocument(want)
+	if helper.IsNotNil(err) {
+		t.Fatal("mongotest: error converting filter:", err)
+	}
+	if !sameValue(command.Filter, wantDocument) {
+		t.Errorf("mongotest: %s filter on %s = %v, want %v", name, namespace, command.Filter, wantDocument)
+	}
+}
+
+// AssertPipeline fails the test if the pipeline of the last aggregate command on the namespace is not equal to the
+// want parameter, see AssertFilter.
+func (r *Recorder) AssertPipeline(t testing.TB, namespace string, want any) {
+	t.Helper()
+	command := r.assertLast(t, "aggregate", namespace)
+	if helper.IsNil(command) {
+		return
+	}
+	wantStages, err := toPipeline(want)
+	if helper.IsNotNil(err) {
+		t.Fatal("mongotest: error converting pipeline:", err)
+	}
+	got, wantValue := make(bson.A, len(command.Pipeline)), make(bson.A, len(wantStages))
+	for i, stage := range command.Pipeline {
+		got[i] = stage
+	}
+	for i, stage := range wantStages {
+		wantValue[i] = stage
+	}
+	if !sameValue(got, wantValue) {
+		t.Errorf("mongotest: aggregate pipeline on %s = %v, want %v", namespace, command.Pipeline, wantStages)
+	}
+}
+
+// Replay executes the succeeded CRUD commands on the template parameter, in the order they were sent, so the
+// behavior recorded on a server can be reproduced on the in-memory Template. The other commands, e.g. the index
+// and transaction commands, are ignored, and the update pipelines return ErrNotSupported.
+func (r *Recorder) Replay(_ context.Context, template *Template) ([]Reply, error) {
+	var result []Reply
+	for _, command := range r.Commands("", "") {
+		if helper.IsNotNil(command.Err) || helper.IsEmpty(command.CollectionName) {
+			continue
+		}
+		reply, ok, err := template.replay(command)
+		if helper.IsNotNil(err) {
+			return result, fmt.Errorf("mongotest: error replaying %s on %s: %w", command.Name, command.Namespace(),
+				err)
+		} else if ok {
+			result = append(result, reply)
+		}
+	}
+	return result, nil
+}
+
+// Namespace returns the database and collection names separated by a dot.
+func (c Command) Namespace() string {
+	return namespace(c.DatabaseName, c.CollectionName)
+}
+
+func (c *Command) match(name, namespace string) bool {
+	return (helper.IsEmpty(name) || helper.Equals(c.Name, name)) &&
+		(helper.IsEmpty(namespace) || helper.Equals(c.Namespace(), namespace))
+}
+
+func (r *Recorder) assertLast(t testing.TB, name, namespace string) *Command {
+	t.Helper()
+	command, ok := r.Last(name, namespace)
+	if !ok {
+		t.Errorf("mongotest: no %s command recorded on %s", name, namespace)
+		return nil
+	}
+	return &command
+}
+
+func (r *Recorder) commandStarted(ctx context.Context, evt *event.CommandStartedEvent) {
+	command := newCommand(evt.CommandName, evt.DatabaseName, evt.Command)
+	r.mutex.Lock()
+	r.started[evt.RequestID] = command
+	r.mutex.Unlock()
+	if helper.IsNotNil(r.next) && r.next.Started != nil {
+		r.next.Started(ctx, evt)
+	}
+}
+
+func (r *Recorder) commandSucceeded(ctx context.Context, evt *event.CommandSucceededEvent) {
+	r.finish(evt.RequestID, evt.Duration, nil)
+	if helper.IsNotNil(r.next) && r.next.Succeeded != nil {
+		r.next.Succeeded(ctx, evt)
+	}
+}
+
+func (r *Recorder) commandFailed(ctx context.Context, evt *event.CommandFailedEvent) {
+	r.finish(evt.RequestID, evt.Duration, errors.New(evt.Failure))
+	if helper.IsNotNil(r.next) && r.next.Failed != nil {
+		r.next.Failed(ctx, evt)
+	}
+}
+
+func (r *Recorder) finish(requestId int64, duration time.Duration, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	command, ok := r.started[requestId]
+	if !ok {
+		return
+	}
+	delete(r.started, requestId)
+	command.Duration = duration
+	command.Err = err
+	r.commands = append(r.commands, command)
+}
+
+// newCommand returns the command with the arguments of the raw command document extracted.
+func newCommand(name, databaseName string, raw bson.Raw) *Command {
+	command := &Command{Name: name, DatabaseName: databaseName}
+	if err := bson.Unmarshal(raw, &command.Body); helper.IsNotNil(err) || helper.IsEmpty(command.Body) {
+		return command
+	}
+	collectionName, _ := command.Body[0].Value.(string)
+	arguments := command.Body
+	switch name {
+	case "find":
+		command.Filter = documentArgument(arguments, "filter")
+		command.Sort = documentArgument(arguments, "sort")
+		command.Projection = documentArgument(arguments, "projection")
+		command.Skip = intArgument(arguments, "skip")
+		command.Limit = intArgument(arguments, "limit")
+	case "aggregate":
+		stages, _ := getPath(arguments, []string{"pipeline"})
+		command.Pipeline, _ = toPipeline(stages)
+	case "count":
+		command.Filter = documentArgument(arguments, "query")
+		command.Skip = intArgument(arguments, "skip")
+		command.Limit = intArgument(arguments, "limit")
+	case "distinct":
+		command.Filter = documentArgument(arguments, "query")
+	case "insert":
+		documents, _ := getPath(arguments, []string{"documents"})
+		items, _ := documents.(bson.A)
+		for _, item := range items {
+			if document, ok := item.(bson.D); ok {
+				command.Documents = append(command.Documents, document)
+			}
+		}
+	case "update", "delete":
+		statements, _ := getPath(arguments, []string{name + "s"})
+		if items, _ := statements.(bson.A); helper.IsNotEmpty(items) {
+			arguments, _ = items[0].(bson.D)
+		}
+		command.Filter = documentArgument(arguments, "q")
+		command.Update, _ = getPath(arguments, []string{"u"})
+		command.Upsert = truthy(lookupArgument(arguments, "upsert"))
+		command.Multi = truthy(lookupArgument(arguments, "multi"))
+		command.Limit = intArgument(arguments, "limit")
+	case "findAndModify":
+		command.Filter = documentArgument(arguments, "query")
+		command.Sort = documentArgument(arguments, "sort")
+		command.Projection = documentArgument(arguments, "fields")
+		command.Update, _ = getPath(arguments, []string{"update"})
+		command.Upsert = truthy(lookupArgument(arguments, "upsert"))
+		command.Remove = truthy(lookupArgument(arguments, "remove"))
+		command.New = truthy(lookupArgument(arguments, "new"))
+	default:
+		return command
+	}
+	command.CollectionName = collectionName
+	return command
+}
+
+func lookupArgument(arguments bson.D, name string) any {
+	v, _ := getPath(arguments, []string{name})
+	return v
+}
+
+func documentArgument(arguments bson.D, name string) bson.D {
+	document, _ := lookupArgument(arguments, name).(bson.D)
+	return document
+}
+
+func intArgument(arguments bson.D, name string) int64 {
+	if v := lookupArgument(arguments, name); isNumber(v) {
+		return toInt(v)
+	}
+	return 0
+}
+
+// sameValue compares the values like equal, but with the fields of the documents in any order.
+func sameValue(a, b any) bool {
+	switch x := a.(type) {
+	case bson.D:
+		y, ok := b.(bson.D)
+		if !ok || helper.IsNotEqualTo(len(x), len(y)) {
+			return false
+		}
+		for _, e := range x {
+			v, ok := getPath(y, []string{e.Key})
+			if !ok || !sameValue(e.Value, v) {
+				return false
+			}
+		}
+		return true
+	case bson.A:
+		y, ok := b.(bson.A)
+		if !ok || helper.IsNotEqualTo(len(x), len(y)) {
+			return false
+		}
+		for i := range x {
+			if !sameValue(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return equal(a, b)
+}
+
+// replay executes the command on the collection of its namespace, returning false if the command is ignored.
+func (t *Template) replay(command Command) (Reply, bool, error) {
+	reply := Reply{Command: command}
+	var err error
+	switch command.Name {
+	case "find", "count":
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		var skip, limit *int64
+		if helper.IsGreaterThan(command.Skip, 0) {
+			skip = &command.Skip
+		}
+		if helper.IsNotEqualTo(command.Limit, 0) {
+			limit = &command.Limit
+		}
+		c := t.replayCollection(command, false)
+		reply.Documents, err = c.query(command.Filter, command.Sort, skip, limit, command.Projection)
+		reply.Count = int64(len(reply.Documents))
+		if helper.Equals(command.Name, "count") {
+			reply.Documents = nil
+		}
+	case "aggregate":
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		c := t.replayCollection(command, false)
+		reply.Documents, err = c.query(bson.D{}, nil, nil, nil, nil)
+		for _, stage := range command.Pipeline {
+			if helper.IsNotNil(err) {
+				break
+			}
+			reply.Documents, err = aggregateStage(reply.Documents, stage[0])
+		}
+		reply.Count = int64(len(reply.Documents))
+	case "insert":
+		err = t.write(false, false, false, func() error {
+			c := t.replayCollection(command, true)
+			for _, document := range command.Documents {
+				document, id := withId(copyDocument(document))
+				if err := c.insert(document); helper.IsNotNil(err) {
+					return err
+				}
+				t.record(mongo.OperationTypeInsert, c, id, document, nil)
+				reply.Count++
+			}
+			return nil
+		})
+	case "update", "findAndModify":
+		err = t.write(false, false, false, func() error {
+			return t.replayModify(command, &reply)
+		})
+	case "delete":
+		err = t.write(false, false, false, func() error {
+			c := t.replayCollection(command, false)
+			indexes, err := c.match(command.Filter, nil)
+			if helper.IsNotNil(err) {
+				return err
+			} else if helper.Equals(command.Limit, int64(1)) && helper.IsGreaterThan(len(indexes), 1) {
+				indexes = indexes[:1]
+			}
+			reply.Count = int64(len(t.deleteDocuments(c, indexes)))
+			return nil
+		})
+	default:
+		return reply, false, nil
+	}
+	return reply, true, err
+}
+
+// replayModify executes the update and findAndModify commands.
+func (t *Template) replayModify(command Command, reply *Reply) error {
+	c := t.replayCollection(command, true)
+	if command.Remove {
+		indexes, err := c.match(command.Filter, command.Sort)
+		if helper.IsNotNil(err) || helper.IsEmpty(indexes) {
+			return err
+		}
+		deleted := t.deleteDocuments(c, indexes[:1])
+		reply.Documents, reply.Count = deleted, 1
+		return nil
+	}
+	update, ok := command.Update.(bson.D)
+	if !ok {
+		return fmt.Errorf("%w: update pipeline", ErrNotSupported)
+	}
+	operationType := mongo.OperationTypeUpdate
+	fn := func(document bson.D, insert bool) (bson.D, error) {
+		return applyUpdate(document, update, insert)
+	}
+	if helper.IsEmpty(update) || !isOperatorDocument(update) {
+		operationType = mongo.OperationTypeReplace
+		fn = func(document bson.D, _ bool) (bson.D, error) {
+			return replaceDocument(document, update)
+		}
+	}
+	result, err := t.modifyCollection(c, command.Filter, command.Multi, command.Sort, command.Upsert, operationType,
+		fn)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	reply.Count = result.matchedCount
+	if helper.IsNotNil(result.upsertedId) {
+		reply.Count++
+	}
+	if helper.Equals(command.Name, "findAndModify") {
+		document := result.before
+		if command.New {
+			document = result.after
+		}
+		if helper.IsNotNil(document) {
+			document, err = project(document, command.Projection)
+			reply.Documents = []bson.D{document}
+		}
+	}
+	return err
+}
+
+func (t *Template) replayCollection(command Command, create bool) *collection {
+	c := t.collection(command.DatabaseName, command.CollectionName, create)
+	if helper.IsNil(c) {
+		c = &collection{databaseName: command.DatabaseName, collectionName: command.CollectionName}
+	}
+	return c
+}
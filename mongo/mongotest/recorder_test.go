@@ -0,0 +1,111 @@
+package mongotest
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"testing"
+	"time"
+)
+
+// sendCommand simulates the driver monitoring events of a command.
+func sendCommand(monitor *event.CommandMonitor, requestId int64, databaseName string, command bson.D,
+	failure string) {
+	raw, _ := bson.Marshal(command)
+	ctx := context.TODO()
+	monitor.Started(ctx, &event.CommandStartedEvent{
+		Command:      raw,
+		DatabaseName: databaseName,
+		CommandName:  command[0].Key,
+		RequestID:    requestId,
+	})
+	finished := event.CommandFinishedEvent{CommandName: command[0].Key, RequestID: requestId, Duration: time.Millisecond}
+	if helper.IsNotEmpty(failure) {
+		monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished, Failure: failure})
+	} else {
+		monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+	}
+}
+
+func initTestRecorder() *Recorder {
+	recorder := NewRecorder()
+	monitor := recorder.Monitor()
+	sendCommand(monitor, 1, "test", bson.D{
+		{"insert", "test"},
+		{"documents", bson.A{
+			bson.D{{"name", "Ana"}, {"age", int32(30)}},
+			bson.D{{"name", "Bruno"}, {"age", int32(25)}},
+		}},
+	}, "")
+	sendCommand(monitor, 2, "test", bson.D{
+		{"find", "test"},
+		{"filter", bson.D{{"age", bson.D{{"$gte", int32(20)}}}, {"name", bson.D{{"$ne", "Carla"}}}}},
+		{"sort", bson.D{{"age", int32(1)}}},
+		{"skip", int64(0)},
+		{"limit", int64(10)},
+	}, "")
+	sendCommand(monitor, 3, "test", bson.D{
+		{"update", "test"},
+		{"updates", bson.A{bson.D{
+			{"q", bson.D{{"name", "Bruno"}}},
+			{"u", bson.D{{"$inc", bson.D{{"age", int32(1)}}}}},
+			{"multi", false},
+		}}},
+	}, "")
+	sendCommand(monitor, 4, "test", bson.D{{"delete", "test"}, {"deletes", bson.A{}}}, "not primary")
+	sendCommand(monitor, 5, "test", bson.D{
+		{"aggregate", "test"},
+		{"pipeline", bson.A{
+			bson.D{{"$match", bson.D{}}},
+			bson.D{{"$group", bson.D{{"_id", int32(1)}, {"n", bson.D{{"$sum", int32(1)}}}}}},
+		}},
+	}, "")
+	sendCommand(monitor, 6, "admin", bson.D{{"commitTransaction", int32(1)}}, "")
+	return recorder
+}
+
+func TestRecorder(t *testing.T) {
+	recorder := initTestRecorder()
+	recorder.AssertCount(t, "", "", 6)
+	recorder.AssertCount(t, "find", "test.test", 1)
+	recorder.AssertFilter(t, "find", "test.test", bson.M{"name": bson.M{"$ne": "Carla"}, "age": bson.M{"$gte": 20}})
+	recorder.AssertFilter(t, "update", "test.test", bson.M{"name": "Bruno"})
+	recorder.AssertPipeline(t, "test.test", bson.A{
+		bson.M{"$match": bson.M{}},
+		bson.M{"$group": bson.M{"n": bson.M{"$sum": 1}, "_id": 1}},
+	})
+	find, _ := recorder.Last("find", "test.test")
+	if helper.IsNotEqualTo(find.Limit, int64(10)) || helper.IsNotEqualTo(find.Sort, bson.D{{"age", int32(1)}}) {
+		t.Errorf("Last() find = %+v", find)
+	}
+	deleteCommand, _ := recorder.Last("delete", "")
+	if helper.IsNil(deleteCommand.Err) {
+		t.Error("Last() expected error on failed delete")
+	}
+	recorder.Reset()
+	recorder.AssertCount(t, "", "", 0)
+}
+
+func TestRecorderReplay(t *testing.T) {
+	template := NewTemplate()
+	replies, err := initTestRecorder().Replay(context.TODO(), template)
+	if helper.IsNotNil(err) {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if helper.IsNotEqualTo(len(replies), 4) {
+		t.Fatalf("Replay() replies = %v, want 4", len(replies))
+	}
+	if helper.IsNotEqualTo(replies[1].Count, int64(2)) || helper.IsNotEqualTo(replies[2].Count, int64(1)) {
+		t.Errorf("Replay() find = %+v, update = %+v", replies[1], replies[2])
+	}
+	if result := replies[3].Documents; helper.IsNotEqualTo(len(result), 1) ||
+		helper.IsNotEqualTo(result[0], bson.D{{"_id", int32(1)}, {"n", int32(2)}}) {
+		t.Errorf("Replay() aggregate = %v", result)
+	}
+	var dest testStruct
+	if err = template.FindOne(context.TODO(), bson.M{"name": "Bruno"}, &dest); helper.IsNotNil(err) ||
+		helper.IsNotEqualTo(dest.Age, 26) {
+		t.Errorf("FindOne() dest = %+v, err = %v", dest, err)
+	}
+}
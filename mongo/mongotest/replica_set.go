@@ -0,0 +1,54 @@
+package mongotest
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/internal/mongod"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"testing"
+)
+
+// StartReplicaSet starts a local mongod as a single-node replica set, on a random port and a temporary directory,
+// returning a Template connected to it, so the integration tests can use transactions, Watch and WatchWithHandler.
+// The server is stopped and its files removed by t.Cleanup.
+//
+// The test is skipped if the mongod binary is not found, see option.ReplicaSet.BinaryPath, and fails if the server
+// does not start.
+//
+// The opts parameter can be used to specify options for the replica set (see the option.ReplicaSet documentation).
+func StartReplicaSet(t testing.TB, opts ...*option.ReplicaSet) *mongo.Template {
+	t.Helper()
+	opt := option.MergeReplicaSetByParams(opts)
+	ctx := context.Background()
+	server, err := mongod.Start(ctx, mongod.Config{
+		BinaryPath:     opt.BinaryPath,
+		ReplicaSetName: opt.ReplicaSetName,
+		Dir:            t.TempDir(),
+		StartupTimeout: opt.StartupTimeout,
+		Args:           opt.Args,
+	})
+	if errors.Is(err, mongod.ErrBinaryNotFound) {
+		t.Skip("mongotest:", err)
+	} else if helper.IsNotNil(err) {
+		t.Fatal("mongotest:", err)
+	}
+	t.Cleanup(func() {
+		if err := server.Stop(); helper.IsNotNil(err) {
+			t.Log("mongotest: error stopping mongod:", err)
+		}
+	})
+	template, err := mongo.NewTemplate(ctx, options.Client().ApplyURI(server.URI).SetMonitor(opt.Monitor))
+	if helper.IsNotNil(err) {
+		t.Fatal("mongotest:", err)
+	}
+	if helper.IsNotNil(opt.Global) {
+		template.SetGlobalOption(opt.Global)
+	}
+	t.Cleanup(func() {
+		_ = template.Disconnect(context.Background())
+	})
+	return template
+}
@@ -0,0 +1,26 @@
+package mongotest
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"testing"
+	"time"
+)
+
+func TestStartReplicaSet(t *testing.T) {
+	template := StartReplicaSet(t, option.NewReplicaSet().SetStartupTimeout(time.Minute))
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+	if err := template.StartSession(ctx); helper.IsNotNil(err) {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	document := &testStruct{Name: "Ana"}
+	if err := template.InsertOne(ctx, document); helper.IsNotNil(err) {
+		t.Fatalf("InsertOne() error = %v", err)
+	}
+	count, err := template.CountDocuments(ctx, map[string]any{}, testStruct{})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(count, int64(1)) {
+		t.Errorf("CountDocuments() = %v, err = %v", count, err)
+	}
+}
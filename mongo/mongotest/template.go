@@ -0,0 +1,1040 @@
+package mongotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/internal/util"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Template is an in-memory implementation of mongo.TemplateAPI, so the services that depend on the interface can be
+// unit tested without any database.
+//
+// The filters support the comparison, logical, element and array query operators ($eq, $ne, $gt, $gte, $lt, $lte,
+// $in, $nin, $and, $or, $nor, $not, $exists, $regex, $size, $all and $elemMatch) on dotted paths, the updates support
+// $set, $setOnInsert, $unset, $inc, $mul, $min, $max, $currentDate, $rename, $push, $addToSet and $pull, and the
+// aggregations support the $match, $sort, $skip, $limit, $project, $unset, $count and $group, with the $sum
+// accumulator, stages. The other operators return ErrNotSupported.
+//
+// The writes run on a transaction like the mongo.Template, the changes are discarded if the session is aborted, and
+// the unique indexes created by CreateOneIndex are enforced with the duplicate key error of the server. The change
+// events are delivered to the watch functions when the session is committed, Watch returns ErrNotSupported, since it
+// returns a driver change stream.
+//
+// The cache, the validators, the read and write concerns and the options that depend on the server, e.g. hint,
+// collation and max time, are ignored. The audit and the versioning are not supported, EnableAudit,
+// FindAuditEntries, FindAsOf, ListVersions and RevertTo return ErrNotSupported.
+type Template struct {
+	mutex        sync.Mutex
+	collections  map[string]*collection
+	session      *session
+	global       *option.Global
+	watchers     map[*watcher]struct{}
+	eventCounter uint32
+}
+
+type collection struct {
+	databaseName   string
+	collectionName string
+	documents      []bson.D
+	indexes        []index
+}
+
+// session is the open transaction of the Template, with the collections before it and the change events to
+// publish when it is committed.
+type session struct {
+	snapshot map[string]*collection
+	events   []bson.D
+}
+
+// modifyResult is the result of the update and replace operations.
+type modifyResult struct {
+	matchedCount  int64
+	modifiedCount int64
+	upsertedId    any
+	before        bson.D
+	after         bson.D
+}
+
+// NewTemplate creates an empty in-memory Template.
+func NewTemplate() *Template {
+	return &Template{
+		collections: map[string]*collection{},
+		global:      &option.Global{},
+		watchers:    map[*watcher]struct{}{},
+	}
+}
+
+var _ mongo.TemplateAPI = (*Template)(nil)
+
+// SetGlobalOption sets value for the global options of the Template.
+func (t *Template) SetGlobalOption(opt *option.Global) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if helper.IsNotNil(opt) {
+		t.global = opt
+	} else {
+		t.global = &option.Global{}
+	}
+}
+
+// SetCache does nothing, the documents are always read from memory.
+func (t *Template) SetCache(mongo.Cache, ...*option.Cache) {}
+
+// WatchCacheInvalidation blocks until the ctx is done, since there is no cache to invalidate.
+func (t *Template) WatchCacheInvalidation(ctx context.Context, _ ...*option.WatchWithHandler) error {
+	<-ctx.Done()
+	return nil
+}
+
+// InsertOne inserts the document, see mongo.Template.InsertOne.
+func (t *Template) InsertOne(_ context.Context, document any, opts ...*option.InsertOne) error {
+	opt := option.MergeInsertOneByParams(opts, t.globalOption())
+	return t.write(*opt.ForceRecreateSession, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession,
+		func() error {
+			if helper.IsNotPointer(document) {
+				return mongo.ErrDocumentIsNotPointer
+			} else if helper.IsNotStruct(document) {
+				return mongo.ErrDocumentIsNotStruct
+			} else if helper.IsEmpty(document) {
+				return mongo.ErrDocumentIsEmpty
+			} else if err := t.validateDocument(document, ""); helper.IsNotNil(err) {
+				return err
+			}
+			return t.insertDocument(document)
+		})
+}
+
+// InsertMany inserts the documents, see mongo.Template.InsertMany.
+func (t *Template) InsertMany(_ context.Context, documents any, opts ...*option.InsertMany) error {
+	opt := option.MergeInsertManyByParams(opts, t.globalOption())
+	return t.write(*opt.ForceRecreateSession, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession,
+		func() error {
+			return t.insertMany(documents)
+		})
+}
+
+// DeleteOne deletes the first document matched by the filter, see mongo.Template.DeleteOne.
+func (t *Template) DeleteOne(_ context.Context, filter, ref any, opts ...*option.Delete) (*mongo.DeleteResult,
+	error) {
+	return t.delete(filter, ref, false, opts)
+}
+
+// DeleteOneById deletes the document with the id, see mongo.Template.DeleteOneById.
+func (t *Template) DeleteOneById(_ context.Context, id, ref any, opts ...*option.Delete) (*mongo.DeleteResult,
+	error) {
+	return t.delete(bson.D{{"_id", id}}, ref, false, opts)
+}
+
+// DeleteMany deletes the documents matched by the filter, see mongo.Template.DeleteMany.
+func (t *Template) DeleteMany(_ context.Context, filter, ref any, opts ...*option.Delete) (*mongo.DeleteResult,
+	error) {
+	return t.delete(filter, ref, true, opts)
+}
+
+// UpdateOneById updates the document with the id, see mongo.Template.UpdateOneById.
+func (t *Template) UpdateOneById(_ context.Context, id, update, ref any, opts ...*option.Update) (
+	*mongo.UpdateResult, error) {
+	return t.update(bson.D{{"_id", id}}, update, ref, false, opts)
+}
+
+// UpdateOne updates the first document matched by the filter, see mongo.Template.UpdateOne.
+func (t *Template) UpdateOne(_ context.Context, filter any, update, ref any, opts ...*option.Update) (
+	*mongo.UpdateResult, error) {
+	return t.update(filter, update, ref, false, opts)
+}
+
+// UpdateMany updates the documents matched by the filter, see mongo.Template.UpdateMany.
+func (t *Template) UpdateMany(_ context.Context, filter any, update, ref any, opts ...*option.Update) (
+	*mongo.UpdateResult, error) {
+	return t.update(filter, update, ref, true, opts)
+}
+
+// ReplaceOne replaces the first document matched by the filter, see mongo.Template.ReplaceOne.
+func (t *Template) ReplaceOne(_ context.Context, filter any, update, ref any, opts ...*option.Replace) (
+	*mongo.UpdateResult, error) {
+	return t.replace(filter, update, ref, opts)
+}
+
+// ReplaceOneById replaces the document with the id, see mongo.Template.ReplaceOneById.
+func (t *Template) ReplaceOneById(_ context.Context, id, replacement, ref any, opts ...*option.Replace) (
+	*mongo.UpdateResult, error) {
+	return t.replace(bson.D{{"_id", id}}, replacement, ref, opts)
+}
+
+// FindOneById finds the document with the id, see mongo.Template.FindOneById.
+func (t *Template) FindOneById(ctx context.Context, id, dest any, opts ...*option.FindOneById) error {
+	opt := option.MergeFindOneByIdByParams(opts)
+	return t.FindOne(ctx, bson.D{{"_id", id}}, dest, option.NewFindOne().SetProjection(opt.Projection))
+}
+
+// FindOne finds the first document matched by the filter, see mongo.Template.FindOne.
+func (t *Template) FindOne(_ context.Context, filter, dest any, opts ...*option.FindOne) error {
+	if helper.IsNotPointer(dest) {
+		return mongo.ErrDestIsNotPointer
+	}
+	opt := option.MergeFindOneByParams(opts)
+	limit := int64(1)
+	documents, err := t.query(dest, filter, opt.Sort, opt.Skip, &limit, opt.Projection)
+	if helper.IsNotNil(err) {
+		return err
+	} else if helper.IsEmpty(documents) {
+		return mongo.ErrNoDocuments
+	}
+	return decode(documents[0], dest)
+}
+
+// FindOneAndDeleteById deletes the document with the id, decoding it on the dest parameter, see
+// mongo.Template.FindOneAndDeleteById.
+func (t *Template) FindOneAndDeleteById(ctx context.Context, id, dest any, opts ...*option.FindOneAndDelete) error {
+	return t.FindOneAndDelete(ctx, bson.D{{"_id", id}}, dest, opts...)
+}
+
+// FindOneAndDelete deletes the first document matched by the filter, decoding it on the dest parameter, see
+// mongo.Template.FindOneAndDelete.
+func (t *Template) FindOneAndDelete(_ context.Context, filter, dest any, opts ...*option.FindOneAndDelete) error {
+	if helper.IsNotPointer(dest) {
+		return mongo.ErrDestIsNotPointer
+	}
+	opt := option.MergeFindOneAndDeleteByParams(opts, t.globalOption())
+	return t.write(*opt.ForceRecreateSession, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession,
+		func() error {
+			c, filterDocument, err := t.target(dest, filter, false)
+			if helper.IsNotNil(err) {
+				return err
+			}
+			indexes, err := c.match(filterDocument, opt.Sort)
+			if helper.IsNotNil(err) {
+				return err
+			} else if helper.IsEmpty(indexes) {
+				return mongo.ErrNoDocuments
+			}
+			deleted := t.deleteDocuments(c, indexes[:1])
+			return decodeProjection(deleted[0], opt.Projection, dest)
+		})
+}
+
+// FindOneAndReplaceById replaces the document with the id, decoding it on the dest parameter, see
+// mongo.Template.FindOneAndReplaceById.
+func (t *Template) FindOneAndReplaceById(ctx context.Context, id, replacement, dest any,
+	opts ...*option.FindOneAndReplace) error {
+	return t.FindOneAndReplace(ctx, bson.D{{"_id", id}}, replacement, dest, opts...)
+}
+
+// FindOneAndReplace replaces the first document matched by the filter, decoding it on the dest parameter, see
+// mongo.Template.FindOneAndReplace.
+func (t *Template) FindOneAndReplace(_ context.Context, filter, replacement, dest any,
+	opts ...*option.FindOneAndReplace) error {
+	if helper.IsNotPointer(dest) {
+		return mongo.ErrDestIsNotPointer
+	}
+	opt := option.MergeFindOneAndReplaceByParams(opts, t.globalOption())
+	return t.write(*opt.ForceRecreateSession, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession,
+		func() error {
+			replacementDocument, err := toReplacement(replacement)
+			if helper.IsNotNil(err) {
+				return err
+			}
+			result, err := t.modify(dest, filter, false, opt.Sort, isTrue(opt.Upsert), mongo.OperationTypeReplace,
+				func(document bson.D, _ bool) (bson.D, error) {
+					return replaceDocument(document, replacementDocument)
+				})
+			if helper.IsNotNil(err) {
+				return err
+			}
+			return decodeModifyResult(result, opt.ReturnDocument, opt.Projection, dest)
+		})
+}
+
+// FindOneAndUpdateById updates the document with the id, decoding it on the dest parameter, see
+// mongo.Template.FindOneAndUpdateById.
+func (t *Template) FindOneAndUpdateById(ctx context.Context, id, update, dest any,
+	opts ...*option.FindOneAndUpdate) error {
+	return t.FindOneAndUpdate(ctx, bson.D{{"_id", id}}, update, dest, opts...)
+}
+
+// FindOneAndUpdate updates the first document matched by the filter, decoding it on the dest parameter, see
+// mongo.Template.FindOneAndUpdate.
+func (t *Template) FindOneAndUpdate(_ context.Context, filter, update, dest any,
+	opts ...*option.FindOneAndUpdate) error {
+	if helper.IsNotPointer(dest) {
+		return mongo.ErrDestIsNotPointer
+	}
+	opt := option.MergeFindOneAndUpdateByParams(opts, t.globalOption())
+	return t.write(*opt.ForceRecreateSession, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession,
+		func() error {
+			updateDocument, err := toDocument(update)
+			if helper.IsNotNil(err) {
+				return err
+			}
+			result, err := t.modify(dest, filter, false, opt.Sort, isTrue(opt.Upsert), mongo.OperationTypeUpdate,
+				func(document bson.D, insert bool) (bson.D, error) {
+					return applyUpdate(document, updateDocument, insert)
+				})
+			if helper.IsNotNil(err) {
+				return err
+			}
+			return decodeModifyResult(result, opt.ReturnDocument, opt.Projection, dest)
+		})
+}
+
+// Find finds the documents matched by the filter, see mongo.Template.Find.
+func (t *Template) Find(_ context.Context, filter, dest any, opts ...*option.Find) error {
+	if helper.IsNotPointer(dest) {
+		return mongo.ErrDestIsNotPointer
+	}
+	opt := option.MergeFindByParams(opts)
+	documents, err := t.query(dest, filter, opt.Sort, opt.Skip, opt.Limit, opt.Projection)
+	if helper.IsNotNil(err) || helper.IsEmpty(documents) {
+		return err
+	}
+	return decodeAll(documents, dest)
+}
+
+// FindAll finds all the documents of the collection, see mongo.Template.FindAll.
+func (t *Template) FindAll(ctx context.Context, dest any, opts ...*option.Find) error {
+	return t.Find(ctx, bson.D{}, dest, opts...)
+}
+
+// FindPageable finds the page of the documents matched by the filter, see mongo.Template.FindPageable.
+func (t *Template) FindPageable(_ context.Context, filter any, input mongo.PageInput, opts ...*option.FindPageable) (
+	*mongo.PageResult, error) {
+	if helper.IsNotStruct(input.Ref) {
+		return nil, errors.New("mongo: input.Ref need to be structure")
+	}
+	opt := option.MergeFindPageableByParams(opts)
+	skip := input.Page * input.PageSize
+	documents, err := t.query(input.Ref, filter, input.Sort, &skip, &input.PageSize, opt.Projection)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	dest := reflect.New(reflect.SliceOf(reflect.TypeOf(input.Ref)))
+	err = decodeAll(documents, dest.Interface())
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	countTotal, err := t.CountDocuments(context.Background(), filter, input.Ref)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return mongo.NewPageResult(input, dest.Elem().Interface(), countTotal), nil
+}
+
+// Exists returns true if any document matches the filter, see mongo.Template.Exists.
+func (t *Template) Exists(ctx context.Context, filter, ref any, _ ...*option.Exists) (bool, error) {
+	limit := int64(1)
+	count, err := t.CountDocuments(ctx, filter, ref, &option.Count{Limit: &limit})
+	return helper.IsGreaterThan(count, 0), err
+}
+
+// ExistsById returns true if the document with the id exists, see mongo.Template.ExistsById.
+func (t *Template) ExistsById(ctx context.Context, id, ref any, opts ...*option.Exists) (bool, error) {
+	return t.Exists(ctx, bson.D{{"_id", id}}, ref, opts...)
+}
+
+// Aggregate runs the pipeline on the collection of the dest parameter, see mongo.Template.Aggregate and the
+// supported stages on the Template documentation.
+func (t *Template) Aggregate(_ context.Context, pipeline any, dest any, _ ...*option.Aggregate) error {
+	if helper.IsNotPointer(dest) {
+		return mongo.ErrDestIsNotPointer
+	}
+	documents, err := t.aggregate(dest, pipeline)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	return decodeAll(documents, dest)
+}
+
+// CountDocuments counts the documents matched by the filter, see mongo.Template.CountDocuments.
+func (t *Template) CountDocuments(_ context.Context, filter, ref any, opts ...*option.Count) (int64, error) {
+	opt := option.MergeCountByParams(opts)
+	documents, err := t.query(ref, filter, nil, opt.Skip, opt.Limit, nil)
+	return int64(len(documents)), err
+}
+
+// EstimatedDocumentCount returns the number of documents of the collection, see
+// mongo.Template.EstimatedDocumentCount.
+func (t *Template) EstimatedDocumentCount(ctx context.Context, ref any, _ ...*option.EstimatedDocumentCount) (int64,
+	error) {
+	return t.CountDocuments(ctx, bson.D{}, ref)
+}
+
+// Distinct finds the unique values of the field on the documents matched by the filter, see mongo.Template.Distinct.
+func (t *Template) Distinct(_ context.Context, fieldName string, filter, dest, ref any, _ ...*option.Distinct) error {
+	if helper.IsNotPointer(dest) {
+		return mongo.ErrDestIsNotPointer
+	}
+	documents, err := t.query(ref, filter, nil, nil, nil, nil)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	result := bson.A{}
+	for _, document := range documents {
+		for _, value := range lookup(document, splitPath(fieldName)) {
+			values := []any{value}
+			if array, ok := value.(bson.A); ok {
+				values = array
+			}
+			for _, v := range values {
+				if !matchEquality([]any{result}, v) {
+					result = append(result, v)
+				}
+			}
+		}
+	}
+	return helper.ConvertToDest(result, dest)
+}
+
+// DropCollection removes the collection of the ref parameter with its indexes.
+func (t *Template) DropCollection(_ context.Context, ref any) error {
+	databaseName, collectionName, err := getNamesByAny(ref)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.collections, namespace(databaseName, collectionName))
+	t.publish([]bson.D{t.newEvent(mongo.OperationTypeDrop, databaseName, collectionName, nil, nil, nil)})
+	return nil
+}
+
+// DropDatabase removes the collections of the database of the ref parameter.
+func (t *Template) DropDatabase(_ context.Context, ref any) error {
+	databaseName, _, err := getNamesByAny(ref)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for key, c := range t.collections {
+		if helper.Equals(c.databaseName, databaseName) {
+			delete(t.collections, key)
+		}
+	}
+	t.publish([]bson.D{t.newEvent(mongo.OperationTypeDropDatabase, databaseName, "", nil, nil, nil)})
+	return nil
+}
+
+// ApplyValidator only checks the ref parameter, the validators are not applied on memory.
+func (t *Template) ApplyValidator(_ context.Context, ref any, _ mongo.ValidationLevel, _ mongo.ValidationAction) error {
+	_, _, err := getNamesByAny(ref)
+	return err
+}
+
+// EnableAudit returns ErrNotSupported, the writes are not audited on memory.
+func (t *Template) EnableAudit(context.Context, ...*option.Audit) error {
+	return ErrNotSupported
+}
+
+// DisableAudit does nothing, the audit cannot be enabled.
+func (t *Template) DisableAudit() {}
+
+// FindAuditEntries returns ErrNotSupported, the writes are not audited on memory.
+func (t *Template) FindAuditEntries(context.Context, any, any) ([]mongo.AuditEntry, error) {
+	return nil, ErrNotSupported
+}
+
+// FindAsOf returns ErrNotSupported, the versions of the documents are not kept on memory.
+func (t *Template) FindAsOf(context.Context, any, time.Time, any) error {
+	return ErrNotSupported
+}
+
+// ListVersions returns ErrNotSupported, the versions of the documents are not kept on memory.
+func (t *Template) ListVersions(context.Context, any, any) ([]mongo.DocumentVersion, error) {
+	return nil, ErrNotSupported
+}
+
+// RevertTo returns ErrNotSupported, the versions of the documents are not kept on memory.
+func (t *Template) RevertTo(context.Context, any, int64, any, ...*option.Replace) (*mongo.UpdateResult, error) {
+	return nil, ErrNotSupported
+}
+
+// StartSession starts a new session, aborting the open one, the next writes run on it until it is closed.
+func (t *Template) StartSession(context.Context) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.startSession(true)
+	return nil
+}
+
+// CloseSession closes the session, if the abort parameter is false the changes are committed, otherwise they are
+// discarded.
+func (t *Template) CloseSession(_ context.Context, abort bool) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.closeSession(abort)
+}
+
+// CommitTransaction commits the changes of the session and closes it.
+func (t *Template) CommitTransaction(ctx context.Context) error {
+	return t.CloseSession(ctx, false)
+}
+
+// AbortTransaction discards the changes of the session and closes it.
+func (t *Template) AbortTransaction(ctx context.Context) error {
+	return t.CloseSession(ctx, true)
+}
+
+// Disconnect does nothing, there is no connection.
+func (t *Template) Disconnect(context.Context) error {
+	return nil
+}
+
+// SimpleDisconnect does nothing, there is no connection.
+func (t *Template) SimpleDisconnect(context.Context) {}
+
+func (t *Template) globalOption() *option.Global {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.global
+}
+
+// write runs the fn parameter on the session, like the writes of mongo.Template, starting it if there is none and
+// closing it after, unless disableAutoClose is true.
+func (t *Template) write(forceRecreate, disableAutoClose, disableAutoRollback bool, fn func() error) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.startSession(forceRecreate)
+	err := fn()
+	if !disableAutoClose {
+		var validationErr *mongo.ValidationError
+		abort := helper.IsNotNil(err) && (!disableAutoRollback || errors.As(err, &validationErr))
+		_ = t.closeSession(abort)
+	}
+	return err
+}
+
+func (t *Template) startSession(forceRecreate bool) {
+	if helper.IsNotNil(t.session) && !forceRecreate {
+		return
+	} else if helper.IsNotNil(t.session) {
+		_ = t.closeSession(true)
+	}
+	snapshot := make(map[string]*collection, len(t.collections))
+	for key, c := range t.collections {
+		snapshot[key] = c.clone()
+	}
+	t.session = &session{snapshot: snapshot}
+}
+
+func (t *Template) closeSession(abort bool) error {
+	if helper.IsNil(t.session) {
+		return mongo.ErrNoOpenSession
+	}
+	current := t.session
+	t.session = nil
+	if abort {
+		t.collections = current.snapshot
+	} else {
+		t.publish(current.events)
+	}
+	return nil
+}
+
+func (t *Template) collection(databaseName, collectionName string, create bool) *collection {
+	key := namespace(databaseName, collectionName)
+	c, ok := t.collections[key]
+	if !ok && create {
+		c = &collection{databaseName: databaseName, collectionName: collectionName}
+		t.collections[key] = c
+	}
+	return c
+}
+
+// target returns the collection of the ref parameter, created if the create parameter is true, with the filter
+// converted to bson.D.
+func (t *Template) target(ref, filter any, create bool) (*collection, bson.D, error) {
+	databaseName, collectionName, err := getNamesByAny(ref)
+	if helper.IsNotNil(err) {
+		return nil, nil, err
+	}
+	filterDocument, err := toDocument(filter)
+	if helper.IsNotNil(err) {
+		return nil, nil, err
+	}
+	c := t.collection(databaseName, collectionName, create)
+	if helper.IsNil(c) {
+		c = &collection{databaseName: databaseName, collectionName: collectionName}
+	}
+	return c, filterDocument, nil
+}
+
+// query returns the documents of the collection of the ref parameter matched by the filter.
+func (t *Template) query(ref, filter, sortSpecification any, skip, limit *int64, projection any) ([]bson.D, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	c, filterDocument, err := t.target(ref, filter, false)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return c.query(filterDocument, sortSpecification, skip, limit, projection)
+}
+
+// query returns the documents of the collection matched by the filter.
+func (c *collection) query(filterDocument bson.D, sortSpecification any, skip, limit *int64,
+	projection any) ([]bson.D, error) {
+	indexes, err := c.match(filterDocument, sortSpecification)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	if helper.IsNotNil(skip) && helper.IsGreaterThan(*skip, 0) {
+		indexes = indexes[min(int(*skip), len(indexes)):]
+	}
+	if helper.IsNotNil(limit) && helper.IsNotEqualTo(*limit, 0) {
+		n := *limit
+		if helper.IsLessThan(n, 0) {
+			n = -n
+		}
+		indexes = indexes[:min(int(n), len(indexes))]
+	}
+	result := make([]bson.D, 0, len(indexes))
+	for _, i := range indexes {
+		document, err := project(c.documents[i], projection)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		result = append(result, document)
+	}
+	return result, nil
+}
+
+// aggregate returns the documents of the collection of the ref parameter transformed by the pipeline.
+func (t *Template) aggregate(ref, pipeline any) ([]bson.D, error) {
+	stages, err := toPipeline(pipeline)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	documents, err := t.query(ref, bson.D{}, nil, nil, nil, nil)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	for _, stage := range stages {
+		documents, err = aggregateStage(documents, stage[0])
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+	}
+	return documents, nil
+}
+
+func (t *Template) insertDocument(document any) error {
+	databaseName, collectionName, err := getNamesByAny(document)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	b, err := toDocument(document)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	b, id := withId(b)
+	c := t.collection(databaseName, collectionName, true)
+	err = c.insert(b)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	t.record(mongo.OperationTypeInsert, c, id, b, nil)
+	util.SetInsertedIdOnDocument(id, document)
+	return nil
+}
+
+func (t *Template) insertMany(a any) error {
+	if helper.IsNotSlice(a) {
+		return errors.New("mongo: document on insert many needs be a slice")
+	} else if helper.IsEmpty(a) {
+		return mongo.ErrDocumentsIsEmpty
+	}
+	documents := reflect.ValueOf(a)
+	var fields []mongo.FieldValidationError
+	for i := 0; helper.IsLessThan(i, documents.Len()); i++ {
+		var validationErr *mongo.ValidationError
+		err := t.validateDocument(documents.Index(i).Interface(), "["+strconv.Itoa(i)+"]")
+		if errors.As(err, &validationErr) {
+			fields = append(fields, validationErr.Fields...)
+		} else if helper.IsNotNil(err) {
+			return err
+		}
+	}
+	if helper.IsNotEmpty(fields) {
+		return &mongo.ValidationError{Fields: fields}
+	}
+	var errs []string
+	for i := 0; helper.IsLessThan(i, documents.Len()); i++ {
+		document := documents.Index(i).Interface()
+		if helper.IsNotPointer(document) {
+			errs = append(errs, helper.Sprintln(mongo.ErrDocumentIsNotPointer.Error(), "index:", i))
+		} else if helper.IsNotStruct(document) {
+			errs = append(errs, helper.Sprintln(mongo.ErrDocumentIsNotStruct.Error(), "index:", i))
+		} else if helper.IsEmpty(document) {
+			errs = append(errs, helper.Sprintln(mongo.ErrDocumentIsEmpty.Error(), "index:", i))
+		} else if err := t.insertDocument(document); helper.IsNotNil(err) {
+			errs = append(errs, helper.Sprintln(err.Error(), "index:", i))
+		}
+	}
+	if helper.IsNotEmpty(errs) {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func (t *Template) delete(filter, ref any, many bool, opts []*option.Delete) (*mongo.DeleteResult, error) {
+	opt := option.MergeDeleteByParams(opts, t.globalOption())
+	var result *mongo.DeleteResult
+	err := t.write(*opt.ForceRecreateSession, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession,
+		func() error {
+			c, filterDocument, err := t.target(ref, filter, false)
+			if helper.IsNotNil(err) {
+				return err
+			}
+			indexes, err := c.match(filterDocument, nil)
+			if helper.IsNotNil(err) {
+				return err
+			} else if !many && helper.IsGreaterThan(len(indexes), 1) {
+				indexes = indexes[:1]
+			}
+			result = &mongo.DeleteResult{DeletedCount: int64(len(t.deleteDocuments(c, indexes)))}
+			return nil
+		})
+	return result, err
+}
+
+// deleteDocuments removes the documents of the indexes parameter, in ascending order, returning them.
+func (t *Template) deleteDocuments(c *collection, indexes []int) []bson.D {
+	removed := make(map[int]bool, len(indexes))
+	result := make([]bson.D, 0, len(indexes))
+	for _, i := range indexes {
+		removed[i] = true
+		result = append(result, c.documents[i])
+		id, _ := getPath(c.documents[i], []string{"_id"})
+		t.record(mongo.OperationTypeDelete, c, id, nil, nil)
+	}
+	documents := make([]bson.D, 0, len(c.documents)-len(indexes))
+	for i, document := range c.documents {
+		if !removed[i] {
+			documents = append(documents, document)
+		}
+	}
+	c.documents = documents
+	return result
+}
+
+func (t *Template) update(filter, update, ref any, many bool, opts []*option.Update) (*mongo.UpdateResult, error) {
+	opt := option.MergeUpdateByParams(opts, t.globalOption())
+	var result *mongo.UpdateResult
+	err := t.write(*opt.ForceRecreateSession, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession,
+		func() error {
+			updateDocument, err := toDocument(update)
+			if helper.IsNotNil(err) {
+				return err
+			}
+			modified, err := t.modify(ref, filter, many, nil, isTrue(opt.Upsert), mongo.OperationTypeUpdate,
+				func(document bson.D, insert bool) (bson.D, error) {
+					return applyUpdate(document, updateDocument, insert)
+				})
+			result = modified.updateResult()
+			return err
+		})
+	return result, err
+}
+
+func (t *Template) replace(filter, replacement, ref any, opts []*option.Replace) (*mongo.UpdateResult, error) {
+	opt := option.MergeReplaceByParams(opts, t.globalOption())
+	var result *mongo.UpdateResult
+	err := t.write(*opt.ForceRecreateSession, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession,
+		func() error {
+			replacementDocument, err := toReplacement(replacement)
+			if helper.IsNotNil(err) {
+				return err
+			}
+			modified, err := t.modify(ref, filter, false, nil, isTrue(opt.Upsert), mongo.OperationTypeReplace,
+				func(document bson.D, _ bool) (bson.D, error) {
+					return replaceDocument(document, replacementDocument)
+				})
+			result = modified.updateResult()
+			return err
+		})
+	return result, err
+}
+
+// modify applies the fn parameter on the documents matched by the filter, or on the document built from the filter
+// equalities if there is none and the upsert parameter is true.
+func (t *Template) modify(ref, filter any, many bool, sortSpecification any, upsert bool,
+	operationType mongo.OperationType, fn func(document bson.D, insert bool) (bson.D, error)) (*modifyResult, error) {
+	c, filterDocument, err := t.target(ref, filter, true)
+	if helper.IsNotNil(err) {
+		return &modifyResult{}, err
+	}
+	return t.modifyCollection(c, filterDocument, many, sortSpecification, upsert, operationType, fn)
+}
+
+// modifyCollection applies the fn parameter on the documents of the collection, see modify.
+func (t *Template) modifyCollection(c *collection, filterDocument bson.D, many bool, sortSpecification any,
+	upsert bool, operationType mongo.OperationType, fn func(document bson.D, insert bool) (bson.D, error)) (
+	*modifyResult, error) {
+	result := &modifyResult{}
+	indexes, err := c.match(filterDocument, sortSpecification)
+	if helper.IsNotNil(err) {
+		return result, err
+	} else if !many && helper.IsGreaterThan(len(indexes), 1) {
+		indexes = indexes[:1]
+	}
+	for _, i := range indexes {
+		before := c.documents[i]
+		after, err := fn(before, false)
+		if helper.IsNotNil(err) {
+			return result, err
+		}
+		if helper.Equals(result.matchedCount, int64(0)) {
+			result.before, result.after = before, after
+		}
+		result.matchedCount++
+		if helper.Equals(compareDocuments(before, after), 0) {
+			continue
+		} else if err = c.checkUnique(after, i); helper.IsNotNil(err) {
+			return result, err
+		}
+		c.documents[i] = after
+		result.modifiedCount++
+		id, _ := getPath(after, []string{"_id"})
+		if helper.Equals(operationType, mongo.OperationTypeUpdate) {
+			t.record(operationType, c, id, after, updateDescription(before, after))
+		} else {
+			t.record(operationType, c, id, after, nil)
+		}
+	}
+	if helper.IsNotEmpty(indexes) || !upsert {
+		return result, nil
+	}
+	document, err := upsertDocument(filterDocument)
+	if helper.IsNotNil(err) {
+		return result, err
+	}
+	document, err = fn(document, true)
+	if helper.IsNotNil(err) {
+		return result, err
+	}
+	document, id := withId(document)
+	if err = c.insert(document); helper.IsNotNil(err) {
+		return result, err
+	}
+	result.upsertedId = id
+	result.after = document
+	t.record(mongo.OperationTypeInsert, c, id, document, nil)
+	return result, nil
+}
+
+func (t *Template) validateDocument(document any, pathPrefix string) error {
+	if !t.global.ValidateDocument || helper.IsNotStruct(document) {
+		return nil
+	}
+	err := helper.Validate().Struct(document)
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+	fields := make([]mongo.FieldValidationError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		path := fieldErr.Namespace()
+		if i := strings.Index(path, "."); helper.IsGreaterThanOrEqual(i, 0) {
+			path = path[i+1:]
+		}
+		if helper.IsNotEmpty(pathPrefix) {
+			path = pathPrefix + "." + path
+		}
+		fields = append(fields, mongo.FieldValidationError{
+			Path:  path,
+			Rule:  fieldErr.Tag(),
+			Param: fieldErr.Param(),
+			Value: fieldErr.Value(),
+		})
+	}
+	return &mongo.ValidationError{Fields: fields}
+}
+
+func (c *collection) clone() *collection {
+	return &collection{
+		databaseName:   c.databaseName,
+		collectionName: c.collectionName,
+		documents:      append([]bson.D(nil), c.documents...),
+		indexes:        append([]index(nil), c.indexes...),
+	}
+}
+
+// match returns the positions of the documents matched by the filter, ordered by the sort specification.
+func (c *collection) match(filter bson.D, sortSpecification any) ([]int, error) {
+	var result []int
+	for i, document := range c.documents {
+		ok, err := matches(document, filter)
+		if helper.IsNotNil(err) {
+			return nil, err
+		} else if ok {
+			result = append(result, i)
+		}
+	}
+	less, err := newSortLess(sortSpecification)
+	if helper.IsNotNil(err) {
+		return nil, err
+	} else if less != nil {
+		sort.SliceStable(result, func(i, j int) bool {
+			return less(c.documents[result[i]], c.documents[result[j]])
+		})
+	}
+	return result, nil
+}
+
+func (c *collection) insert(document bson.D) error {
+	if err := c.checkUnique(document, -1); helper.IsNotNil(err) {
+		return err
+	}
+	c.documents = append(c.documents, document)
+	return nil
+}
+
+func (m *modifyResult) updateResult() *mongo.UpdateResult {
+	result := &mongo.UpdateResult{
+		MatchedCount:  m.matchedCount,
+		ModifiedCount: m.modifiedCount,
+		UpsertedID:    m.upsertedId,
+	}
+	if helper.IsNotNil(m.upsertedId) {
+		result.UpsertedCount = 1
+	}
+	return result
+}
+
+// withId returns the document with the _id field first, generating an ObjectID if it does not have one.
+func withId(document bson.D) (bson.D, any) {
+	for i, e := range document {
+		if helper.Equals(e.Key, "_id") {
+			result := append(bson.D{e}, document[:i]...)
+			return append(result, document[i+1:]...), e.Value
+		}
+	}
+	id := primitive.NewObjectID()
+	return append(bson.D{{"_id", id}}, document...), id
+}
+
+func toReplacement(replacement any) (bson.D, error) {
+	document, err := toDocument(replacement)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	for _, e := range document {
+		if strings.HasPrefix(e.Key, "$") {
+			return nil, fmt.Errorf("mongotest: replacement document cannot contain operator %s", e.Key)
+		}
+	}
+	return document, nil
+}
+
+// replaceDocument returns the replacement with the _id of the document, which cannot be changed.
+func replaceDocument(document, replacement bson.D) (bson.D, error) {
+	id, hasId := getPath(document, []string{"_id"})
+	result := bson.D{}
+	if hasId {
+		result = append(result, bson.E{Key: "_id", Value: id})
+	}
+	for _, e := range replacement {
+		if helper.IsNotEqualTo(e.Key, "_id") {
+			result = append(result, bson.E{Key: e.Key, Value: copyValue(e.Value)})
+		} else if hasId && !equal(e.Value, id) {
+			return nil, ErrImmutableId
+		} else if !hasId {
+			result = append(bson.D{e}, result...)
+		}
+	}
+	return result, nil
+}
+
+func decodeModifyResult(result *modifyResult, returnDocument *option.ReturnDocument, projection, dest any) error {
+	document := result.before
+	if helper.IsNotNil(returnDocument) && helper.Equals(*returnDocument, option.ReturnDocumentAfter) {
+		document = result.after
+	}
+	if helper.IsNil(document) {
+		return mongo.ErrNoDocuments
+	}
+	return decodeProjection(document, projection, dest)
+}
+
+func decodeProjection(document bson.D, projection, dest any) error {
+	document, err := project(document, projection)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	return decode(document, dest)
+}
+
+func decode(document bson.D, dest any) error {
+	b, err := bson.Marshal(document)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	return bson.Unmarshal(b, dest)
+}
+
+// decodeAll decodes the documents on the dest parameter, a pointer to a slice.
+func decodeAll(documents []bson.D, dest any) error {
+	value := reflect.ValueOf(dest).Elem()
+	if helper.IsNotEqualTo(value.Kind(), reflect.Slice) {
+		return errors.New("mongotest: dest param must be a pointer to a slice")
+	}
+	result := reflect.MakeSlice(value.Type(), 0, len(documents))
+	for _, document := range documents {
+		item := reflect.New(value.Type().Elem())
+		if err := decode(document, item.Interface()); helper.IsNotNil(err) {
+			return err
+		}
+		result = reflect.Append(result, item.Elem())
+	}
+	value.Set(result)
+	return nil
+}
+
+// getNamesByAny returns the database and collection names of the struct or slice of struct, like the mongo.Template.
+func getNamesByAny(a any) (string, string, error) {
+	var databaseName string
+	var collectionName string
+	v := reflect.ValueOf(a)
+	if helper.IsPointer(a) || helper.IsInterface(a) {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if helper.IsNotEqualTo(v.Type().Elem().Kind(), reflect.Struct) {
+			return "", "", mongo.ErrRefDocument
+		}
+		databaseName = util.GetDatabaseNameBySlice(a)
+		collectionName = util.GetCollectionNameBySlice(a)
+	case reflect.Struct:
+		databaseName = util.GetDatabaseNameByStruct(a)
+		collectionName = util.GetCollectionNameByStruct(a)
+	default:
+		return "", "", mongo.ErrRefDocument
+	}
+	if helper.IsEmpty(databaseName) {
+		return "", "", mongo.ErrDatabaseNotConfigured
+	} else if helper.IsEmpty(collectionName) {
+		return "", "", mongo.ErrCollectionNotConfigured
+	}
+	return databaseName, collectionName, nil
+}
+
+func namespace(databaseName, collectionName string) string {
+	return databaseName + "." + collectionName
+}
+
+func isTrue(b *bool) bool {
+	return helper.IsNotNil(b) && *b
+}
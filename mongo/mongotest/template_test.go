@@ -0,0 +1,269 @@
+package mongotest
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	driver "go.mongodb.org/mongo-driver/mongo"
+	"testing"
+	"time"
+)
+
+type testStruct struct {
+	Id        primitive.ObjectID `bson:"_id,omitempty" database:"test" collection:"test"`
+	Name      string             `bson:"name,omitempty"`
+	Age       int                `bson:"age,omitempty"`
+	Tags      []string           `bson:"tags,omitempty"`
+	Address   testAddress        `bson:"address,omitempty"`
+	CreatedAt time.Time          `bson:"createdAt,omitempty"`
+}
+
+type testCount struct {
+	Total int32 `bson:"total" database:"test" collection:"test"`
+}
+
+type testAddress struct {
+	City string `bson:"city,omitempty"`
+}
+
+func initTestTemplate(t *testing.T) (*Template, []*testStruct) {
+	template := NewTemplate()
+	documents := []*testStruct{
+		{Name: "Ana", Age: 30, Tags: []string{"a", "b"}, Address: testAddress{City: "Rio"}},
+		{Name: "Bruno", Age: 25, Tags: []string{"b"}, Address: testAddress{City: "Lisbon"}},
+		{Name: "Carla", Age: 41, Address: testAddress{City: "Rio"}},
+	}
+	if err := template.InsertMany(context.TODO(), documents); helper.IsNotNil(err) {
+		t.Fatalf("InsertMany() error = %v", err)
+	}
+	return template, documents
+}
+
+func TestTemplateFind(t *testing.T) {
+	ctx := context.TODO()
+	template, documents := initTestTemplate(t)
+	if documents[0].Id.IsZero() {
+		t.Fatal("InsertMany() expected generated _id on document")
+	}
+	tests := []struct {
+		name   string
+		filter any
+		want   []string
+	}{
+		{"equality", bson.M{"address.city": "Rio"}, []string{"Ana", "Carla"}},
+		{"comparison", bson.M{"age": bson.M{"$gte": 30}}, []string{"Ana", "Carla"}},
+		{"array element", bson.M{"tags": "b"}, []string{"Ana", "Bruno"}},
+		{"in", bson.M{"name": bson.M{"$in": bson.A{"Bruno", "Carla"}}}, []string{"Bruno", "Carla"}},
+		{"exists", bson.M{"tags": bson.M{"$exists": false}}, []string{"Carla"}},
+		{"or", bson.M{"$or": bson.A{bson.M{"age": 25}, bson.M{"name": primitive.Regex{Pattern: "^c", Options: "i"}}}},
+			[]string{"Bruno", "Carla"}},
+		{"not", bson.M{"age": bson.M{"$not": bson.M{"$lt": 30}}}, []string{"Ana", "Carla"}},
+		{"size", bson.M{"tags": bson.M{"$size": 2}}, []string{"Ana"}},
+		{"all", bson.M{"tags": bson.M{"$all": bson.A{"a", "b"}}}, []string{"Ana"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dest []testStruct
+			err := template.Find(ctx, tt.filter, &dest, option.NewFind().SetSort(bson.M{"name": 1}))
+			if helper.IsNotNil(err) {
+				t.Fatalf("Find() error = %v", err)
+			}
+			var names []string
+			for _, document := range dest {
+				names = append(names, document.Name)
+			}
+			if helper.IsNotEqualTo(names, tt.want) {
+				t.Errorf("Find() names = %v, want %v", names, tt.want)
+			}
+		})
+	}
+	var dest testStruct
+	err := template.FindOne(ctx, bson.M{}, &dest, option.NewFindOne().SetSort(bson.M{"age": -1}).
+		SetProjection(bson.M{"name": 1}))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(dest.Name, "Carla") || helper.IsNotEqualTo(dest.Age, 0) {
+		t.Errorf("FindOne() dest = %+v, err = %v", dest, err)
+	}
+	err = template.FindOneById(ctx, primitive.NewObjectID(), &dest)
+	if helper.IsNotEqualTo(err, mongo.ErrNoDocuments) {
+		t.Errorf("FindOneById() error = %v, want %v", err, mongo.ErrNoDocuments)
+	}
+	page, err := template.FindPageable(ctx, bson.M{}, mongo.PageInput{
+		Page:     1,
+		PageSize: 2,
+		Ref:      testStruct{},
+		Sort:     bson.M{"age": 1},
+	})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(page.TotalElements, int64(3)) ||
+		helper.IsNotEqualTo(len(page.Content), 1) {
+		t.Errorf("FindPageable() page = %+v, err = %v", page, err)
+	}
+	var result []testCount
+	err = template.Aggregate(ctx, mongo.Pipeline{
+		{{"$match", bson.M{"address.city": "Rio"}}},
+		{{"$count", "total"}},
+	}, &result)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(result), 1) || helper.IsNotEqualTo(result[0].Total, int32(2)) {
+		t.Errorf("Aggregate() result = %v, err = %v", result, err)
+	}
+}
+
+func TestTemplateUpdate(t *testing.T) {
+	ctx := context.TODO()
+	template, documents := initTestTemplate(t)
+	result, err := template.UpdateOneById(ctx, documents[0].Id, bson.M{
+		"$set":   bson.M{"address.city": "Recife"},
+		"$inc":   bson.M{"age": 1},
+		"$push":  bson.M{"tags": bson.M{"$each": bson.A{"c", "d"}}},
+		"$unset": bson.M{"name": ""},
+	}, testStruct{})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(result.ModifiedCount, int64(1)) {
+		t.Fatalf("UpdateOneById() result = %+v, err = %v", result, err)
+	}
+	var dest testStruct
+	_ = template.FindOneById(ctx, documents[0].Id, &dest)
+	if helper.IsNotEqualTo(dest.Age, 31) || helper.IsNotEmpty(dest.Name) ||
+		helper.IsNotEqualTo(dest.Address.City, "Recife") || helper.IsNotEqualTo(len(dest.Tags), 4) {
+		t.Errorf("UpdateOneById() document = %+v", dest)
+	}
+	result, err = template.UpdateMany(ctx, bson.M{"age": bson.M{"$lt": 100}}, bson.M{"$set": bson.M{"age": 1}},
+		testStruct{})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(result.MatchedCount, int64(3)) {
+		t.Errorf("UpdateMany() result = %+v, err = %v", result, err)
+	}
+	result, err = template.UpdateOne(ctx, bson.M{"name": "Daniel"}, bson.M{"$set": bson.M{"age": 18}}, testStruct{},
+		option.NewUpdate().SetUpsert(true))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(result.UpsertedCount, int64(1)) {
+		t.Errorf("UpdateOne() upsert result = %+v, err = %v", result, err)
+	}
+	err = template.FindOneAndUpdate(ctx, bson.M{"name": "Daniel"}, bson.M{"$inc": bson.M{"age": 2}}, &dest,
+		option.NewFindOneAndUpdate().SetReturnDocument(option.ReturnDocumentAfter))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(dest.Age, 20) {
+		t.Errorf("FindOneAndUpdate() dest = %+v, err = %v", dest, err)
+	}
+	_, err = template.UpdateOneById(ctx, documents[1].Id, bson.M{"$set": bson.M{"_id": primitive.NewObjectID()}},
+		testStruct{})
+	if helper.IsNotEqualTo(err, ErrImmutableId) {
+		t.Errorf("UpdateOneById() error = %v, want %v", err, ErrImmutableId)
+	}
+	deleteResult, err := template.DeleteMany(ctx, bson.M{"address.city": "Rio"}, testStruct{})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(deleteResult.DeletedCount, int64(1)) {
+		t.Errorf("DeleteMany() result = %+v, err = %v", deleteResult, err)
+	}
+}
+
+func TestTemplateUniqueIndexAndSession(t *testing.T) {
+	ctx := context.TODO()
+	template, _ := initTestTemplate(t)
+	_, err := template.CreateOneIndex(ctx, mongo.IndexInput{
+		Keys:    bson.D{{"name", 1}},
+		Options: option.NewIndex().SetUnique(true),
+		Ref:     testStruct{},
+	})
+	if helper.IsNotNil(err) {
+		t.Fatalf("CreateOneIndex() error = %v", err)
+	}
+	err = template.InsertOne(ctx, &testStruct{Name: "Ana"})
+	if !driver.IsDuplicateKeyError(err) {
+		t.Errorf("InsertOne() error = %v, want duplicate key error", err)
+	}
+	specifications, _ := template.ListIndexSpecifications(ctx, testStruct{})
+	if helper.IsNotEqualTo(len(specifications), 2) {
+		t.Errorf("ListIndexSpecifications() = %v", specifications)
+	}
+	_ = template.StartSession(ctx)
+	_ = template.InsertOne(ctx, &testStruct{Name: "Daniel"}, option.NewInsertOne().SetDisableAutoCloseSession(true))
+	_, _ = template.DeleteOne(ctx, bson.M{"name": "Ana"}, testStruct{},
+		option.NewDelete().SetDisableAutoCloseSession(true))
+	if err = template.AbortTransaction(ctx); helper.IsNotNil(err) {
+		t.Fatalf("AbortTransaction() error = %v", err)
+	}
+	count, _ := template.CountDocuments(ctx, bson.M{}, testStruct{})
+	if helper.IsNotEqualTo(count, int64(3)) {
+		t.Errorf("CountDocuments() after abort = %v, want 3", count)
+	}
+	if err = template.CloseSession(ctx, false); helper.IsNotEqualTo(err, mongo.ErrNoOpenSession) {
+		t.Errorf("CloseSession() error = %v, want %v", err, mongo.ErrNoOpenSession)
+	}
+}
+
+func TestTemplateWatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	template := NewTemplate()
+	events, errs := template.WatchChan(ctx, testStruct{}, mongo.Pipeline{
+		{{"$match", bson.M{"operationType": bson.M{"$in": bson.A{mongo.OperationTypeInsert, mongo.OperationTypeUpdate}}}}},
+	})
+	document := &testStruct{Name: "Ana"}
+	_ = template.InsertOne(ctx, document)
+	_, _ = template.UpdateOneById(ctx, document.Id, bson.M{"$set": bson.M{"age": 30}}, testStruct{})
+	_, _ = template.DeleteOneById(ctx, document.Id, testStruct{})
+	for _, want := range []mongo.OperationType{mongo.OperationTypeInsert, mongo.OperationTypeUpdate} {
+		select {
+		case event := <-events:
+			if helper.IsNotEqualTo(event.OperationType, want) || helper.IsNotEqualTo(event.DocumentKey.ID, document.Id) {
+				t.Errorf("WatchChan() event = %+v, want %v", event, want)
+			}
+		case err := <-errs:
+			t.Fatalf("WatchChan() error = %v", err)
+		case <-ctx.Done():
+			t.Fatal("WatchChan() event not received")
+		}
+	}
+	if _, err := template.Watch(ctx, mongo.Pipeline{}); helper.IsNotEqualTo(err, ErrNotSupported) {
+		t.Errorf("Watch() error = %v, want %v", err, ErrNotSupported)
+	}
+}
+
+func TestTemplateExplain(t *testing.T) {
+	ctx := context.TODO()
+	template, _ := initTestTemplate(t)
+	result, err := template.ExplainFind(ctx, bson.M{"address.city": "Rio"}, testStruct{})
+	if helper.IsNotNil(err) {
+		t.Fatalf("ExplainFind() error = %v", err)
+	}
+	if result.UsesIndex() || helper.IsNotEqualTo(result.DocsExamined, int64(3)) ||
+		helper.IsNotEqualTo(result.Returned, int64(2)) {
+		t.Errorf("ExplainFind() = %+v", result)
+	}
+	result, err = template.ExplainAggregate(ctx, mongo.Pipeline{bson.D{{"$limit", 1}}}, testStruct{})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(result.Returned, int64(1)) {
+		t.Errorf("ExplainAggregate() = %+v, err = %v", result, err)
+	}
+	result, err = template.ExplainUpdate(ctx, bson.M{"name": "Ana"}, bson.M{"$set": bson.M{"age": 1}}, testStruct{})
+	if helper.IsNotNil(err) || !result.HasStage("UPDATE") {
+		t.Errorf("ExplainUpdate() = %+v, err = %v", result, err)
+	}
+	var dest testStruct
+	if err = template.FindOne(ctx, bson.M{"name": "Ana"}, &dest); helper.IsNotNil(err) ||
+		helper.IsNotEqualTo(dest.Age, 30) {
+		t.Errorf("ExplainUpdate() modified document = %+v, err = %v", dest, err)
+	}
+}
+
+func TestTemplateAudit(t *testing.T) {
+	template := NewTemplate()
+	if err := template.EnableAudit(context.TODO()); helper.IsNotEqualTo(err, ErrNotSupported) {
+		t.Errorf("EnableAudit() error = %v, want %v", err, ErrNotSupported)
+	}
+	template.DisableAudit()
+	if _, err := template.FindAuditEntries(context.TODO(), 1, testStruct{}); helper.IsNotEqualTo(err, ErrNotSupported) {
+		t.Errorf("FindAuditEntries() error = %v, want %v", err, ErrNotSupported)
+	}
+}
+
+func TestTemplateVersions(t *testing.T) {
+	ctx := context.TODO()
+	template := NewTemplate()
+	if err := template.FindAsOf(ctx, 1, time.Now(), &testStruct{}); helper.IsNotEqualTo(err, ErrNotSupported) {
+		t.Errorf("FindAsOf() error = %v, want %v", err, ErrNotSupported)
+	}
+	if _, err := template.ListVersions(ctx, 1, testStruct{}); helper.IsNotEqualTo(err, ErrNotSupported) {
+		t.Errorf("ListVersions() error = %v, want %v", err, ErrNotSupported)
+	}
+	if _, err := template.RevertTo(ctx, 1, 1, testStruct{}); helper.IsNotEqualTo(err, ErrNotSupported) {
+		t.Errorf("RevertTo() error = %v, want %v", err, ErrNotSupported)
+	}
+}
@@ -0,0 +1,341 @@
+package mongotest
+
+import (
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// getPath returns the value of the dotted path on the document, without traversing the arrays of documents.
+func getPath(document bson.D, parts []string) (any, bool) {
+	var current any = document
+	for _, part := range parts {
+		switch value := current.(type) {
+		case bson.D:
+			found := false
+			for _, e := range value {
+				if helper.Equals(e.Key, part) {
+					current = e.Value
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		case bson.A:
+			i, err := strconv.Atoi(part)
+			if helper.IsNotNil(err) || helper.IsLessThan(i, 0) || helper.IsGreaterThanOrEqual(i, len(value)) {
+				return nil, false
+			}
+			current = value[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setPath sets the value on the dotted path of the document, creating the missing embedded documents.
+func setPath(document bson.D, parts []string, value any) (bson.D, error) {
+	for i, e := range document {
+		if helper.IsNotEqualTo(e.Key, parts[0]) {
+			continue
+		} else if helper.Equals(len(parts), 1) {
+			document[i].Value = value
+			return document, nil
+		}
+		child, err := setValue(e.Value, parts[1:], value)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		document[i].Value = child
+		return document, nil
+	}
+	if helper.Equals(len(parts), 1) {
+		return append(document, bson.E{Key: parts[0], Value: value}), nil
+	}
+	child, err := setPath(bson.D{}, parts[1:], value)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return append(document, bson.E{Key: parts[0], Value: child}), nil
+}
+
+func setValue(current any, parts []string, value any) (any, error) {
+	switch c := current.(type) {
+	case nil:
+		return setPath(bson.D{}, parts, value)
+	case bson.D:
+		return setPath(c, parts, value)
+	case bson.A:
+		i, err := strconv.Atoi(parts[0])
+		if helper.IsNotNil(err) || helper.IsLessThan(i, 0) {
+			return nil, fmt.Errorf("mongotest: cannot create field '%s' in array", parts[0])
+		}
+		for helper.IsGreaterThanOrEqual(i, len(c)) {
+			c = append(c, nil)
+		}
+		if helper.Equals(len(parts), 1) {
+			c[i] = value
+			return c, nil
+		}
+		c[i], err = setValue(c[i], parts[1:], value)
+		return c, err
+	}
+	return nil, fmt.Errorf("mongotest: cannot create field '%s' in element %v", parts[0], current)
+}
+
+// unsetPath removes the dotted path of the document, the array elements are set to null, like the server.
+func unsetPath(document bson.D, parts []string) bson.D {
+	for i, e := range document {
+		if helper.IsNotEqualTo(e.Key, parts[0]) {
+			continue
+		} else if helper.Equals(len(parts), 1) {
+			return append(document[:i], document[i+1:]...)
+		}
+		switch child := e.Value.(type) {
+		case bson.D:
+			document[i].Value = unsetPath(child, parts[1:])
+		case bson.A:
+			unsetArrayPath(child, parts[1:])
+		}
+		return document
+	}
+	return document
+}
+
+func unsetArrayPath(array bson.A, parts []string) {
+	i, err := strconv.Atoi(parts[0])
+	if helper.IsNotNil(err) || helper.IsLessThan(i, 0) || helper.IsGreaterThanOrEqual(i, len(array)) {
+		return
+	} else if helper.Equals(len(parts), 1) {
+		array[i] = nil
+		return
+	}
+	switch child := array[i].(type) {
+	case bson.D:
+		array[i] = unsetPath(child, parts[1:])
+	case bson.A:
+		unsetArrayPath(child, parts[1:])
+	}
+}
+
+// applyUpdate returns a copy of the document with the update operators applied, the insert parameter is true when
+// the document is being upserted, so $setOnInsert is applied.
+func applyUpdate(document, update bson.D, insert bool) (bson.D, error) {
+	if helper.IsEmpty(update) {
+		return nil, ErrUpdateIsEmpty
+	}
+	result := copyDocument(document)
+	for _, operator := range update {
+		if !strings.HasPrefix(operator.Key, "$") {
+			return nil, ErrUpdateWithoutOperators
+		}
+		fields, ok := operator.Value.(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("mongotest: %s argument must be a document", operator.Key)
+		}
+		for _, field := range fields {
+			var err error
+			result, err = applyUpdateOperator(result, operator.Key, splitPath(field.Key), field.Value, insert)
+			if helper.IsNotNil(err) {
+				return nil, err
+			}
+		}
+	}
+	if id, ok := getPath(document, []string{"_id"}); ok {
+		if newId, ok := getPath(result, []string{"_id"}); !ok || !equal(id, newId) {
+			return nil, ErrImmutableId
+		}
+	}
+	return result, nil
+}
+
+func applyUpdateOperator(document bson.D, operator string, parts []string, v any, insert bool) (bson.D, error) {
+	current, exists := getPath(document, parts)
+	switch operator {
+	case "$set":
+		return setPath(document, parts, copyValue(v))
+	case "$setOnInsert":
+		if insert {
+			return setPath(document, parts, copyValue(v))
+		}
+		return document, nil
+	case "$unset":
+		return unsetPath(document, parts), nil
+	case "$inc", "$mul":
+		result, err := arithmetic(current, v, operator)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		return setPath(document, parts, result)
+	case "$min", "$max":
+		c := compare(v, current)
+		if !exists || (helper.Equals(operator, "$min") && helper.IsLessThan(c, 0)) ||
+			(helper.Equals(operator, "$max") && helper.IsGreaterThan(c, 0)) {
+			return setPath(document, parts, copyValue(v))
+		}
+		return document, nil
+	case "$currentDate":
+		var now any = primitive.NewDateTimeFromTime(time.Now())
+		if specification, ok := v.(bson.D); ok && helper.Equals(len(specification), 1) &&
+			helper.Equals(specification[0].Value, "timestamp") {
+			now = primitive.Timestamp{T: uint32(time.Now().Unix())}
+		}
+		return setPath(document, parts, now)
+	case "$rename":
+		name, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("mongotest: $rename target must be a string")
+		} else if !exists {
+			return document, nil
+		}
+		return setPath(unsetPath(document, parts), splitPath(name), current)
+	case "$push", "$addToSet", "$pull":
+		array, ok := current.(bson.A)
+		if exists && !ok {
+			return nil, fmt.Errorf("mongotest: %s requires an array field, the field '%s' is not an array", operator,
+				strings.Join(parts, "."))
+		}
+		array, err := applyArrayOperator(array, operator, v)
+		if helper.IsNotNil(err) {
+			return nil, err
+		} else if !exists && helper.Equals(operator, "$pull") {
+			return document, nil
+		}
+		return setPath(document, parts, array)
+	}
+	return nil, fmt.Errorf("%w: update operator %s", ErrNotSupported, operator)
+}
+
+func applyArrayOperator(array bson.A, operator string, v any) (bson.A, error) {
+	result := append(bson.A{}, array...)
+	if helper.Equals(operator, "$pull") {
+		filtered := bson.A{}
+		for _, item := range result {
+			var ok bool
+			var err error
+			if condition, isDocument := v.(bson.D); isDocument && !isOperatorDocument(v) {
+				if document, isDocument := item.(bson.D); isDocument {
+					ok, err = matches(document, condition)
+				}
+			} else {
+				ok, err = matchValues([]any{item}, v)
+			}
+			if helper.IsNotNil(err) {
+				return nil, err
+			} else if !ok {
+				filtered = append(filtered, item)
+			}
+		}
+		return filtered, nil
+	}
+	values := []any{v}
+	if modifiers, ok := v.(bson.D); ok && isOperatorDocument(v) {
+		if helper.IsNotEqualTo(len(modifiers), 1) || helper.IsNotEqualTo(modifiers[0].Key, "$each") {
+			return nil, fmt.Errorf("%w: %s modifiers other than $each", ErrNotSupported, operator)
+		}
+		each, ok := modifiers[0].Value.(bson.A)
+		if !ok {
+			return nil, fmt.Errorf("mongotest: $each argument must be an array")
+		}
+		values = each
+	}
+	for _, value := range values {
+		if helper.Equals(operator, "$addToSet") && matchEquality([]any{result}, value) {
+			continue
+		}
+		result = append(result, copyValue(value))
+	}
+	return result, nil
+}
+
+// arithmetic applies $inc or $mul on the current value, keeping int32 while the result fits, like the server.
+func arithmetic(current, operand any, operator string) (any, error) {
+	if !isNumber(operand) {
+		return nil, fmt.Errorf("mongotest: cannot %s with non-numeric argument %v", operator, operand)
+	} else if current == nil {
+		current = int32(0)
+	} else if !isNumber(current) {
+		return nil, fmt.Errorf("mongotest: cannot apply %s to a value of non-numeric type", operator)
+	}
+	if isIntegral(current) && isIntegral(operand) {
+		a, b := toInt(current), toInt(operand)
+		result := a + b
+		if helper.Equals(operator, "$mul") {
+			result = a * b
+		}
+		_, currentInt32 := current.(int32)
+		_, operandInt32 := operand.(int32)
+		if currentInt32 && operandInt32 && helper.IsGreaterThanOrEqual(result, int64(math.MinInt32)) &&
+			helper.IsLessThanOrEqual(result, int64(math.MaxInt32)) {
+			return int32(result), nil
+		}
+		return result, nil
+	}
+	if helper.Equals(operator, "$mul") {
+		return toFloat(current) * toFloat(operand), nil
+	}
+	return toFloat(current) + toFloat(operand), nil
+}
+
+// upsertDocument returns the document inserted by an upsert, with the equality conditions of the filter.
+func upsertDocument(filter bson.D) (bson.D, error) {
+	result := bson.D{}
+	var err error
+	for _, e := range filter {
+		if helper.Equals(e.Key, "$and") {
+			filters, _ := e.Value.(bson.A)
+			for _, item := range filters {
+				if document, ok := item.(bson.D); ok {
+					var equalities bson.D
+					equalities, err = upsertDocument(document)
+					for _, equality := range equalities {
+						if helper.IsNil(err) {
+							result, err = setPath(result, splitPath(equality.Key), equality.Value)
+						}
+					}
+				}
+			}
+		} else if strings.HasPrefix(e.Key, "$") {
+			continue
+		} else if operators, ok := e.Value.(bson.D); ok && isOperatorDocument(operators) {
+			if helper.Equals(len(operators), 1) && helper.Equals(operators[0].Key, "$eq") {
+				result, err = setPath(result, splitPath(e.Key), copyValue(operators[0].Value))
+			}
+		} else {
+			result, err = setPath(result, splitPath(e.Key), copyValue(e.Value))
+		}
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// updateDescription returns the updated and removed top level fields of the document, like the change events.
+func updateDescription(before, after bson.D) bson.D {
+	updatedFields := bson.D{}
+	removedFields := bson.A{}
+	for _, e := range after {
+		if value, ok := getPath(before, []string{e.Key}); !ok || !equal(value, e.Value) {
+			updatedFields = append(updatedFields, e)
+		}
+	}
+	for _, e := range before {
+		if _, ok := getPath(after, []string{e.Key}); !ok {
+			removedFields = append(removedFields, e.Key)
+		}
+	}
+	return bson.D{
+		{"updatedFields", updatedFields},
+		{"removedFields", removedFields},
+		{"truncatedArrays", bson.A{}},
+	}
+}
@@ -0,0 +1,312 @@
+package mongotest
+
+import (
+	"context"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	driver "go.mongodb.org/mongo-driver/mongo"
+	"sync"
+	"time"
+)
+
+// watcher receives the change events of a watch function, the events are queued, so the writes never wait for the
+// handlers.
+type watcher struct {
+	databaseName   string
+	collectionName string
+	filters        []bson.D
+	mutex          sync.Mutex
+	queue          []bson.D
+	signal         chan struct{}
+}
+
+// Watch returns ErrNotSupported, since the driver change stream cannot be created without a server, use
+// WatchWithHandlerE or WatchChan instead.
+func (t *Template) Watch(context.Context, any, ...*option.Watch) (*driver.ChangeStream, error) {
+	return nil, ErrNotSupported
+}
+
+// WatchWithHandler calls the handler with the change events committed after it is called, see
+// WatchWithHandlerE.
+func (t *Template) WatchWithHandler(ctx context.Context, pipeline any, handler mongo.EventHandler,
+	opts ...*option.WatchWithHandler) error {
+	var handlerE mongo.EventHandlerE
+	if handler != nil {
+		handlerE = func(ctx *mongo.EventContext) error {
+			handler(ctx)
+			return nil
+		}
+	}
+	return t.WatchWithHandlerE(ctx, pipeline, handlerE, opts...)
+}
+
+// WatchWithHandlerE calls the handler with the change events committed after it is called, one at a time, until
+// the ctx is done. Only the $match stages are supported on the pipeline parameter.
+//
+// The failed events are retried and sent to the option.WatchWithHandler.DeadLetter func like on
+// mongo.Template.WatchWithHandlerE, the options that depend on the change stream, e.g. the TokenStore and the
+// Concurrency, are ignored.
+func (t *Template) WatchWithHandlerE(ctx context.Context, pipeline any, handler mongo.EventHandlerE,
+	opts ...*option.WatchWithHandler) error {
+	opt := option.MergeWatchHandlerByParams(opts)
+	if handler == nil {
+		return mongo.ErrEventHandlerIsNil
+	}
+	w, err := t.subscribe(opt.DatabaseName, opt.CollectionName, pipeline)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	defer t.unsubscribe(w)
+	for {
+		current, ok := w.next(ctx)
+		if !ok {
+			return nil
+		}
+		err = processEvent(ctx, handler, current, opt)
+		if helper.IsNotNil(ctx.Err()) {
+			return nil
+		} else if helper.IsNotNil(err) {
+			return err
+		}
+	}
+}
+
+// WatchChan delivers the change events of the collection of the ref parameter committed after it is called on the
+// events channel, see mongo.Template.WatchChan. Only the $match stages are supported on the pipeline parameter.
+func (t *Template) WatchChan(ctx context.Context, ref, pipeline any, opts ...*option.WatchChan) (<-chan mongo.Event,
+	<-chan error) {
+	opt := option.MergeWatchChanByParams(opts)
+	events := make(chan mongo.Event, opt.BufferSize)
+	errs := make(chan error, 1)
+	databaseName, collectionName, err := getNamesByAny(ref)
+	var w *watcher
+	if helper.IsNil(err) {
+		w, err = t.subscribe(databaseName, collectionName, pipeline)
+	}
+	if helper.IsNotNil(err) {
+		errs <- err
+		close(events)
+		close(errs)
+		return events, errs
+	}
+	go func() {
+		defer close(errs)
+		defer close(events)
+		defer t.unsubscribe(w)
+		for {
+			current, ok := w.next(ctx)
+			if !ok {
+				return
+			}
+			var event mongo.Event
+			if err := bson.Unmarshal(current, &event); helper.IsNotNil(err) {
+				errs <- err
+				return
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, errs
+}
+
+// WatchWithRouter calls the handlers of the router with the change events committed after it is called, see
+// WatchWithHandlerE.
+func (t *Template) WatchWithRouter(ctx context.Context, router *mongo.EventRouter,
+	opts ...*option.WatchWithHandler) error {
+	if helper.IsNil(router) {
+		return mongo.ErrEventRouterIsEmpty
+	} else if err := router.Err(); helper.IsNotNil(err) {
+		return err
+	}
+	pipeline := router.Pipeline()
+	// the $match stage of the router has an $or condition by route
+	if routes, _ := pipeline[0][0].Value.(bson.D)[0].Value.(bson.A); helper.IsEmpty(routes) {
+		return mongo.ErrEventRouterIsEmpty
+	}
+	opt := option.MergeWatchHandlerByParams(opts)
+	opt.CollectionName = ""
+	return t.WatchWithHandlerE(ctx, pipeline, router.Handle, opt)
+}
+
+func (t *Template) subscribe(databaseName, collectionName string, pipeline any) (*watcher, error) {
+	stages, err := toPipeline(pipeline)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	w := &watcher{
+		databaseName:   databaseName,
+		collectionName: collectionName,
+		signal:         make(chan struct{}, 1),
+	}
+	for _, stage := range stages {
+		filter, ok := stage[0].Value.(bson.D)
+		if helper.IsNotEqualTo(stage[0].Key, "$match") || !ok {
+			return nil, fmt.Errorf("%w: change stream stage %s", ErrNotSupported, stage[0].Key)
+		}
+		w.filters = append(w.filters, filter)
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.watchers[w] = struct{}{}
+	return w, nil
+}
+
+func (t *Template) unsubscribe(w *watcher) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.watchers, w)
+}
+
+// record adds the change event to the session, it is published when the session is committed.
+func (t *Template) record(operationType mongo.OperationType, c *collection, id any, fullDocument,
+	description bson.D) {
+	event := t.newEvent(operationType, c.databaseName, c.collectionName, id, fullDocument, description)
+	if helper.IsNotNil(t.session) {
+		t.session.events = append(t.session.events, event)
+	} else {
+		t.publish([]bson.D{event})
+	}
+}
+
+// newEvent returns the change event document, with the fields of the server change events.
+func (t *Template) newEvent(operationType mongo.OperationType, databaseName, collectionName string, id any,
+	fullDocument, description bson.D) bson.D {
+	t.eventCounter++
+	now := time.Now()
+	ns := bson.D{{"db", databaseName}}
+	if helper.IsNotEmpty(collectionName) {
+		ns = append(ns, bson.E{Key: "coll", Value: collectionName})
+	}
+	event := bson.D{
+		{"_id", bson.D{{"_data", fmt.Sprintf("%016X", t.eventCounter)}}},
+		{"operationType", operationType},
+		{"clusterTime", primitive.Timestamp{T: uint32(now.Unix()), I: t.eventCounter}},
+		{"wallTime", primitive.NewDateTimeFromTime(now)},
+		{"ns", ns},
+	}
+	if helper.IsNotNil(id) {
+		event = append(event, bson.E{Key: "documentKey", Value: bson.D{{"_id", id}}})
+	}
+	if helper.IsNotNil(fullDocument) {
+		event = append(event, bson.E{Key: "fullDocument", Value: fullDocument})
+	}
+	if helper.IsNotNil(description) {
+		event = append(event, bson.E{Key: "updateDescription", Value: description})
+	}
+	// the event is matched by the pipeline filters, so it has the bson types, e.g. string instead of OperationType
+	result, _ := toDocument(event)
+	return result
+}
+
+// publish queues the events on the watchers that accept them.
+func (t *Template) publish(events []bson.D) {
+	for w := range t.watchers {
+		for _, event := range events {
+			if w.accept(event) {
+				w.push(event)
+			}
+		}
+	}
+}
+
+func (w *watcher) accept(event bson.D) bool {
+	if helper.IsNotEmpty(w.databaseName) && !matchEquality(lookup(event, []string{"ns", "db"}), w.databaseName) {
+		return false
+	} else if helper.IsNotEmpty(w.collectionName) &&
+		!matchEquality(lookup(event, []string{"ns", "coll"}), w.collectionName) {
+		return false
+	}
+	for _, filter := range w.filters {
+		if ok, err := matches(event, filter); helper.IsNotNil(err) || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *watcher) push(event bson.D) {
+	w.mutex.Lock()
+	w.queue = append(w.queue, event)
+	w.mutex.Unlock()
+	select {
+	case w.signal <- struct{}{}:
+	default:
+	}
+}
+
+// next returns the next event of the queue, waiting for it, or false if the ctx is done.
+func (w *watcher) next(ctx context.Context) (bson.Raw, bool) {
+	for {
+		w.mutex.Lock()
+		if helper.IsNotEmpty(w.queue) {
+			event := w.queue[0]
+			w.queue = w.queue[1:]
+			w.mutex.Unlock()
+			b, err := bson.Marshal(event)
+			return b, helper.IsNil(err)
+		}
+		w.mutex.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-w.signal:
+		}
+	}
+}
+
+// processEvent calls the handler with the event, retrying it and sending it to the dead letter like the
+// mongo.Template.
+func processEvent(ctx context.Context, handler mongo.EventHandlerE, current bson.Raw,
+	opt *option.WatchWithHandler) error {
+	var event mongo.Event
+	err := bson.Unmarshal(current, &event)
+	if helper.IsNil(err) {
+		err = processEventWithRetry(ctx, handler, event, current, opt)
+	}
+	if helper.IsNil(err) || helper.IsNotNil(ctx.Err()) {
+		return err
+	}
+	sendWatchError(opt, err)
+	if opt.DeadLetter == nil {
+		return err
+	}
+	return opt.DeadLetter(ctx, current, err)
+}
+
+func processEventWithRetry(ctx context.Context, handler mongo.EventHandlerE, event mongo.Event, current bson.Raw,
+	opt *option.WatchWithHandler) error {
+	delay := opt.RetryDelay
+	for retry := 0; ; retry++ {
+		handlerCtx, cancel := context.WithTimeout(ctx, opt.ContextFuncTimeout)
+		err := handler(&mongo.EventContext{Context: handlerCtx, Event: event, Raw: current})
+		cancel()
+		if helper.IsNil(err) || helper.IsNotNil(ctx.Err()) || helper.IsGreaterThanOrEqual(retry, *opt.MaxRetries) {
+			return err
+		}
+		sendWatchError(opt, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, opt.MaxRetryDelay)
+	}
+}
+
+func sendWatchError(opt *option.WatchWithHandler, err error) {
+	if helper.IsNil(opt.Errors) {
+		return
+	}
+	select {
+	case opt.Errors <- err:
+	default:
+	}
+}
@@ -50,6 +50,16 @@ type Aggregate struct {
 	// option names and values. Values must be Marshaller. Custom options may conflict with non-custom options, and custom
 	// options bypass client-side validation. Prefer using non-custom options where possible.
 	Custom bson.M
+	// ReadPreference Determines which members of the replica set can be used by the operation. The default value is
+	// the global option, and if it is nil, the client read preference.
+	ReadPreference *ReadPreference
+	// ReadConcern The consistency and isolation properties of the data read by the operation. The default value is
+	// the global option, and if it is empty, the client read concern.
+	ReadConcern ReadConcern
+	// CausalConsistency If true, the operation runs in a causally consistent session after the last write of the
+	// template, so it sees that write, even on a secondary. If the template has an open session, the operation runs
+	// on it. The default value is the global option.
+	CausalConsistency *bool
 }
 
 // NewAggregate creates a new Aggregate instance.
@@ -117,6 +127,24 @@ func (a *Aggregate) SetCustom(b bson.M) *Aggregate {
 	return a
 }
 
+// SetReadPreference sets value for the ReadPreference field.
+func (a *Aggregate) SetReadPreference(r *ReadPreference) *Aggregate {
+	a.ReadPreference = r
+	return a
+}
+
+// SetReadConcern sets value for the ReadConcern field.
+func (a *Aggregate) SetReadConcern(r ReadConcern) *Aggregate {
+	a.ReadConcern = r
+	return a
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (a *Aggregate) SetCausalConsistency(b bool) *Aggregate {
+	a.CausalConsistency = &b
+	return a
+}
+
 // MergeAggregateByParams assembles the Aggregate object from optional parameters.
 func MergeAggregateByParams(opts []*Aggregate) *Aggregate {
 	result := &Aggregate{}
@@ -151,6 +179,15 @@ func MergeAggregateByParams(opts []*Aggregate) *Aggregate {
 		if helper.IsNotNil(opt.Custom) {
 			result.Custom = opt.Custom
 		}
+		if helper.IsNotNil(opt.ReadPreference) {
+			result.ReadPreference = opt.ReadPreference
+		}
+		if helper.IsNotEmpty(opt.ReadConcern) {
+			result.ReadConcern = opt.ReadConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
 	}
 	return result
 }
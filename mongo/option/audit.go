@@ -0,0 +1,67 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"time"
+)
+
+// Audit represents options that can be used to configure the audit log of the mongo.Template mutations.
+type Audit struct {
+	// DatabaseName database name where the audit entries are stored (required)
+	DatabaseName string
+	// CollectionName collection name where the audit entries are stored
+	//
+	// default: _audit
+	CollectionName string
+	// Retention Duration that the audit entries are kept, they are removed by a TTL index on the timestamp field, if
+	// it is zero the entries are kept forever.
+	//
+	// default: 0
+	Retention time.Duration
+}
+
+// NewAudit creates a new Audit instance.
+func NewAudit() *Audit {
+	return &Audit{}
+}
+
+// SetDatabaseName sets value for the DatabaseName field.
+func (a *Audit) SetDatabaseName(s string) *Audit {
+	a.DatabaseName = s
+	return a
+}
+
+// SetCollectionName sets value for the CollectionName field.
+func (a *Audit) SetCollectionName(s string) *Audit {
+	a.CollectionName = s
+	return a
+}
+
+// SetRetention sets value for the Retention field.
+func (a *Audit) SetRetention(d time.Duration) *Audit {
+	a.Retention = d
+	return a
+}
+
+// MergeAuditByParams assembles the Audit object from optional parameters.
+func MergeAuditByParams(opts []*Audit) *Audit {
+	result := &Audit{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotEmpty(opt.DatabaseName) {
+			result.DatabaseName = opt.DatabaseName
+		}
+		if helper.IsNotEmpty(opt.CollectionName) {
+			result.CollectionName = opt.CollectionName
+		}
+		if helper.IsGreaterThan(opt.Retention, 0) {
+			result.Retention = opt.Retention
+		}
+	}
+	if helper.IsEmpty(result.CollectionName) {
+		result.CollectionName = "_audit"
+	}
+	return result
+}
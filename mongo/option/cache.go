@@ -0,0 +1,59 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"time"
+)
+
+// Cache represents options that can be used to configure the cache of the mongo.Template, see
+// mongo.Template.SetCache.
+type Cache struct {
+	// TTL Time that the documents are kept on the cache after read from the server.
+	//
+	// default: 1 minute
+	TTL time.Duration
+	// KeyPrefix Prefix of the cache keys, useful when the cache store is shared with other applications.
+	//
+	// default: mongo:
+	KeyPrefix string
+}
+
+// NewCache creates a new Cache instance.
+func NewCache() *Cache {
+	return &Cache{}
+}
+
+// SetTTL sets value for the TTL field.
+func (c *Cache) SetTTL(d time.Duration) *Cache {
+	c.TTL = d
+	return c
+}
+
+// SetKeyPrefix sets value for the KeyPrefix field.
+func (c *Cache) SetKeyPrefix(s string) *Cache {
+	c.KeyPrefix = s
+	return c
+}
+
+// MergeCacheByParams assembles the Cache object from optional parameters.
+func MergeCacheByParams(opts []*Cache) *Cache {
+	result := &Cache{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsGreaterThan(opt.TTL, 0) {
+			result.TTL = opt.TTL
+		}
+		if helper.IsNotEmpty(opt.KeyPrefix) {
+			result.KeyPrefix = opt.KeyPrefix
+		}
+	}
+	if helper.IsLessThanOrEqual(result.TTL, 0) {
+		result.TTL = time.Minute
+	}
+	if helper.IsEmpty(result.KeyPrefix) {
+		result.KeyPrefix = "mongo:"
+	}
+	return result
+}
@@ -0,0 +1,170 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"go.mongodb.org/mongo-driver/tag"
+	"time"
+)
+
+// ReadPreferenceMode indicates the members of the replica set that can be used by the read operations.
+type ReadPreferenceMode string
+
+// ReadConcern indicates the consistency and isolation properties of the data read by the operation.
+type ReadConcern string
+
+const (
+	// ReadPreferencePrimary reads only from the primary, it is the default mode.
+	ReadPreferencePrimary ReadPreferenceMode = "primary"
+	// ReadPreferencePrimaryPreferred reads from the primary if available, otherwise from a secondary.
+	ReadPreferencePrimaryPreferred ReadPreferenceMode = "primaryPreferred"
+	// ReadPreferenceSecondary reads only from a secondary.
+	ReadPreferenceSecondary ReadPreferenceMode = "secondary"
+	// ReadPreferenceSecondaryPreferred reads from a secondary if available, otherwise from the primary.
+	ReadPreferenceSecondaryPreferred ReadPreferenceMode = "secondaryPreferred"
+	// ReadPreferenceNearest reads from the member with the lowest network latency.
+	ReadPreferenceNearest ReadPreferenceMode = "nearest"
+)
+
+const (
+	// ReadConcernLocal returns the most recent data of the member, without guarantee that it was written to a
+	// majority of the replica set members.
+	ReadConcernLocal ReadConcern = "local"
+	// ReadConcernAvailable is like ReadConcernLocal, but on sharded clusters it can return orphaned documents.
+	ReadConcernAvailable ReadConcern = "available"
+	// ReadConcernMajority returns only data acknowledged by a majority of the replica set members.
+	ReadConcernMajority ReadConcern = "majority"
+	// ReadConcernLinearizable returns data that reflects all the majority acknowledged writes completed before the
+	// start of the read operation, it is only valid on the primary.
+	ReadConcernLinearizable ReadConcern = "linearizable"
+	// ReadConcernSnapshot returns data from a snapshot of majority committed data.
+	ReadConcernSnapshot ReadConcern = "snapshot"
+)
+
+// WriteConcernMajority is the WriteConcern.W value to request acknowledgment from a majority of the replica set
+// members.
+const WriteConcernMajority = "majority"
+
+// ReadPreference determines which members of the replica set are used by the read operations.
+type ReadPreference struct {
+	// Mode the members that can be used by the read operations.
+	//
+	// default: ReadPreferencePrimary
+	Mode ReadPreferenceMode
+	// TagSets Ordered list of tag sets to select the members, they are not valid with ReadPreferencePrimary.
+	TagSets []map[string]string
+	// MaxStaleness The maximum replication lag of the selected secondaries, it is not valid with
+	// ReadPreferencePrimary and must be at least 90 seconds.
+	MaxStaleness time.Duration
+	// HedgeEnabled If true, the reads are hedged on sharded clusters, it is only valid with ReadPreferenceNearest by
+	// default, and with the secondary modes.
+	HedgeEnabled *bool
+}
+
+// WriteConcern describes the level of acknowledgment requested from the server for the write operations.
+type WriteConcern struct {
+	// W The number of members that must acknowledge the write, an int, WriteConcernMajority, or a custom write concern
+	// name configured on the replica set.
+	W any
+	// Journal If true, the write must be written to the on-disk journal before being acknowledged.
+	Journal *bool
+	// WTimeout The time limit for the write concern, after it an error is returned, although the write may still
+	// succeed.
+	WTimeout time.Duration
+}
+
+// NewReadPreference creates a new ReadPreference instance with the mode parameter.
+func NewReadPreference(mode ReadPreferenceMode) *ReadPreference {
+	return &ReadPreference{
+		Mode: mode,
+	}
+}
+
+// NewWriteConcern creates a new WriteConcern instance with the w parameter, see the WriteConcern.W documentation.
+func NewWriteConcern(w any) *WriteConcern {
+	return &WriteConcern{
+		W: w,
+	}
+}
+
+// SetTagSets sets value for the TagSets field.
+func (r *ReadPreference) SetTagSets(tagSets ...map[string]string) *ReadPreference {
+	r.TagSets = tagSets
+	return r
+}
+
+// SetMaxStaleness sets value for the MaxStaleness field.
+func (r *ReadPreference) SetMaxStaleness(d time.Duration) *ReadPreference {
+	r.MaxStaleness = d
+	return r
+}
+
+// SetHedgeEnabled sets value for the HedgeEnabled field.
+func (r *ReadPreference) SetHedgeEnabled(b bool) *ReadPreference {
+	r.HedgeEnabled = &b
+	return r
+}
+
+// SetJournal sets value for the Journal field.
+func (w *WriteConcern) SetJournal(b bool) *WriteConcern {
+	w.Journal = &b
+	return w
+}
+
+// SetWTimeout sets value for the WTimeout field.
+func (w *WriteConcern) SetWTimeout(d time.Duration) *WriteConcern {
+	w.WTimeout = d
+	return w
+}
+
+// ParseReadPreference convert ReadPreference to mongo readpref.ReadPref
+func ParseReadPreference(r *ReadPreference) (*readpref.ReadPref, error) {
+	if helper.IsNil(r) {
+		return nil, nil
+	}
+	mode := readpref.PrimaryMode
+	if helper.IsNotEmpty(r.Mode) {
+		var err error
+		mode, err = readpref.ModeFromString(string(r.Mode))
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+	}
+	var opts []readpref.Option
+	if helper.IsNotEmpty(r.TagSets) {
+		tagSets := make([]tag.Set, 0, len(r.TagSets))
+		for _, tags := range r.TagSets {
+			tagSets = append(tagSets, tag.NewTagSetFromMap(tags))
+		}
+		opts = append(opts, readpref.WithTagSets(tagSets...))
+	}
+	if helper.IsGreaterThan(r.MaxStaleness, 0) {
+		opts = append(opts, readpref.WithMaxStaleness(r.MaxStaleness))
+	}
+	if helper.IsNotNil(r.HedgeEnabled) {
+		opts = append(opts, readpref.WithHedgeEnabled(*r.HedgeEnabled))
+	}
+	return readpref.New(mode, opts...)
+}
+
+// ParseReadConcern convert ReadConcern to mongo readconcern.ReadConcern
+func ParseReadConcern(r ReadConcern) *readconcern.ReadConcern {
+	if helper.IsEmpty(r) {
+		return nil
+	}
+	return &readconcern.ReadConcern{Level: string(r)}
+}
+
+// ParseWriteConcern convert WriteConcern to mongo writeconcern.WriteConcern
+func ParseWriteConcern(w *WriteConcern) *writeconcern.WriteConcern {
+	if helper.IsNil(w) {
+		return nil
+	}
+	return &writeconcern.WriteConcern{
+		W:        w.W,
+		Journal:  w.Journal,
+		WTimeout: w.WTimeout,
+	}
+}
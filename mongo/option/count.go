@@ -31,6 +31,16 @@ type Count struct {
 	// Skip
 	// The number of documents to skip before counting. The default value is 0.
 	Skip *int64
+	// ReadPreference Determines which members of the replica set can be used by the operation. The default value is
+	// the global option, and if it is nil, the client read preference.
+	ReadPreference *ReadPreference
+	// ReadConcern The consistency and isolation properties of the data read by the operation. The default value is
+	// the global option, and if it is empty, the client read concern.
+	ReadConcern ReadConcern
+	// CausalConsistency If true, the operation runs in a causally consistent session after the last write of the
+	// template, so it sees that write, even on a secondary. If the template has an open session, the operation runs
+	// on it. The default value is the global option.
+	CausalConsistency *bool
 }
 
 // EstimatedDocumentCount represents options that can be used to configure an 'EstimatedDocumentCount' operation.
@@ -93,6 +103,24 @@ func (c *Count) SetSkip(i int64) *Count {
 	return c
 }
 
+// SetReadPreference sets value for the ReadPreference field.
+func (c *Count) SetReadPreference(r *ReadPreference) *Count {
+	c.ReadPreference = r
+	return c
+}
+
+// SetReadConcern sets value for the ReadConcern field.
+func (c *Count) SetReadConcern(r ReadConcern) *Count {
+	c.ReadConcern = r
+	return c
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (c *Count) SetCausalConsistency(b bool) *Count {
+	c.CausalConsistency = &b
+	return c
+}
+
 // SetMaxTime sets value for the MaxTime field.
 func (e *EstimatedDocumentCount) SetMaxTime(d time.Duration) *EstimatedDocumentCount {
 	e.MaxTime = &d
@@ -130,6 +158,15 @@ func MergeCountByParams(opts []*Count) Count {
 		if helper.IsNotNil(opt.Skip) {
 			result.Skip = opt.Skip
 		}
+		if helper.IsNotNil(opt.ReadPreference) {
+			result.ReadPreference = opt.ReadPreference
+		}
+		if helper.IsNotEmpty(opt.ReadConcern) {
+			result.ReadConcern = opt.ReadConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
 	}
 	return result
 }
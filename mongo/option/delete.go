@@ -33,6 +33,15 @@ type Delete struct {
 	// committing the transactions, and continue creating a new session.
 	// default is false
 	ForceRecreateSession *bool
+	// WriteConcern The acknowledgment requested from the server for the transaction of the template session started by
+	// the operation. It only applies when the session is created, since the write concern of a transaction is set when
+	// it starts, so it is ignored if the session is already open, e.g. after StartSession or a previous operation with
+	// DisableAutoCloseSession. The default value is the global option, and if it is nil, the client write concern.
+	WriteConcern *WriteConcern
+	// CausalConsistency If set, configures the causal consistency of the session started by the operation, it is
+	// ignored if the session is already open. The default value is the global option, and if it is false, the driver
+	// default, which is true.
+	CausalConsistency *bool
 }
 
 // NewDelete creates a new Delete instance.
@@ -82,6 +91,18 @@ func (d *Delete) SetForceRecreateSession(b bool) *Delete {
 	return d
 }
 
+// SetWriteConcern sets value for the WriteConcern field.
+func (d *Delete) SetWriteConcern(w *WriteConcern) *Delete {
+	d.WriteConcern = w
+	return d
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (d *Delete) SetCausalConsistency(b bool) *Delete {
+	d.CausalConsistency = &b
+	return d
+}
+
 // MergeDeleteByParams assembles the Delete object from optional parameters.
 func MergeDeleteByParams(opts []*Delete, global *Global) *Delete {
 	result := &Delete{}
@@ -110,6 +131,12 @@ func MergeDeleteByParams(opts []*Delete, global *Global) *Delete {
 		if helper.IsNotNil(opt.ForceRecreateSession) {
 			result.ForceRecreateSession = opt.ForceRecreateSession
 		}
+		if helper.IsNotNil(opt.WriteConcern) {
+			result.WriteConcern = opt.WriteConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
 	}
 	if helper.IsNil(result.Comment) {
 		result.Comment = global.Comment
@@ -123,5 +150,11 @@ func MergeDeleteByParams(opts []*Delete, global *Global) *Delete {
 	if helper.IsNil(result.ForceRecreateSession) {
 		result.ForceRecreateSession = helper.ConvertToPointer(global.ForceRecreateSession)
 	}
+	if helper.IsNil(result.WriteConcern) {
+		result.WriteConcern = global.WriteConcern
+	}
+	if helper.IsNil(result.CausalConsistency) && global.CausalConsistency {
+		result.CausalConsistency = helper.ConvertToPointer(true)
+	}
 	return result
 }
@@ -21,6 +21,16 @@ type Distinct struct {
 	// its place to control the amount of time that a single operation can run before returning an error. MaxTime is
 	// ignored if Timeout is set on the client.
 	MaxTime *time.Duration
+	// ReadPreference Determines which members of the replica set can be used by the operation. The default value is
+	// the global option, and if it is nil, the client read preference.
+	ReadPreference *ReadPreference
+	// ReadConcern The consistency and isolation properties of the data read by the operation. The default value is
+	// the global option, and if it is empty, the client read concern.
+	ReadConcern ReadConcern
+	// CausalConsistency If true, the operation runs in a causally consistent session after the last write of the
+	// template, so it sees that write, even on a secondary. If the template has an open session, the operation runs
+	// on it. The default value is the global option.
+	CausalConsistency *bool
 }
 
 // NewDistinct creates a new Distinct instance.
@@ -46,6 +56,24 @@ func (d *Distinct) SetComment(comment any) *Distinct {
 	return d
 }
 
+// SetReadPreference sets value for the ReadPreference field.
+func (d *Distinct) SetReadPreference(r *ReadPreference) *Distinct {
+	d.ReadPreference = r
+	return d
+}
+
+// SetReadConcern sets value for the ReadConcern field.
+func (d *Distinct) SetReadConcern(r ReadConcern) *Distinct {
+	d.ReadConcern = r
+	return d
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (d *Distinct) SetCausalConsistency(b bool) *Distinct {
+	d.CausalConsistency = &b
+	return d
+}
+
 // MergeDistinctByParams assembles the Distinct object from optional parameters.
 func MergeDistinctByParams(opts []*Distinct) *Distinct {
 	result := &Distinct{}
@@ -62,6 +90,15 @@ func MergeDistinctByParams(opts []*Distinct) *Distinct {
 		if helper.IsNotNil(opt.MaxTime) {
 			result.MaxTime = opt.MaxTime
 		}
+		if helper.IsNotNil(opt.ReadPreference) {
+			result.ReadPreference = opt.ReadPreference
+		}
+		if helper.IsNotEmpty(opt.ReadConcern) {
+			result.ReadConcern = opt.ReadConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
 	}
 	return result
 }
@@ -74,6 +74,16 @@ type Find struct {
 	// Values must be constant or closed expressions that do not reference document fields. Parameters can then be
 	// accessed as variables in an aggregate expression context (e.g. "$$var").
 	Let any
+	// ReadPreference Determines which members of the replica set can be used by the operation. The default value is
+	// the global option, and if it is nil, the client read preference.
+	ReadPreference *ReadPreference
+	// ReadConcern The consistency and isolation properties of the data read by the operation. The default value is
+	// the global option, and if it is empty, the client read concern.
+	ReadConcern ReadConcern
+	// CausalConsistency If true, the operation runs in a causally consistent session after the last write of the
+	// template, so it sees that write, even on a secondary. If the template has an open session, the operation runs
+	// on it. The default value is the global option.
+	CausalConsistency *bool
 }
 
 // FindPageable represents options that can be used to configure a 'FindPageable' operation.
@@ -136,6 +146,16 @@ type FindPageable struct {
 	// Values must be constant or closed expressions that do not reference document fields. Parameters can then be
 	// accessed as variables in an aggregate expression context (e.g. "$$var").
 	Let any
+	// ReadPreference Determines which members of the replica set can be used by the operation. The default value is
+	// the global option, and if it is nil, the client read preference.
+	ReadPreference *ReadPreference
+	// ReadConcern The consistency and isolation properties of the data read by the operation. The default value is
+	// the global option, and if it is empty, the client read concern.
+	ReadConcern ReadConcern
+	// CausalConsistency If true, the operation runs in a causally consistent session after the last write of the
+	// template, so it sees that write, even on a secondary. If the template has an open session, the operation runs
+	// on it. The default value is the global option.
+	CausalConsistency *bool
 }
 
 // FindOne represents options that can be used to configure a FindOne operation.
@@ -181,6 +201,21 @@ type FindOne struct {
 	// Sort A document specifying the sort order to apply to the query. The first document in the sorted order will be
 	// returned. The driver will return an error if the sort parameter is a multi-key map.
 	Sort any
+	// DisableCache If true, the operation does not read through the cache configured by mongo.Template.SetCache, so
+	// the document is always searched on the server and is not stored on the cache.
+	//
+	// default: false
+	DisableCache *bool
+	// ReadPreference Determines which members of the replica set can be used by the operation. The default value is
+	// the global option, and if it is nil, the client read preference.
+	ReadPreference *ReadPreference
+	// ReadConcern The consistency and isolation properties of the data read by the operation. The default value is
+	// the global option, and if it is empty, the client read concern.
+	ReadConcern ReadConcern
+	// CausalConsistency If true, the operation runs in a causally consistent session after the last write of the
+	// template, so it sees that write, even on a secondary. If the template has an open session, the operation runs
+	// on it. The default value is the global option.
+	CausalConsistency *bool
 }
 
 // FindOneById represents options that can be used to configure a 'FindOneById' operation.
@@ -221,6 +256,21 @@ type FindOneById struct {
 	// If true, a $recordId field with a record identifier will be included in the document returned by the operation.
 	// The default value is false.
 	ShowRecordID *bool
+	// DisableCache If true, the operation does not read through the cache configured by mongo.Template.SetCache, so
+	// the document is always searched on the server and is not stored on the cache.
+	//
+	// default: false
+	DisableCache *bool
+	// ReadPreference Determines which members of the replica set can be used by the operation. The default value is
+	// the global option, and if it is nil, the client read preference.
+	ReadPreference *ReadPreference
+	// ReadConcern The consistency and isolation properties of the data read by the operation. The default value is
+	// the global option, and if it is empty, the client read concern.
+	ReadConcern ReadConcern
+	// CausalConsistency If true, the operation runs in a causally consistent session after the last write of the
+	// template, so it sees that write, even on a secondary. If the template has an open session, the operation runs
+	// on it. The default value is the global option.
+	CausalConsistency *bool
 }
 
 // FindOneAndDelete represents options that can be used to configure a FindOneAndDelete operation.
@@ -268,6 +318,15 @@ type FindOneAndDelete struct {
 	// committing the transactions, and continue creating a new session.
 	// default is false.
 	ForceRecreateSession *bool
+	// WriteConcern The acknowledgment requested from the server for the transaction of the template session started by
+	// the operation. It only applies when the session is created, since the write concern of a transaction is set when
+	// it starts, so it is ignored if the session is already open, e.g. after StartSession or a previous operation with
+	// DisableAutoCloseSession. The default value is the global option, and if it is nil, the client write concern.
+	WriteConcern *WriteConcern
+	// CausalConsistency If set, configures the causal consistency of the session started by the operation, it is
+	// ignored if the session is already open. The default value is the global option, and if it is false, the driver
+	// default, which is true.
+	CausalConsistency *bool
 }
 
 // FindOneAndReplace represents options that can be used to configure a FindOneAndReplace operation.
@@ -326,6 +385,15 @@ type FindOneAndReplace struct {
 	// committing the transactions, and continue creating a new session.
 	// default is false.
 	ForceRecreateSession *bool
+	// WriteConcern The acknowledgment requested from the server for the transaction of the template session started by
+	// the operation. It only applies when the session is created, since the write concern of a transaction is set when
+	// it starts, so it is ignored if the session is already open, e.g. after StartSession or a previous operation with
+	// DisableAutoCloseSession. The default value is the global option, and if it is nil, the client write concern.
+	WriteConcern *WriteConcern
+	// CausalConsistency If set, configures the causal consistency of the session started by the operation, it is
+	// ignored if the session is already open. The default value is the global option, and if it is false, the driver
+	// default, which is true.
+	CausalConsistency *bool
 }
 
 // FindOneAndUpdate represents options that can be used to configure a FindOneAndUpdate operation.
@@ -388,6 +456,15 @@ type FindOneAndUpdate struct {
 	// committing the transactions, and continue creating a new session.
 	// default is false
 	ForceRecreateSession *bool
+	// WriteConcern The acknowledgment requested from the server for the transaction of the template session started by
+	// the operation. It only applies when the session is created, since the write concern of a transaction is set when
+	// it starts, so it is ignored if the session is already open, e.g. after StartSession or a previous operation with
+	// DisableAutoCloseSession. The default value is the global option, and if it is nil, the client write concern.
+	WriteConcern *WriteConcern
+	// CausalConsistency If set, configures the causal consistency of the session started by the operation, it is
+	// ignored if the session is already open. The default value is the global option, and if it is false, the driver
+	// default, which is true.
+	CausalConsistency *bool
 }
 
 // NewFind creates a new Find instance.
@@ -539,6 +616,24 @@ func (f *Find) SetLet(v any) *Find {
 	return f
 }
 
+// SetReadPreference sets value for the ReadPreference field.
+func (f *Find) SetReadPreference(r *ReadPreference) *Find {
+	f.ReadPreference = r
+	return f
+}
+
+// SetReadConcern sets value for the ReadConcern field.
+func (f *Find) SetReadConcern(r ReadConcern) *Find {
+	f.ReadConcern = r
+	return f
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (f *Find) SetCausalConsistency(b bool) *Find {
+	f.CausalConsistency = &b
+	return f
+}
+
 // SetAllowDiskUse creates a new AllowDiskUse instance.
 func (f *FindPageable) SetAllowDiskUse(b bool) *FindPageable {
 	f.AllowDiskUse = &b
@@ -635,6 +730,24 @@ func (f *FindPageable) SetLet(v any) *FindPageable {
 	return f
 }
 
+// SetReadPreference sets value for the ReadPreference field.
+func (f *FindPageable) SetReadPreference(r *ReadPreference) *FindPageable {
+	f.ReadPreference = r
+	return f
+}
+
+// SetReadConcern sets value for the ReadConcern field.
+func (f *FindPageable) SetReadConcern(r ReadConcern) *FindPageable {
+	f.ReadConcern = r
+	return f
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (f *FindPageable) SetCausalConsistency(b bool) *FindPageable {
+	f.CausalConsistency = &b
+	return f
+}
+
 // SetAllowPartialResults creates a new AllowPartialResults instance.
 func (f *FindOne) SetAllowPartialResults(b bool) *FindOne {
 	f.AllowPartialResults = &b
@@ -707,6 +820,30 @@ func (f *FindOne) SetSort(a any) *FindOne {
 	return f
 }
 
+// SetDisableCache sets value for the DisableCache field.
+func (f *FindOne) SetDisableCache(b bool) *FindOne {
+	f.DisableCache = &b
+	return f
+}
+
+// SetReadPreference sets value for the ReadPreference field.
+func (f *FindOne) SetReadPreference(r *ReadPreference) *FindOne {
+	f.ReadPreference = r
+	return f
+}
+
+// SetReadConcern sets value for the ReadConcern field.
+func (f *FindOne) SetReadConcern(r ReadConcern) *FindOne {
+	f.ReadConcern = r
+	return f
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (f *FindOne) SetCausalConsistency(b bool) *FindOne {
+	f.CausalConsistency = &b
+	return f
+}
+
 // SetAllowPartialResults creates a new AllowPartialResults instance.
 func (f *FindOneById) SetAllowPartialResults(b bool) *FindOneById {
 	f.AllowPartialResults = &b
@@ -767,6 +904,30 @@ func (f *FindOneById) SetShowRecordID(b bool) *FindOneById {
 	return f
 }
 
+// SetDisableCache sets value for the DisableCache field.
+func (f *FindOneById) SetDisableCache(b bool) *FindOneById {
+	f.DisableCache = &b
+	return f
+}
+
+// SetReadPreference sets value for the ReadPreference field.
+func (f *FindOneById) SetReadPreference(r *ReadPreference) *FindOneById {
+	f.ReadPreference = r
+	return f
+}
+
+// SetReadConcern sets value for the ReadConcern field.
+func (f *FindOneById) SetReadConcern(r ReadConcern) *FindOneById {
+	f.ReadConcern = r
+	return f
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (f *FindOneById) SetCausalConsistency(b bool) *FindOneById {
+	f.CausalConsistency = &b
+	return f
+}
+
 // SetCollation creates a new Collation instance.
 func (f *FindOneAndDelete) SetCollation(c *Collation) *FindOneAndDelete {
 	f.Collation = c
@@ -827,6 +988,18 @@ func (f *FindOneAndDelete) SetForceRecreateSession(b bool) *FindOneAndDelete {
 	return f
 }
 
+// SetWriteConcern sets value for the WriteConcern field.
+func (f *FindOneAndDelete) SetWriteConcern(w *WriteConcern) *FindOneAndDelete {
+	f.WriteConcern = w
+	return f
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (f *FindOneAndDelete) SetCausalConsistency(b bool) *FindOneAndDelete {
+	f.CausalConsistency = &b
+	return f
+}
+
 // SetDisableAutoRollbackSession creates a new DisableAutoCloseSession instance.
 func (f *FindOneAndReplace) SetDisableAutoRollbackSession(b bool) *FindOneAndReplace {
 	f.DisableAutoRollbackSession = &b
@@ -905,6 +1078,18 @@ func (f *FindOneAndReplace) SetSort(a any) *FindOneAndReplace {
 	return f
 }
 
+// SetWriteConcern sets value for the WriteConcern field.
+func (f *FindOneAndReplace) SetWriteConcern(w *WriteConcern) *FindOneAndReplace {
+	f.WriteConcern = w
+	return f
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (f *FindOneAndReplace) SetCausalConsistency(b bool) *FindOneAndReplace {
+	f.CausalConsistency = &b
+	return f
+}
+
 // SetDisableAutoRollbackSession creates a new DisableAutoRollbackSession instance.
 func (f *FindOneAndUpdate) SetDisableAutoRollbackSession(b bool) *FindOneAndUpdate {
 	f.DisableAutoRollbackSession = &b
@@ -988,6 +1173,18 @@ func (f *FindOneAndUpdate) SetUpsert(b bool) *FindOneAndUpdate {
 	return f
 }
 
+// SetWriteConcern sets value for the WriteConcern field.
+func (f *FindOneAndUpdate) SetWriteConcern(w *WriteConcern) *FindOneAndUpdate {
+	f.WriteConcern = w
+	return f
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (f *FindOneAndUpdate) SetCausalConsistency(b bool) *FindOneAndUpdate {
+	f.CausalConsistency = &b
+	return f
+}
+
 // MergeFindByParams assembles the Find object from optional parameters.
 func MergeFindByParams(opts []*Find) *Find {
 	result := &Find{}
@@ -1052,6 +1249,15 @@ func MergeFindByParams(opts []*Find) *Find {
 		if helper.IsNotNil(opt.MaxAwaitTime) {
 			result.MaxAwaitTime = opt.MaxAwaitTime
 		}
+		if helper.IsNotNil(opt.ReadPreference) {
+			result.ReadPreference = opt.ReadPreference
+		}
+		if helper.IsNotEmpty(opt.ReadConcern) {
+			result.ReadConcern = opt.ReadConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
 	}
 	return result
 }
@@ -1111,6 +1317,15 @@ func MergeFindPageableByParams(opts []*FindPageable) *FindPageable {
 		if helper.IsNotNil(opt.MaxAwaitTime) {
 			result.MaxAwaitTime = opt.MaxAwaitTime
 		}
+		if helper.IsNotNil(opt.ReadPreference) {
+			result.ReadPreference = opt.ReadPreference
+		}
+		if helper.IsNotEmpty(opt.ReadConcern) {
+			result.ReadConcern = opt.ReadConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
 	}
 	return result
 }
@@ -1158,6 +1373,21 @@ func MergeFindOneByParams(opts []*FindOne) *FindOne {
 		if helper.IsNotNil(opt.MaxTime) {
 			result.MaxTime = opt.MaxTime
 		}
+		if helper.IsNotNil(opt.DisableCache) {
+			result.DisableCache = opt.DisableCache
+		}
+		if helper.IsNotNil(opt.ReadPreference) {
+			result.ReadPreference = opt.ReadPreference
+		}
+		if helper.IsNotEmpty(opt.ReadConcern) {
+			result.ReadConcern = opt.ReadConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
+	}
+	if helper.IsNil(result.DisableCache) {
+		result.DisableCache = helper.ConvertToPointer(false)
 	}
 	return result
 }
@@ -1199,6 +1429,21 @@ func MergeFindOneByIdByParams(opts []*FindOneById) *FindOneById {
 		if helper.IsNotNil(opt.MaxTime) {
 			result.MaxTime = opt.MaxTime
 		}
+		if helper.IsNotNil(opt.DisableCache) {
+			result.DisableCache = opt.DisableCache
+		}
+		if helper.IsNotNil(opt.ReadPreference) {
+			result.ReadPreference = opt.ReadPreference
+		}
+		if helper.IsNotEmpty(opt.ReadConcern) {
+			result.ReadConcern = opt.ReadConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
+	}
+	if helper.IsNil(result.DisableCache) {
+		result.DisableCache = helper.ConvertToPointer(false)
 	}
 	return result
 }
@@ -1240,6 +1485,12 @@ func MergeFindOneAndDeleteByParams(opts []*FindOneAndDelete, global *Global) *Fi
 		if helper.IsNotNil(opt.MaxTime) {
 			result.MaxTime = opt.MaxTime
 		}
+		if helper.IsNotNil(opt.WriteConcern) {
+			result.WriteConcern = opt.WriteConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
 	}
 	if helper.IsNil(result.Comment) {
 		result.Comment = global.Comment
@@ -1253,6 +1504,12 @@ func MergeFindOneAndDeleteByParams(opts []*FindOneAndDelete, global *Global) *Fi
 	if helper.IsNil(result.ForceRecreateSession) {
 		result.ForceRecreateSession = helper.ConvertToPointer(global.ForceRecreateSession)
 	}
+	if helper.IsNil(result.WriteConcern) {
+		result.WriteConcern = global.WriteConcern
+	}
+	if helper.IsNil(result.CausalConsistency) && global.CausalConsistency {
+		result.CausalConsistency = helper.ConvertToPointer(true)
+	}
 	return result
 }
 
@@ -1302,6 +1559,12 @@ func MergeFindOneAndReplaceByParams(opts []*FindOneAndReplace, global *Global) *
 		if helper.IsNotNil(opt.ReturnDocument) {
 			result.ReturnDocument = opt.ReturnDocument
 		}
+		if helper.IsNotNil(opt.WriteConcern) {
+			result.WriteConcern = opt.WriteConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
 	}
 	if helper.IsNil(result.BypassDocumentValidation) {
 		result.BypassDocumentValidation = helper.ConvertToPointer(global.BypassDocumentValidation)
@@ -1318,6 +1581,12 @@ func MergeFindOneAndReplaceByParams(opts []*FindOneAndReplace, global *Global) *
 	if helper.IsNil(result.ForceRecreateSession) {
 		result.ForceRecreateSession = helper.ConvertToPointer(global.ForceRecreateSession)
 	}
+	if helper.IsNil(result.WriteConcern) {
+		result.WriteConcern = global.WriteConcern
+	}
+	if helper.IsNil(result.CausalConsistency) && global.CausalConsistency {
+		result.CausalConsistency = helper.ConvertToPointer(true)
+	}
 	return result
 }
 
@@ -1370,6 +1639,12 @@ func MergeFindOneAndUpdateByParams(opts []*FindOneAndUpdate, global *Global) *Fi
 		if helper.IsNotNil(opt.ReturnDocument) {
 			result.ReturnDocument = opt.ReturnDocument
 		}
+		if helper.IsNotNil(opt.WriteConcern) {
+			result.WriteConcern = opt.WriteConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
 	}
 	if helper.IsNil(result.BypassDocumentValidation) {
 		result.BypassDocumentValidation = helper.ConvertToPointer(global.BypassDocumentValidation)
@@ -1386,5 +1661,11 @@ func MergeFindOneAndUpdateByParams(opts []*FindOneAndUpdate, global *Global) *Fi
 	if helper.IsNil(result.ForceRecreateSession) {
 		result.ForceRecreateSession = helper.ConvertToPointer(global.ForceRecreateSession)
 	}
+	if helper.IsNil(result.WriteConcern) {
+		result.WriteConcern = global.WriteConcern
+	}
+	if helper.IsNil(result.CausalConsistency) && global.CausalConsistency {
+		result.CausalConsistency = helper.ConvertToPointer(true)
+	}
 	return result
 }
@@ -1,5 +1,7 @@
 package option
 
+import "time"
+
 // Global represents options that can be used for all operations as a default form, it is important to highlight that it
 // will not overwrite operation options.
 type Global struct {
@@ -21,4 +23,35 @@ type Global struct {
 	// aborting all open transactions, and continue creating a new session.
 	// default is false
 	ForceRecreateSession bool
+	// ValidateDocument If true, the documents of the InsertOne, InsertMany, ReplaceOne and FindOneAndReplace operations
+	// are validated by the validate tags of their structure before being sent to the server, if the validation fails,
+	// the transaction is aborted and a ValidationError is returned.
+	// See https://github.com/go-playground/validator for more information about the validate tags.
+	// default is false
+	ValidateDocument bool
+	// ReadPreference Determines which members of the replica set can be used by the read operations, see the
+	// ReadPreference documentation.
+	// default is nil, which means the client read preference is used
+	ReadPreference *ReadPreference
+	// ReadConcern The consistency and isolation properties of the data read by the read operations.
+	// default is empty, which means the client read concern is used
+	ReadConcern ReadConcern
+	// WriteConcern The acknowledgment requested from the server for the transactions of the template sessions, see the
+	// WriteConcern documentation.
+	// default is nil, which means the client write concern is used
+	WriteConcern *WriteConcern
+	// CausalConsistency If true, the read operations run in a causally consistent session after the last write of the
+	// template, so they see that write, even on secondaries.
+	// default is false
+	CausalConsistency bool
+	// SlowQueryThreshold If greater than zero, the Find, FindOne, FindPageable, Aggregate, CountDocuments and update
+	// operations that take longer are logged as slow queries, with the namespace, the sanitized filter, where the
+	// values are replaced by "?", the sort and the duration.
+	// default is 0, which means the slow queries are not logged
+	SlowQueryThreshold time.Duration
+	// SlowQueryExplain If true, the slow queries are explained with the "executionStats" verbosity, and the winning
+	// plan and the docs-examined ratio are attached to the slow query log. At most 4 explains run at the same time,
+	// the slow queries found while they run are logged without the explain.
+	// default is false
+	SlowQueryExplain bool
 }
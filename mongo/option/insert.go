@@ -22,6 +22,15 @@ type InsertOne struct {
 	// aborting all open transactions, and continue creating a new session.
 	// default is false
 	ForceRecreateSession *bool
+	// WriteConcern The acknowledgment requested from the server for the transaction of the template session started by
+	// the operation. It only applies when the session is created, since the write concern of a transaction is set when
+	// it starts, so it is ignored if the session is already open, e.g. after StartSession or a previous operation with
+	// DisableAutoCloseSession. The default value is the global option, and if it is nil, the client write concern.
+	WriteConcern *WriteConcern
+	// CausalConsistency If set, configures the causal consistency of the session started by the operation, it is
+	// ignored if the session is already open. The default value is the global option, and if it is false, the driver
+	// default, which is true.
+	CausalConsistency *bool
 }
 
 // InsertMany represents options that can be used to configure a 'InsertMany' operation.
@@ -44,6 +53,15 @@ type InsertMany struct {
 	// committing the transactions, and continue creating a new session.
 	// default is false
 	ForceRecreateSession *bool
+	// WriteConcern The acknowledgment requested from the server for the transaction of the template session started by
+	// the operation. It only applies when the session is created, since the write concern of a transaction is set when
+	// it starts, so it is ignored if the session is already open, e.g. after StartSession or a previous operation with
+	// DisableAutoCloseSession. The default value is the global option, and if it is nil, the client write concern.
+	WriteConcern *WriteConcern
+	// CausalConsistency If set, configures the causal consistency of the session started by the operation, it is
+	// ignored if the session is already open. The default value is the global option, and if it is false, the driver
+	// default, which is true.
+	CausalConsistency *bool
 }
 
 // NewInsertOne creates a new InsertOne instance.
@@ -86,6 +104,18 @@ func (i *InsertOne) SetForceRecreateSession(b bool) *InsertOne {
 	return i
 }
 
+// SetWriteConcern sets value for the WriteConcern field.
+func (i *InsertOne) SetWriteConcern(w *WriteConcern) *InsertOne {
+	i.WriteConcern = w
+	return i
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (i *InsertOne) SetCausalConsistency(b bool) *InsertOne {
+	i.CausalConsistency = &b
+	return i
+}
+
 // SetBypassDocumentValidation sets value for the BypassDocumentValidation field.
 func (i *InsertMany) SetBypassDocumentValidation(b bool) *InsertMany {
 	i.BypassDocumentValidation = &b
@@ -116,6 +146,18 @@ func (i *InsertMany) SetForceRecreateSession(b bool) *InsertMany {
 	return i
 }
 
+// SetWriteConcern sets value for the WriteConcern field.
+func (i *InsertMany) SetWriteConcern(w *WriteConcern) *InsertMany {
+	i.WriteConcern = w
+	return i
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (i *InsertMany) SetCausalConsistency(b bool) *InsertMany {
+	i.CausalConsistency = &b
+	return i
+}
+
 // MergeInsertOneByParams assembles the InsertOne object from optional parameters.
 func MergeInsertOneByParams(opts []*InsertOne, global *Global) *InsertOne {
 	result := &InsertOne{}
@@ -135,6 +177,12 @@ func MergeInsertOneByParams(opts []*InsertOne, global *Global) *InsertOne {
 		if helper.IsNotNil(opt.ForceRecreateSession) {
 			result.ForceRecreateSession = opt.ForceRecreateSession
 		}
+		if helper.IsNotNil(opt.WriteConcern) {
+			result.WriteConcern = opt.WriteConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
 	}
 	if helper.IsNil(result.BypassDocumentValidation) {
 		result.BypassDocumentValidation = helper.ConvertToPointer(global.BypassDocumentValidation)
@@ -151,6 +199,12 @@ func MergeInsertOneByParams(opts []*InsertOne, global *Global) *InsertOne {
 	if helper.IsNil(result.ForceRecreateSession) {
 		result.ForceRecreateSession = helper.ConvertToPointer(global.ForceRecreateSession)
 	}
+	if helper.IsNil(result.WriteConcern) {
+		result.WriteConcern = global.WriteConcern
+	}
+	if helper.IsNil(result.CausalConsistency) && global.CausalConsistency {
+		result.CausalConsistency = helper.ConvertToPointer(true)
+	}
 	return result
 }
 
@@ -176,6 +230,12 @@ func MergeInsertManyByParams(opts []*InsertMany, global *Global) *InsertMany {
 		if helper.IsNotNil(opt.DisableAutoCloseSession) {
 			result.DisableAutoCloseSession = opt.DisableAutoCloseSession
 		}
+		if helper.IsNotNil(opt.WriteConcern) {
+			result.WriteConcern = opt.WriteConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
 	}
 	if helper.IsNil(result.BypassDocumentValidation) {
 		result.BypassDocumentValidation = helper.ConvertToPointer(global.BypassDocumentValidation)
@@ -192,5 +252,11 @@ func MergeInsertManyByParams(opts []*InsertMany, global *Global) *InsertMany {
 	if helper.IsNil(result.ForceRecreateSession) {
 		result.ForceRecreateSession = helper.ConvertToPointer(global.ForceRecreateSession)
 	}
+	if helper.IsNil(result.WriteConcern) {
+		result.WriteConcern = global.WriteConcern
+	}
+	if helper.IsNil(result.CausalConsistency) && global.CausalConsistency {
+		result.CausalConsistency = helper.ConvertToPointer(true)
+	}
 	return result
 }
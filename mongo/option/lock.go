@@ -0,0 +1,106 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"time"
+)
+
+// Lock represents options that can be used to configure a mongo.Lock.
+type Lock struct {
+	// DatabaseName database name where the lock documents are stored (required)
+	DatabaseName string
+	// CollectionName collection name where the lock documents are stored, it receives a TTL index on the expiresAt
+	// field, so the expired locks are removed by the server.
+	//
+	// default: _locks
+	CollectionName string
+	// RetryInterval Delay to try again to acquire the lock on the mongo.Lock.Acquire operation when it is held by
+	// another holder.
+	//
+	// default: 1 second
+	RetryInterval time.Duration
+	// RefreshInterval Interval that the background goroutine refreshes the lock after acquired, it must be smaller than
+	// the lock ttl.
+	//
+	// default: a third of the lock ttl
+	RefreshInterval time.Duration
+	// DisableAutoRefresh If true, the lock is not refreshed automatically after acquired, so the holder needs to call
+	// mongo.Lock.Refresh before the ttl expires.
+	//
+	// default: false
+	DisableAutoRefresh *bool
+}
+
+// NewLock creates a new Lock instance.
+func NewLock() *Lock {
+	return &Lock{}
+}
+
+// SetDatabaseName sets value for the DatabaseName field.
+func (l *Lock) SetDatabaseName(s string) *Lock {
+	l.DatabaseName = s
+	return l
+}
+
+// SetCollectionName sets value for the CollectionName field.
+func (l *Lock) SetCollectionName(s string) *Lock {
+	l.CollectionName = s
+	return l
+}
+
+// SetRetryInterval sets value for the RetryInterval field.
+func (l *Lock) SetRetryInterval(d time.Duration) *Lock {
+	l.RetryInterval = d
+	return l
+}
+
+// SetRefreshInterval sets value for the RefreshInterval field.
+func (l *Lock) SetRefreshInterval(d time.Duration) *Lock {
+	l.RefreshInterval = d
+	return l
+}
+
+// SetDisableAutoRefresh sets value for the DisableAutoRefresh field.
+func (l *Lock) SetDisableAutoRefresh(b bool) *Lock {
+	l.DisableAutoRefresh = &b
+	return l
+}
+
+// MergeLockByParams assembles the Lock object from optional parameters, the ttl parameter is used to calculate the
+// default RefreshInterval.
+func MergeLockByParams(opts []*Lock, ttl time.Duration) *Lock {
+	result := &Lock{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotEmpty(opt.DatabaseName) {
+			result.DatabaseName = opt.DatabaseName
+		}
+		if helper.IsNotEmpty(opt.CollectionName) {
+			result.CollectionName = opt.CollectionName
+		}
+		if helper.IsGreaterThan(opt.RetryInterval, 0) {
+			result.RetryInterval = opt.RetryInterval
+		}
+		if helper.IsGreaterThan(opt.RefreshInterval, 0) {
+			result.RefreshInterval = opt.RefreshInterval
+		}
+		if helper.IsNotNil(opt.DisableAutoRefresh) {
+			result.DisableAutoRefresh = opt.DisableAutoRefresh
+		}
+	}
+	if helper.IsEmpty(result.CollectionName) {
+		result.CollectionName = "_locks"
+	}
+	if helper.IsLessThanOrEqual(result.RetryInterval, 0) {
+		result.RetryInterval = time.Second
+	}
+	if helper.IsLessThanOrEqual(result.RefreshInterval, 0) {
+		result.RefreshInterval = ttl / 3
+	}
+	if helper.IsNil(result.DisableAutoRefresh) {
+		result.DisableAutoRefresh = helper.ConvertToPointer(false)
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"time"
+)
+
+// Migrate represents options that can be used to configure a migrate.Migrator.
+type Migrate struct {
+	// DatabaseName database name where the applied migrations and the lock document are stored (required)
+	DatabaseName string
+	// CollectionName collection name where the applied migrations and the lock document are stored
+	//
+	// default: _migrations
+	CollectionName string
+	// LockTTL Duration that the lock document is valid without being refreshed, while the migrations are running the
+	// lock is refreshed automatically, so it only expires if the holder is gone.
+	//
+	// default: 1 minute
+	LockTTL time.Duration
+	// LockRetryInterval Delay to try again to acquire the lock when it is held by another process.
+	//
+	// default: 1 second
+	LockRetryInterval time.Duration
+	// DisableTransaction If true, the migrations are not run inside a Template transaction, even if the deployment
+	// supports them.
+	//
+	// default: false
+	DisableTransaction *bool
+}
+
+// NewMigrate creates a new Migrate instance.
+func NewMigrate() *Migrate {
+	return &Migrate{}
+}
+
+// SetDatabaseName sets value for the DatabaseName field.
+func (m *Migrate) SetDatabaseName(s string) *Migrate {
+	m.DatabaseName = s
+	return m
+}
+
+// SetCollectionName sets value for the CollectionName field.
+func (m *Migrate) SetCollectionName(s string) *Migrate {
+	m.CollectionName = s
+	return m
+}
+
+// SetLockTTL sets value for the LockTTL field.
+func (m *Migrate) SetLockTTL(d time.Duration) *Migrate {
+	m.LockTTL = d
+	return m
+}
+
+// SetLockRetryInterval sets value for the LockRetryInterval field.
+func (m *Migrate) SetLockRetryInterval(d time.Duration) *Migrate {
+	m.LockRetryInterval = d
+	return m
+}
+
+// SetDisableTransaction sets value for the DisableTransaction field.
+func (m *Migrate) SetDisableTransaction(b bool) *Migrate {
+	m.DisableTransaction = &b
+	return m
+}
+
+// MergeMigrateByParams assembles the Migrate object from optional parameters.
+func MergeMigrateByParams(opts []*Migrate) *Migrate {
+	result := &Migrate{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotEmpty(opt.DatabaseName) {
+			result.DatabaseName = opt.DatabaseName
+		}
+		if helper.IsNotEmpty(opt.CollectionName) {
+			result.CollectionName = opt.CollectionName
+		}
+		if helper.IsGreaterThan(opt.LockTTL, 0) {
+			result.LockTTL = opt.LockTTL
+		}
+		if helper.IsGreaterThan(opt.LockRetryInterval, 0) {
+			result.LockRetryInterval = opt.LockRetryInterval
+		}
+		if helper.IsNotNil(opt.DisableTransaction) {
+			result.DisableTransaction = opt.DisableTransaction
+		}
+	}
+	if helper.IsEmpty(result.CollectionName) {
+		result.CollectionName = "_migrations"
+	}
+	if helper.IsLessThanOrEqual(result.LockTTL, 0) {
+		result.LockTTL = time.Minute
+	}
+	if helper.IsLessThanOrEqual(result.LockRetryInterval, 0) {
+		result.LockRetryInterval = time.Second
+	}
+	if helper.IsNil(result.DisableTransaction) {
+		result.DisableTransaction = helper.ConvertToPointer(false)
+	}
+	return result
+}
@@ -0,0 +1,237 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"time"
+)
+
+// Outbox represents options that can be used to configure a mongo.Outbox.
+type Outbox struct {
+	// DatabaseName database name where the outbox messages are stored (required)
+	DatabaseName string
+	// CollectionName collection name where the outbox messages are stored
+	//
+	// default: _outbox
+	CollectionName string
+	// DeadLetterCollectionName collection name where the messages that reached the OutboxRelay.MaxAttempts are moved
+	//
+	// default: CollectionName + "_dead_letter"
+	DeadLetterCollectionName string
+	// DispatchedRetention Duration that the dispatched messages are kept on the collection, they are removed by the
+	// server through a TTL index on the dispatchedAt field, created on the first 'Relay' operation.
+	//
+	// default: 7 days
+	DispatchedRetention time.Duration
+}
+
+// OutboxRelay represents options that can be used to configure a 'Relay' operation of the mongo.Outbox.
+type OutboxRelay struct {
+	// PollInterval Interval to search for pending messages, it is used to retry the failed messages and as fallback
+	// when the deployment does not support change streams.
+	//
+	// default: 5 seconds
+	PollInterval time.Duration
+	// BatchSize The maximum number of pending messages dispatched on each poll.
+	//
+	// default: 100
+	BatchSize int64
+	// PublishTimeout Duration time to process the publisher func, timeout applied in the past context.
+	//
+	// default: 5 seconds
+	PublishTimeout time.Duration
+	// ClaimTimeout Duration that a message claimed by a relay is not dispatched by another one, if the relay stops
+	// while publishing, the message is dispatched again after this duration.
+	//
+	// default: 1 minute
+	ClaimTimeout time.Duration
+	// MaxAttempts Maximum number of publish attempts of a message, when it is reached the message is moved to the dead
+	// letter collection.
+	//
+	// default: 5
+	MaxAttempts int
+	// InitialBackoff Delay to retry the first failed publish attempt, it is doubled on each next attempt.
+	//
+	// default: 1 second
+	InitialBackoff time.Duration
+	// MaxBackoff Maximum delay between the publish attempts.
+	//
+	// default: 1 minute
+	MaxBackoff time.Duration
+	// DisableWatch If true, the relay only polls the pending messages, without opening a change stream.
+	//
+	// default: false
+	DisableWatch *bool
+}
+
+// NewOutbox creates a new Outbox instance.
+func NewOutbox() *Outbox {
+	return &Outbox{}
+}
+
+// NewOutboxRelay creates a new OutboxRelay instance.
+func NewOutboxRelay() *OutboxRelay {
+	return &OutboxRelay{}
+}
+
+// SetDatabaseName sets value for the DatabaseName field.
+func (o *Outbox) SetDatabaseName(s string) *Outbox {
+	o.DatabaseName = s
+	return o
+}
+
+// SetCollectionName sets value for the CollectionName field.
+func (o *Outbox) SetCollectionName(s string) *Outbox {
+	o.CollectionName = s
+	return o
+}
+
+// SetDeadLetterCollectionName sets value for the DeadLetterCollectionName field.
+func (o *Outbox) SetDeadLetterCollectionName(s string) *Outbox {
+	o.DeadLetterCollectionName = s
+	return o
+}
+
+// SetDispatchedRetention sets value for the DispatchedRetention field.
+func (o *Outbox) SetDispatchedRetention(d time.Duration) *Outbox {
+	o.DispatchedRetention = d
+	return o
+}
+
+// SetPollInterval sets value for the PollInterval field.
+func (o *OutboxRelay) SetPollInterval(d time.Duration) *OutboxRelay {
+	o.PollInterval = d
+	return o
+}
+
+// SetBatchSize sets value for the BatchSize field.
+func (o *OutboxRelay) SetBatchSize(i int64) *OutboxRelay {
+	o.BatchSize = i
+	return o
+}
+
+// SetPublishTimeout sets value for the PublishTimeout field.
+func (o *OutboxRelay) SetPublishTimeout(d time.Duration) *OutboxRelay {
+	o.PublishTimeout = d
+	return o
+}
+
+// SetClaimTimeout sets value for the ClaimTimeout field.
+func (o *OutboxRelay) SetClaimTimeout(d time.Duration) *OutboxRelay {
+	o.ClaimTimeout = d
+	return o
+}
+
+// SetMaxAttempts sets value for the MaxAttempts field.
+func (o *OutboxRelay) SetMaxAttempts(i int) *OutboxRelay {
+	o.MaxAttempts = i
+	return o
+}
+
+// SetInitialBackoff sets value for the InitialBackoff field.
+func (o *OutboxRelay) SetInitialBackoff(d time.Duration) *OutboxRelay {
+	o.InitialBackoff = d
+	return o
+}
+
+// SetMaxBackoff sets value for the MaxBackoff field.
+func (o *OutboxRelay) SetMaxBackoff(d time.Duration) *OutboxRelay {
+	o.MaxBackoff = d
+	return o
+}
+
+// SetDisableWatch sets value for the DisableWatch field.
+func (o *OutboxRelay) SetDisableWatch(b bool) *OutboxRelay {
+	o.DisableWatch = &b
+	return o
+}
+
+// MergeOutboxByParams assembles the Outbox object from optional parameters.
+func MergeOutboxByParams(opts []*Outbox) *Outbox {
+	result := &Outbox{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotEmpty(opt.DatabaseName) {
+			result.DatabaseName = opt.DatabaseName
+		}
+		if helper.IsNotEmpty(opt.CollectionName) {
+			result.CollectionName = opt.CollectionName
+		}
+		if helper.IsNotEmpty(opt.DeadLetterCollectionName) {
+			result.DeadLetterCollectionName = opt.DeadLetterCollectionName
+		}
+		if helper.IsGreaterThan(opt.DispatchedRetention, 0) {
+			result.DispatchedRetention = opt.DispatchedRetention
+		}
+	}
+	if helper.IsEmpty(result.CollectionName) {
+		result.CollectionName = "_outbox"
+	}
+	if helper.IsEmpty(result.DeadLetterCollectionName) {
+		result.DeadLetterCollectionName = result.CollectionName + "_dead_letter"
+	}
+	if helper.IsLessThanOrEqual(result.DispatchedRetention, 0) {
+		result.DispatchedRetention = 7 * 24 * time.Hour
+	}
+	return result
+}
+
+// MergeOutboxRelayByParams assembles the OutboxRelay object from optional parameters.
+func MergeOutboxRelayByParams(opts []*OutboxRelay) *OutboxRelay {
+	result := &OutboxRelay{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsGreaterThan(opt.PollInterval, 0) {
+			result.PollInterval = opt.PollInterval
+		}
+		if helper.IsGreaterThan(opt.BatchSize, 0) {
+			result.BatchSize = opt.BatchSize
+		}
+		if helper.IsGreaterThan(opt.PublishTimeout, 0) {
+			result.PublishTimeout = opt.PublishTimeout
+		}
+		if helper.IsGreaterThan(opt.ClaimTimeout, 0) {
+			result.ClaimTimeout = opt.ClaimTimeout
+		}
+		if helper.IsGreaterThan(opt.MaxAttempts, 0) {
+			result.MaxAttempts = opt.MaxAttempts
+		}
+		if helper.IsGreaterThan(opt.InitialBackoff, 0) {
+			result.InitialBackoff = opt.InitialBackoff
+		}
+		if helper.IsGreaterThan(opt.MaxBackoff, 0) {
+			result.MaxBackoff = opt.MaxBackoff
+		}
+		if helper.IsNotNil(opt.DisableWatch) {
+			result.DisableWatch = opt.DisableWatch
+		}
+	}
+	if helper.IsLessThanOrEqual(result.PollInterval, 0) {
+		result.PollInterval = 5 * time.Second
+	}
+	if helper.IsLessThanOrEqual(result.BatchSize, 0) {
+		result.BatchSize = 100
+	}
+	if helper.IsLessThanOrEqual(result.PublishTimeout, 0) {
+		result.PublishTimeout = 5 * time.Second
+	}
+	if helper.IsLessThanOrEqual(result.ClaimTimeout, 0) {
+		result.ClaimTimeout = time.Minute
+	}
+	if helper.IsLessThanOrEqual(result.MaxAttempts, 0) {
+		result.MaxAttempts = 5
+	}
+	if helper.IsLessThanOrEqual(result.InitialBackoff, 0) {
+		result.InitialBackoff = time.Second
+	}
+	if helper.IsLessThanOrEqual(result.MaxBackoff, 0) {
+		result.MaxBackoff = time.Minute
+	}
+	if helper.IsNil(result.DisableWatch) {
+		result.DisableWatch = helper.ConvertToPointer(false)
+	}
+	return result
+}
@@ -41,6 +41,15 @@ type Replace struct {
 	// committing the transactions, and continue creating a new session.
 	// default is false
 	ForceRecreateSession *bool
+	// WriteConcern The acknowledgment requested from the server for the transaction of the template session started by
+	// the operation. It only applies when the session is created, since the write concern of a transaction is set when
+	// it starts, so it is ignored if the session is already open, e.g. after StartSession or a previous operation with
+	// DisableAutoCloseSession. The default value is the global option, and if it is nil, the client write concern.
+	WriteConcern *WriteConcern
+	// CausalConsistency If set, configures the causal consistency of the session started by the operation, it is
+	// ignored if the session is already open. The default value is the global option, and if it is false, the driver
+	// default, which is true.
+	CausalConsistency *bool
 }
 
 // NewReplace creates a new Replace instance.
@@ -78,6 +87,12 @@ func (r *Replace) SetLet(a any) *Replace {
 	return r
 }
 
+// SetUpsert sets value for the Upsert field.
+func (r *Replace) SetUpsert(b bool) *Replace {
+	r.Upsert = &b
+	return r
+}
+
 // SetDisableAutoRollbackSession creates a new DisableAutoRollbackSession instance.
 func (r *Replace) SetDisableAutoRollbackSession(b bool) *Replace {
 	r.DisableAutoRollbackSession = &b
@@ -96,6 +111,18 @@ func (r *Replace) SetForceRecreateSession(b bool) *Replace {
 	return r
 }
 
+// SetWriteConcern sets value for the WriteConcern field.
+func (r *Replace) SetWriteConcern(w *WriteConcern) *Replace {
+	r.WriteConcern = w
+	return r
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (r *Replace) SetCausalConsistency(b bool) *Replace {
+	r.CausalConsistency = &b
+	return r
+}
+
 // MergeReplaceByParams assembles the Replace object from optional parameters.
 func MergeReplaceByParams(opts []*Replace, global *Global) *Replace {
 	result := &Replace{}
@@ -103,6 +130,9 @@ func MergeReplaceByParams(opts []*Replace, global *Global) *Replace {
 		if helper.IsNil(opt) {
 			continue
 		}
+		if helper.IsNotNil(opt.BypassDocumentValidation) {
+			result.BypassDocumentValidation = opt.BypassDocumentValidation
+		}
 		if helper.IsNotNil(opt.Collation) {
 			result.Collation = opt.Collation
 		}
@@ -115,6 +145,9 @@ func MergeReplaceByParams(opts []*Replace, global *Global) *Replace {
 		if helper.IsNotNil(opt.Let) {
 			result.Let = opt.Let
 		}
+		if helper.IsNotNil(opt.Upsert) {
+			result.Upsert = opt.Upsert
+		}
 		if helper.IsNotNil(opt.DisableAutoRollbackSession) {
 			result.DisableAutoRollbackSession = opt.DisableAutoRollbackSession
 		}
@@ -124,6 +157,12 @@ func MergeReplaceByParams(opts []*Replace, global *Global) *Replace {
 		if helper.IsNotNil(opt.ForceRecreateSession) {
 			result.ForceRecreateSession = opt.ForceRecreateSession
 		}
+		if helper.IsNotNil(opt.WriteConcern) {
+			result.WriteConcern = opt.WriteConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
 	}
 	if helper.IsNil(result.BypassDocumentValidation) {
 		result.BypassDocumentValidation = helper.ConvertToPointer(global.BypassDocumentValidation)
@@ -140,5 +179,11 @@ func MergeReplaceByParams(opts []*Replace, global *Global) *Replace {
 	if helper.IsNil(result.ForceRecreateSession) {
 		result.ForceRecreateSession = helper.ConvertToPointer(global.ForceRecreateSession)
 	}
+	if helper.IsNil(result.WriteConcern) {
+		result.WriteConcern = global.WriteConcern
+	}
+	if helper.IsNil(result.CausalConsistency) && global.CausalConsistency {
+		result.CausalConsistency = helper.ConvertToPointer(true)
+	}
 	return result
 }
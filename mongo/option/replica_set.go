@@ -0,0 +1,104 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"go.mongodb.org/mongo-driver/event"
+	"time"
+)
+
+// ReplicaSet represents options that can be used to configure the replica set started by mongotest.StartReplicaSet.
+type ReplicaSet struct {
+	// BinaryPath path of the mongod binary, without it the MONGOD_BINARY environment variable is used, and without it
+	// the mongod found on the PATH.
+	BinaryPath string
+	// ReplicaSetName name of the single-node replica set.
+	//
+	// default: rs0
+	ReplicaSetName string
+	// StartupTimeout Time limit for the server to start and be elected primary.
+	//
+	// default: 30 seconds
+	StartupTimeout time.Duration
+	// Args Extra command line arguments of the mongod, e.g. --setParameter options.
+	Args []string
+	// Global The global options of the returned Template, see Template.SetGlobalOption.
+	Global *Global
+	// Monitor The command monitor of the client of the returned Template, e.g. the monitor of a mongotest.Recorder.
+	Monitor *event.CommandMonitor
+}
+
+// NewReplicaSet creates a new ReplicaSet instance.
+func NewReplicaSet() *ReplicaSet {
+	return &ReplicaSet{}
+}
+
+// SetBinaryPath sets value for the BinaryPath field.
+func (r *ReplicaSet) SetBinaryPath(s string) *ReplicaSet {
+	r.BinaryPath = s
+	return r
+}
+
+// SetReplicaSetName sets value for the ReplicaSetName field.
+func (r *ReplicaSet) SetReplicaSetName(s string) *ReplicaSet {
+	r.ReplicaSetName = s
+	return r
+}
+
+// SetStartupTimeout sets value for the StartupTimeout field.
+func (r *ReplicaSet) SetStartupTimeout(d time.Duration) *ReplicaSet {
+	r.StartupTimeout = d
+	return r
+}
+
+// SetArgs sets value for the Args field.
+func (r *ReplicaSet) SetArgs(args ...string) *ReplicaSet {
+	r.Args = args
+	return r
+}
+
+// SetGlobal sets value for the Global field.
+func (r *ReplicaSet) SetGlobal(g *Global) *ReplicaSet {
+	r.Global = g
+	return r
+}
+
+// SetMonitor sets value for the Monitor field.
+func (r *ReplicaSet) SetMonitor(m *event.CommandMonitor) *ReplicaSet {
+	r.Monitor = m
+	return r
+}
+
+// MergeReplicaSetByParams assembles the ReplicaSet object from optional parameters.
+func MergeReplicaSetByParams(opts []*ReplicaSet) *ReplicaSet {
+	result := &ReplicaSet{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotEmpty(opt.BinaryPath) {
+			result.BinaryPath = opt.BinaryPath
+		}
+		if helper.IsNotEmpty(opt.ReplicaSetName) {
+			result.ReplicaSetName = opt.ReplicaSetName
+		}
+		if helper.IsGreaterThan(opt.StartupTimeout, 0) {
+			result.StartupTimeout = opt.StartupTimeout
+		}
+		if helper.IsNotEmpty(opt.Args) {
+			result.Args = opt.Args
+		}
+		if helper.IsNotNil(opt.Global) {
+			result.Global = opt.Global
+		}
+		if helper.IsNotNil(opt.Monitor) {
+			result.Monitor = opt.Monitor
+		}
+	}
+	if helper.IsEmpty(result.ReplicaSetName) {
+		result.ReplicaSetName = "rs0"
+	}
+	if helper.IsLessThanOrEqual(result.StartupTimeout, 0) {
+		result.StartupTimeout = 30 * time.Second
+	}
+	return result
+}
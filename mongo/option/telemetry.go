@@ -0,0 +1,77 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Telemetry represents options that can be used to configure the OpenTelemetry instrumentation of the
+// mongo.Template, see mongo.NewTemplateWithTelemetry.
+type Telemetry struct {
+	// TracerProvider Provider of the tracer of the operation, event and command spans.
+	//
+	// default: otel.GetTracerProvider()
+	TracerProvider trace.TracerProvider
+	// MeterProvider Provider of the meter of the operation duration histogram and error counter.
+	//
+	// default: otel.GetMeterProvider()
+	MeterProvider metric.MeterProvider
+	// DisableCommandSpans If true, the server commands are not traced as child spans of the operation spans.
+	//
+	// default: false
+	DisableCommandSpans *bool
+}
+
+// NewTelemetry creates a new Telemetry instance.
+func NewTelemetry() *Telemetry {
+	return &Telemetry{}
+}
+
+// SetTracerProvider sets value for the TracerProvider field.
+func (t *Telemetry) SetTracerProvider(p trace.TracerProvider) *Telemetry {
+	t.TracerProvider = p
+	return t
+}
+
+// SetMeterProvider sets value for the MeterProvider field.
+func (t *Telemetry) SetMeterProvider(p metric.MeterProvider) *Telemetry {
+	t.MeterProvider = p
+	return t
+}
+
+// SetDisableCommandSpans sets value for the DisableCommandSpans field.
+func (t *Telemetry) SetDisableCommandSpans(b bool) *Telemetry {
+	t.DisableCommandSpans = &b
+	return t
+}
+
+// MergeTelemetryByParams assembles the Telemetry object from optional parameters.
+func MergeTelemetryByParams(opts []*Telemetry) *Telemetry {
+	result := &Telemetry{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.TracerProvider) {
+			result.TracerProvider = opt.TracerProvider
+		}
+		if helper.IsNotNil(opt.MeterProvider) {
+			result.MeterProvider = opt.MeterProvider
+		}
+		if helper.IsNotNil(opt.DisableCommandSpans) {
+			result.DisableCommandSpans = opt.DisableCommandSpans
+		}
+	}
+	if helper.IsNil(result.TracerProvider) {
+		result.TracerProvider = otel.GetTracerProvider()
+	}
+	if helper.IsNil(result.MeterProvider) {
+		result.MeterProvider = otel.GetMeterProvider()
+	}
+	if helper.IsNil(result.DisableCommandSpans) {
+		result.DisableCommandSpans = helper.ConvertToPointer(false)
+	}
+	return result
+}
@@ -45,6 +45,15 @@ type Update struct {
 	// committing the transactions, and continue creating a new session.
 	// default is false
 	ForceRecreateSession *bool
+	// WriteConcern The acknowledgment requested from the server for the transaction of the template session started by
+	// the operation. It only applies when the session is created, since the write concern of a transaction is set when
+	// it starts, so it is ignored if the session is already open, e.g. after StartSession or a previous operation with
+	// DisableAutoCloseSession. The default value is the global option, and if it is nil, the client write concern.
+	WriteConcern *WriteConcern
+	// CausalConsistency If set, configures the causal consistency of the session started by the operation, it is
+	// ignored if the session is already open. The default value is the global option, and if it is false, the driver
+	// default, which is true.
+	CausalConsistency *bool
 }
 
 // NewUpdate creates a new Update instance.
@@ -112,6 +121,18 @@ func (u *Update) SetUpsert(b bool) *Update {
 	return u
 }
 
+// SetWriteConcern sets value for the WriteConcern field.
+func (u *Update) SetWriteConcern(w *WriteConcern) *Update {
+	u.WriteConcern = w
+	return u
+}
+
+// SetCausalConsistency sets value for the CausalConsistency field.
+func (u *Update) SetCausalConsistency(b bool) *Update {
+	u.CausalConsistency = &b
+	return u
+}
+
 // MergeUpdateByParams assembles the Update object from optional parameters.
 func MergeUpdateByParams(opts []*Update, global *Global) *Update {
 	result := &Update{}
@@ -122,6 +143,9 @@ func MergeUpdateByParams(opts []*Update, global *Global) *Update {
 		if helper.IsNotNil(opt.ArrayFilters) {
 			result.ArrayFilters = opt.ArrayFilters
 		}
+		if helper.IsNotNil(opt.BypassDocumentValidation) {
+			result.BypassDocumentValidation = opt.BypassDocumentValidation
+		}
 		if helper.IsNotNil(opt.Collation) {
 			result.Collation = opt.Collation
 		}
@@ -134,6 +158,9 @@ func MergeUpdateByParams(opts []*Update, global *Global) *Update {
 		if helper.IsNotNil(opt.Let) {
 			result.Let = opt.Let
 		}
+		if helper.IsNotNil(opt.Upsert) {
+			result.Upsert = opt.Upsert
+		}
 		if helper.IsNotNil(opt.DisableAutoRollbackSession) {
 			result.DisableAutoRollbackSession = opt.DisableAutoRollbackSession
 		}
@@ -143,6 +170,12 @@ func MergeUpdateByParams(opts []*Update, global *Global) *Update {
 		if helper.IsNotNil(opt.ForceRecreateSession) {
 			result.ForceRecreateSession = opt.ForceRecreateSession
 		}
+		if helper.IsNotNil(opt.WriteConcern) {
+			result.WriteConcern = opt.WriteConcern
+		}
+		if helper.IsNotNil(opt.CausalConsistency) {
+			result.CausalConsistency = opt.CausalConsistency
+		}
 	}
 	if helper.IsNil(result.BypassDocumentValidation) {
 		result.BypassDocumentValidation = helper.ConvertToPointer(global.BypassDocumentValidation)
@@ -159,5 +192,11 @@ func MergeUpdateByParams(opts []*Update, global *Global) *Update {
 	if helper.IsNil(result.ForceRecreateSession) {
 		result.ForceRecreateSession = helper.ConvertToPointer(global.ForceRecreateSession)
 	}
+	if helper.IsNil(result.WriteConcern) {
+		result.WriteConcern = global.WriteConcern
+	}
+	if helper.IsNil(result.CausalConsistency) && global.CausalConsistency {
+		result.CausalConsistency = helper.ConvertToPointer(true)
+	}
 	return result
 }
@@ -1,6 +1,7 @@
 package option
 
 import (
+	"context"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -60,17 +61,161 @@ type Watch struct {
 	CustomPipeline bson.M
 }
 
+// TokenStore represents a storage of the change stream resume tokens, used by the 'WatchWithHandler' operation to
+// continue where it left off after a restart.
+type TokenStore interface {
+	// Load returns the last resume token saved with the key, or nil if there is none.
+	Load(ctx context.Context, key string) (bson.Raw, error)
+	// Save stores the resume token with the key, replacing the previous one.
+	Save(ctx context.Context, key string, token bson.Raw) error
+}
+
 // WatchWithHandler represents options that can be used to configure a 'WatchWithHandler'  operation.
 type WatchWithHandler struct {
 	// DatabaseName database name to watch
 	DatabaseName string
 	// CollectionName collection name to watch
 	CollectionName string
-	// ContextFuncTimeout Duration time to process the func watch, timeout applied in the past context.
+	// ContextFuncTimeout Duration time to process the func watch, timeout applied in the past context. When it is
+	// reached, the context is cancelled and the next event is only processed after the handler returns, or after the
+	// same duration if the handler does not stop, so the handler must return when the context is done.
 	//
 	// default: 5 seconds
 	ContextFuncTimeout time.Duration
-	// DelayLoop Delay to run the next search for messages in the queue
+	// TimeoutAsFailure If true, a handler that reaches the ContextFuncTimeout fails with mongo.ErrEventHandlerTimeout,
+	// so the event is retried and dead-lettered as any other handler error, otherwise the next event is processed.
+	//
+	// default: false
+	TimeoutAsFailure *bool
+	// OnTimeout Func called with the raw change event when the handler reaches the ContextFuncTimeout, it can be used
+	// to report timeouts to logs or metrics. The default is nil, which means that the timeouts are not reported.
+	OnTimeout func(ctx context.Context, event bson.Raw, timeout time.Duration)
+	// DelayLoop Delay to reconnect the change stream after it is interrupted by a resumable error, e.g. network error
+	// or primary stepdown.
+	//
+	// default: 5 seconds
+	DelayLoop time.Duration
+	// TokenStore Storage where the resume token is saved after each event processed by the handler, and loaded when
+	// the operation starts, so a restarted consumer continues where it left off. When a token is loaded, it replaces
+	// the ResumeAfter, StartAfter and StartAtOperationTime fields. The default is nil, which means that the resume
+	// token is only kept in memory to reconnect.
+	TokenStore TokenStore
+	// TokenKey Key of the resume token on the TokenStore, each consumer must have its own key.
+	//
+	// default: DatabaseName.CollectionName
+	TokenKey string
+	// Concurrency Number of workers processing the events in parallel, the events are partitioned by the DocumentKey,
+	// so the events of the same document are processed in order by the same worker, while the events of different
+	// documents are processed in parallel.
+	//
+	// default: 1
+	Concurrency int
+	// QueueSize Maximum number of events waiting on each worker, when the queue of the worker is full, the reading of
+	// the change stream is blocked until the worker processes its events.
+	//
+	// default: 10
+	QueueSize int
+	// PollingFallback If true, when the deployment does not support change streams, e.g. a standalone server, the
+	// collection is polled by the PollingField watermark, and the handler receives synthesized insert and update
+	// events. The polling requires the DatabaseName and CollectionName fields, and the pipeline is not applied to the
+	// synthesized events.
+	//
+	// A document is synthesized as insert when its ObjectID is greater than all the ones already read, otherwise as
+	// update. Deletes are not detected, and the checkpoint saved on the TokenStore is the polling watermark.
+	//
+	// default: false
+	PollingFallback *bool
+	// PollingField Field of the documents updated on every write, e.g. "updatedAt", used as watermark on the polling
+	// fallback. If it is "_id", only insert events are synthesized, since the ObjectID only grows on inserts.
+	//
+	// default: updatedAt
+	PollingField string
+	// PollingInterval Delay to poll the collection again when there are no new changes.
+	//
+	// default: 1 second
+	PollingInterval time.Duration
+	// PollingBatchSize The maximum number of documents read on each poll.
+	//
+	// default: 100
+	PollingBatchSize int64
+	// MaxRetries Maximum number of times that the handler is called again for the same event after returning an
+	// error.
+	//
+	// default: 3
+	MaxRetries *int
+	// RetryDelay Delay to call the handler again after the first error, it is doubled on each next retry.
+	//
+	// default: 1 second
+	RetryDelay time.Duration
+	// MaxRetryDelay Maximum delay between the handler retries.
+	//
+	// default: 30 seconds
+	MaxRetryDelay time.Duration
+	// DeadLetter Func called with the raw change event and the error when the event could not be decoded or the
+	// handler failed after all the retries, if it returns nil, the event is checkpointed and the next event is read,
+	// otherwise the operation is stopped returning the error. The default is nil, which means that the operation is
+	// stopped returning the event error, without advancing past the event.
+	DeadLetter func(ctx context.Context, event bson.Raw, err error) error
+	// Errors Channel where all the errors of the operation are sent, e.g. decode, handler and token store errors. The
+	// send does not block, so the errors are dropped if the channel is not ready to receive. The default is nil,
+	// which means that the errors are not sent.
+	Errors chan<- error
+	// BatchSize The maximum number of documents to be included in each batch returned by the server.
+	BatchSize *int32
+	// Collation Specifies a collation to use for string comparisons during the operation. This option is only valid
+	// for MongoDB  versions >= 3.4. For previous server versions, the driver will return an error if this option is
+	// used. The default value is nil, which means the default collation of the collection will be used.
+	Collation *Collation
+	// Comment A string that will be included in server logs, profiling logs, and currentOp queries to help trace the operation.
+	// The default is nil, which means that no comment will be included in the logs.
+	Comment *string
+	// FullDocument Specifies how the updated document should be returned in Change notifications for update operations.
+	// The default is FullDocumentDefault, which means that only partial update deltas will be included in the Change
+	// notification.
+	FullDocument *FullDocument
+	// FullDocumentBeforeChange Specifies how the pre-update document should be returned in Change notifications for
+	// update operations. The default is FullDocumentOff, which means that the pre-update document will not be included
+	// in the Change notification.
+	FullDocumentBeforeChange *FullDocument
+	// MaxAwaitTime The maximum amount of time that the server should wait for new documents to satisfy a tailable cursor query.
+	MaxAwaitTime *time.Duration
+	// ResumeAfter A document specifying the logical starting point for the Change stream. Only changes corresponding to an oplog
+	// entry immediately after the resume token will be returned. If this is specified, StartAtOperationTime and
+	// StartAfter must not be set.
+	ResumeAfter any
+	// ShowExpandedEvents specifies whether the server will return an expanded list of Change stream events. Additional
+	// events include: createIndexes, dropIndexes, modify, create, shardCollection, reshardCollection and
+	// refineCollectionShardKey. This option is only valid for MongoDB versions >= 6.0.
+	ShowExpandedEvents *bool
+	// StartAtOperationTime If specified, the Change stream will only return changes that occurred at or after the given timestamp. This
+	// option is only valid for MongoDB versions >= 4.0. If this is specified, ResumeAfter and StartAfter must not be
+	// set.
+	StartAtOperationTime *primitive.Timestamp
+	// StartAfter A document specifying the logical starting point for the Change stream. This is similar to the ResumeAfter
+	// option, but allows a resume token from an "invalidate" notification to be used. This allows a Change stream on a
+	// collection to be resumed after the collection has been dropped and recreated or renamed. Only changes
+	// corresponding to an oplog entry immediately after the specified token will be returned. If this is specified,
+	// ResumeAfter and StartAtOperationTime must not be set. This option is only valid for MongoDB versions >= 4.1.1.
+	StartAfter any
+	// Custom options to be added to the initial aggregate for the Change stream. Key-value pairs of the BSON map should
+	// correlate with desired option names and values. Values must be Marshalable. Custom options may conflict with
+	// non-custom options, and custom options bypass client-side validation. Prefer using non-custom options where possible.
+	Custom bson.M
+	// CustomPipeline options to be added to the $changeStream stage in the initial aggregate. Key-value pairs of the BSON map should
+	// correlate with desired option names and values. Values must be Marshalable. Custom pipeline options bypass client-side
+	// validation. Prefer using non-custom options where possible.
+	CustomPipeline bson.M
+}
+
+// WatchChan represents options that can be used to configure a 'WatchChan' operation.
+type WatchChan struct {
+	// BufferSize Maximum number of events waiting on the events channel, when it is full, the reading of the change
+	// stream is blocked until the events are received.
+	//
+	// default: 100
+	BufferSize int
+	// DelayLoop Delay to reconnect the change stream after it is interrupted by a resumable error, e.g. network error
+	// or primary stepdown.
 	//
 	// default: 5 seconds
 	DelayLoop time.Duration
@@ -126,6 +271,11 @@ func NewWatch() *Watch {
 	return &Watch{}
 }
 
+// NewWatchChan creates a new WatchChan instance.
+func NewWatchChan() *WatchChan {
+	return &WatchChan{}
+}
+
 // NewWatchWithHandler creates a new WatchWithHandler instance.
 func NewWatchWithHandler() *WatchWithHandler {
 	return &WatchWithHandler{}
@@ -215,6 +365,90 @@ func (w *Watch) SetCustomPipeline(b bson.M) *Watch {
 	return w
 }
 
+// SetBufferSize sets value for the BufferSize field.
+func (w *WatchChan) SetBufferSize(i int) *WatchChan {
+	w.BufferSize = i
+	return w
+}
+
+// SetDelayLoop sets value for the DelayLoop field.
+func (w *WatchChan) SetDelayLoop(d time.Duration) *WatchChan {
+	w.DelayLoop = d
+	return w
+}
+
+// SetBatchSize sets value for the BatchSize field.
+func (w *WatchChan) SetBatchSize(i int32) *WatchChan {
+	w.BatchSize = &i
+	return w
+}
+
+// SetCollation sets value for the Collation field.
+func (w *WatchChan) SetCollation(c *Collation) *WatchChan {
+	w.Collation = c
+	return w
+}
+
+// SetComment sets value for the Comment field.
+func (w *WatchChan) SetComment(s string) *WatchChan {
+	w.Comment = &s
+	return w
+}
+
+// SetFullDocument sets value for the FullDocument field.
+func (w *WatchChan) SetFullDocument(f FullDocument) *WatchChan {
+	w.FullDocument = &f
+	return w
+}
+
+// SetFullDocumentBeforeChange sets value for the FullDocumentBeforeChange field.
+func (w *WatchChan) SetFullDocumentBeforeChange(f FullDocument) *WatchChan {
+	w.FullDocumentBeforeChange = &f
+	return w
+}
+
+// SetMaxAwaitTime sets value for the MaxAwaitTime field.
+func (w *WatchChan) SetMaxAwaitTime(d time.Duration) *WatchChan {
+	w.MaxAwaitTime = &d
+	return w
+}
+
+// SetResumeAfter sets value for the ResumeAfter field.
+func (w *WatchChan) SetResumeAfter(a any) *WatchChan {
+	w.ResumeAfter = a
+	return w
+}
+
+// SetShowExpandedEvents sets value for the ShowExpandedEvents field.
+func (w *WatchChan) SetShowExpandedEvents(b bool) *WatchChan {
+	w.ShowExpandedEvents = &b
+	return w
+}
+
+// SetStartAtOperationTime sets value for the StartAtOperationTime field.
+func (w *WatchChan) SetStartAtOperationTime(t primitive.Timestamp) *WatchChan {
+	w.StartAtOperationTime = &t
+	return w
+}
+
+// SetStartAfter sets value for the StartAfter field.
+func (w *WatchChan) SetStartAfter(a any) *WatchChan {
+	w.StartAfter = a
+	return w
+}
+
+// SetCustom sets value for the Custom field.
+func (w *WatchChan) SetCustom(b bson.M) *WatchChan {
+	w.Custom = b
+	return w
+}
+
+// SetCustomPipeline sets value for the CustomPipeline field.
+func (w *WatchChan) SetCustomPipeline(b bson.M) *WatchChan {
+	w.CustomPipeline = b
+	return w
+}
+
 // SetDatabaseName sets value for the DatabaseName field.
 func (w *WatchWithHandler) SetDatabaseName(s string) *WatchWithHandler {
 	w.DatabaseName = s
@@ -232,11 +466,101 @@ func (w *WatchWithHandler) SetContextFuncTimeout(d time.Duration) *WatchWithHand
 	return w
 }
 
+// SetTimeoutAsFailure sets value for the TimeoutAsFailure field.
+func (w *WatchWithHandler) SetTimeoutAsFailure(b bool) *WatchWithHandler {
+	w.TimeoutAsFailure = &b
+	return w
+}
+
+// SetOnTimeout sets value for the OnTimeout field.
+func (w *WatchWithHandler) SetOnTimeout(f func(ctx context.Context, event bson.Raw, timeout time.Duration)) *WatchWithHandler {
+	w.OnTimeout = f
+	return w
+}
+
 func (w *WatchWithHandler) SetDelayLoop(d time.Duration) *WatchWithHandler {
 	w.DelayLoop = d
 	return w
 }
 
+// SetTokenStore sets value for the TokenStore field.
+func (w *WatchWithHandler) SetTokenStore(t TokenStore) *WatchWithHandler {
+	w.TokenStore = t
+	return w
+}
+
+// SetTokenKey sets value for the TokenKey field.
+func (w *WatchWithHandler) SetTokenKey(s string) *WatchWithHandler {
+	w.TokenKey = s
+	return w
+}
+
+// SetConcurrency sets value for the Concurrency field.
+func (w *WatchWithHandler) SetConcurrency(i int) *WatchWithHandler {
+	w.Concurrency = i
+	return w
+}
+
+// SetQueueSize sets value for the QueueSize field.
+func (w *WatchWithHandler) SetQueueSize(i int) *WatchWithHandler {
+	w.QueueSize = i
+	return w
+}
+
+// SetPollingFallback sets value for the PollingFallback field.
+func (w *WatchWithHandler) SetPollingFallback(b bool) *WatchWithHandler {
+	w.PollingFallback = &b
+	return w
+}
+
+// SetPollingField sets value for the PollingField field.
+func (w *WatchWithHandler) SetPollingField(s string) *WatchWithHandler {
+	w.PollingField = s
+	return w
+}
+
+// SetPollingInterval sets value for the PollingInterval field.
+func (w *WatchWithHandler) SetPollingInterval(d time.Duration) *WatchWithHandler {
+	w.PollingInterval = d
+	return w
+}
+
+// SetPollingBatchSize sets value for the PollingBatchSize field.
+func (w *WatchWithHandler) SetPollingBatchSize(i int64) *WatchWithHandler {
+	w.PollingBatchSize = i
+	return w
+}
+
+// SetMaxRetries sets value for the MaxRetries field.
+func (w *WatchWithHandler) SetMaxRetries(i int) *WatchWithHandler {
+	w.MaxRetries = &i
+	return w
+}
+
+// SetRetryDelay sets value for the RetryDelay field.
+func (w *WatchWithHandler) SetRetryDelay(d time.Duration) *WatchWithHandler {
+	w.RetryDelay = d
+	return w
+}
+
+// SetMaxRetryDelay sets value for the MaxRetryDelay field.
+func (w *WatchWithHandler) SetMaxRetryDelay(d time.Duration) *WatchWithHandler {
+	w.MaxRetryDelay = d
+	return w
+}
+
+// SetDeadLetter sets value for the DeadLetter field.
+func (w *WatchWithHandler) SetDeadLetter(f func(ctx context.Context, event bson.Raw, err error) error) *WatchWithHandler {
+	w.DeadLetter = f
+	return w
+}
+
+// SetErrors sets value for the Errors field.
+func (w *WatchWithHandler) SetErrors(c chan<- error) *WatchWithHandler {
+	w.Errors = c
+	return w
+}
+
 // SetBatchSize creates a new BatchSize instance.
 func (w *WatchWithHandler) SetBatchSize(i int32) *WatchWithHandler {
 	w.BatchSize = &i
@@ -375,9 +699,54 @@ func MergeWatchHandlerByParams(opts []*WatchWithHandler) *WatchWithHandler {
 		if helper.IsGreaterThan(opt.ContextFuncTimeout, 0) {
 			result.ContextFuncTimeout = opt.ContextFuncTimeout
 		}
+		if helper.IsNotNil(opt.TimeoutAsFailure) {
+			result.TimeoutAsFailure = opt.TimeoutAsFailure
+		}
+		if opt.OnTimeout != nil {
+			result.OnTimeout = opt.OnTimeout
+		}
 		if helper.IsGreaterThan(opt.DelayLoop, 0) {
 			result.DelayLoop = opt.DelayLoop
 		}
+		if helper.IsNotNil(opt.TokenStore) {
+			result.TokenStore = opt.TokenStore
+		}
+		if helper.IsNotEmpty(opt.TokenKey) {
+			result.TokenKey = opt.TokenKey
+		}
+		if helper.IsGreaterThan(opt.Concurrency, 0) {
+			result.Concurrency = opt.Concurrency
+		}
+		if helper.IsGreaterThan(opt.QueueSize, 0) {
+			result.QueueSize = opt.QueueSize
+		}
+		if helper.IsNotNil(opt.PollingFallback) {
+			result.PollingFallback = opt.PollingFallback
+		}
+		if helper.IsNotEmpty(opt.PollingField) {
+			result.PollingField = opt.PollingField
+		}
+		if helper.IsGreaterThan(opt.PollingInterval, 0) {
+			result.PollingInterval = opt.PollingInterval
+		}
+		if helper.IsGreaterThan(opt.PollingBatchSize, 0) {
+			result.PollingBatchSize = opt.PollingBatchSize
+		}
+		if helper.IsNotNil(opt.MaxRetries) {
+			result.MaxRetries = opt.MaxRetries
+		}
+		if helper.IsGreaterThan(opt.RetryDelay, 0) {
+			result.RetryDelay = opt.RetryDelay
+		}
+		if helper.IsGreaterThan(opt.MaxRetryDelay, 0) {
+			result.MaxRetryDelay = opt.MaxRetryDelay
+		}
+		if opt.DeadLetter != nil {
+			result.DeadLetter = opt.DeadLetter
+		}
+		if helper.IsNotNil(opt.Errors) {
+			result.Errors = opt.Errors
+		}
 		if helper.IsNotNil(opt.BatchSize) {
 			result.BatchSize = opt.BatchSize
 		}
@@ -427,8 +796,97 @@ func MergeWatchHandlerByParams(opts []*WatchWithHandler) *WatchWithHandler {
 	if helper.IsLessThanOrEqual(result.ContextFuncTimeout, 0) {
 		result.ContextFuncTimeout = 5 * time.Second
 	}
+	if helper.IsNil(result.TimeoutAsFailure) {
+		result.TimeoutAsFailure = helper.ConvertToPointer(false)
+	}
 	if helper.IsLessThanOrEqual(result.DelayLoop, 0) {
 		result.DelayLoop = 5 * time.Second
 	}
+	if helper.IsLessThanOrEqual(result.Concurrency, 0) {
+		result.Concurrency = 1
+	}
+	if helper.IsLessThanOrEqual(result.QueueSize, 0) {
+		result.QueueSize = 10
+	}
+	if helper.IsNil(result.PollingFallback) {
+		result.PollingFallback = helper.ConvertToPointer(false)
+	}
+	if helper.IsEmpty(result.PollingField) {
+		result.PollingField = "updatedAt"
+	}
+	if helper.IsLessThanOrEqual(result.PollingInterval, 0) {
+		result.PollingInterval = time.Second
+	}
+	if helper.IsLessThanOrEqual(result.PollingBatchSize, 0) {
+		result.PollingBatchSize = 100
+	}
+	if helper.IsNil(result.MaxRetries) {
+		result.MaxRetries = helper.ConvertToPointer(3)
+	}
+	if helper.IsLessThanOrEqual(result.RetryDelay, 0) {
+		result.RetryDelay = time.Second
+	}
+	if helper.IsLessThanOrEqual(result.MaxRetryDelay, 0) {
+		result.MaxRetryDelay = 30 * time.Second
+	}
 	return &result
 }
+
+// MergeWatchChanByParams assembles the WatchChan object from optional parameters.
+func MergeWatchChanByParams(opts []*WatchChan) *WatchChan {
+	result := &WatchChan{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsGreaterThan(opt.BufferSize, 0) {
+			result.BufferSize = opt.BufferSize
+		}
+		if helper.IsGreaterThan(opt.DelayLoop, 0) {
+			result.DelayLoop = opt.DelayLoop
+		}
+		if helper.IsNotNil(opt.BatchSize) {
+			result.BatchSize = opt.BatchSize
+		}
+		if helper.IsNotNil(opt.Collation) {
+			result.Collation = opt.Collation
+		}
+		if helper.IsNotNil(opt.Comment) {
+			result.Comment = opt.Comment
+		}
+		if helper.IsNotNil(opt.MaxAwaitTime) {
+			result.MaxAwaitTime = opt.MaxAwaitTime
+		}
+		if helper.IsNotNil(opt.ResumeAfter) {
+			result.ResumeAfter = opt.ResumeAfter
+		}
+		if helper.IsNotNil(opt.FullDocument) {
+			result.FullDocument = opt.FullDocument
+		}
+		if helper.IsNotNil(opt.FullDocumentBeforeChange) {
+			result.FullDocumentBeforeChange = opt.FullDocumentBeforeChange
+		}
+		if helper.IsNotNil(opt.ShowExpandedEvents) {
+			result.ShowExpandedEvents = opt.ShowExpandedEvents
+		}
+		if helper.IsNotNil(opt.StartAtOperationTime) {
+			result.StartAtOperationTime = opt.StartAtOperationTime
+		}
+		if helper.IsNotNil(opt.StartAfter) {
+			result.StartAfter = opt.StartAfter
+		}
+		if helper.IsNotNil(opt.Custom) {
+			result.Custom = opt.Custom
+		}
+		if helper.IsNotNil(opt.CustomPipeline) {
+			result.CustomPipeline = opt.CustomPipeline
+		}
+	}
+	if helper.IsLessThanOrEqual(result.BufferSize, 0) {
+		result.BufferSize = 100
+	}
+	if helper.IsLessThanOrEqual(result.DelayLoop, 0) {
+		result.DelayLoop = 5 * time.Second
+	}
+	return result
+}
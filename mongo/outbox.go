@@ -0,0 +1,323 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"sync"
+	"time"
+)
+
+// Outbox represents a transactional outbox, the events are added in the same transaction of the Template session,
+// and dispatched by the Relay only after the transaction is committed, see Template.NewOutbox.
+type Outbox struct {
+	template     *Template
+	opt          *option.Outbox
+	mutex        sync.Mutex
+	indexCreated bool
+}
+
+// OutboxEvent represents a domain event to be added on the Outbox.
+type OutboxEvent struct {
+	// Topic the destination of the event on the publisher (required)
+	Topic string
+	// Key the event key, e.g. the aggregate id, used by the publisher to partition the events
+	Key string
+	// Payload the event content, it must be a structure or a map
+	Payload any
+	// Headers the event metadata
+	Headers map[string]string
+}
+
+// OutboxMessage represents an OutboxEvent stored on the outbox collection.
+type OutboxMessage struct {
+	Id             primitive.ObjectID `bson:"_id"`
+	Topic          string             `bson:"topic"`
+	Key            string             `bson:"key,omitempty"`
+	Payload        any                `bson:"payload,omitempty"`
+	Headers        map[string]string  `bson:"headers,omitempty"`
+	Status         OutboxStatus       `bson:"status"`
+	Attempts       int                `bson:"attempts"`
+	LastError      string             `bson:"lastError,omitempty"`
+	CreatedAt      time.Time          `bson:"createdAt"`
+	NextAttemptAt  time.Time          `bson:"nextAttemptAt"`
+	ClaimExpiresAt time.Time          `bson:"claimExpiresAt,omitempty"`
+	DispatchedAt   time.Time          `bson:"dispatchedAt,omitempty"`
+}
+
+// OutboxPublisher publishes the message on the destination, if an error is returned, the message is retried with
+// backoff until the maximum attempts, see option.OutboxRelay.
+type OutboxPublisher func(ctx context.Context, message OutboxMessage) error
+
+// NewOutbox creates an Outbox stored on the collection configured in the opts parameter (see the option.Outbox
+// documentation.)
+func (t *Template) NewOutbox(opts ...*option.Outbox) *Outbox {
+	return &Outbox{
+		template: t,
+		opt:      option.MergeOutboxByParams(opts),
+	}
+}
+
+// Add executes an insert command to add the event on the outbox collection within the Template session, so it is
+// committed or aborted in the same transaction of the other operations of the session, e.g. call InsertOne with
+// option.InsertOne.DisableAutoCloseSession and then call Add to commit both.
+//
+// The event parameter must have the Topic filled.
+//
+// The opts parameter can be used to specify options for the operation (see the option.InsertOne documentation.)
+//
+// For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/insert/.
+func (o *Outbox) Add(ctx context.Context, event OutboxEvent, opts ...*option.InsertOne) error {
+	t := o.template
+	opt := option.MergeInsertOneByParams(opts, globalOption)
+	err := t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+	if helper.IsNil(err) {
+		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+			err = o.add(sc, event, opt)
+			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+		})
+	}
+	return err
+}
+
+// Relay dispatches the outbox messages to the publisher parameter until the ctx is done, marking them as dispatched
+// when the publisher returns no error. The new messages are consumed through WatchWithHandler, and the pending and
+// failed messages are polled every option.OutboxRelay.PollInterval, if the deployment does not support change
+// streams, the relay only polls.
+//
+// On the first call, the indexes of the claim queries and the TTL index of the option.Outbox.DispatchedRetention are
+// created on the outbox collection.
+//
+// Each message is claimed before being published, so more than one relay can run at the same time, and the failed
+// messages are retried with exponential backoff, when the option.OutboxRelay.MaxAttempts is reached, the message is
+// moved to the dead letter collection.
+//
+// The opts parameter can be used to specify options for the relay (see the option.OutboxRelay documentation.)
+func (o *Outbox) Relay(ctx context.Context, publisher OutboxPublisher, opts ...*option.OutboxRelay) error {
+	if publisher == nil {
+		return ErrOutboxPublisherIsNil
+	} else if helper.IsEmpty(o.opt.DatabaseName) {
+		return ErrDatabaseNotConfigured
+	}
+	err := o.createIndexes(ctx)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	opt := option.MergeOutboxRelayByParams(opts)
+	if !*opt.DisableWatch {
+		go o.watch(ctx, publisher, opt)
+	}
+	ticker := time.NewTicker(opt.PollInterval)
+	defer ticker.Stop()
+	for {
+		o.dispatchPending(ctx, publisher, opt)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// DecodePayload convert OutboxMessage.Payload to dest
+func (m OutboxMessage) DecodePayload(dest any) error {
+	if helper.IsNotPointer(dest) {
+		return ErrDestIsNotPointer
+	}
+	b, err := bson.Marshal(m.Payload)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	return bson.Unmarshal(b, dest)
+}
+
+func (o *Outbox) add(sc mongo.SessionContext, event OutboxEvent, opt *option.InsertOne) error {
+	if helper.IsEmpty(event.Topic) {
+		return ErrOutboxTopicIsEmpty
+	} else if helper.IsEmpty(o.opt.DatabaseName) {
+		return ErrDatabaseNotConfigured
+	}
+	now := time.Now()
+	_, err := o.collection().InsertOne(sc, OutboxMessage{
+		Id:            primitive.NewObjectID(),
+		Topic:         event.Topic,
+		Key:           event.Key,
+		Payload:       event.Payload,
+		Headers:       event.Headers,
+		Status:        OutboxStatusPending,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}, &options.InsertOneOptions{
+		BypassDocumentValidation: opt.BypassDocumentValidation,
+		Comment:                  opt.Comment,
+	})
+	return err
+}
+
+func (o *Outbox) watch(ctx context.Context, publisher OutboxPublisher, opt *option.OutboxRelay) {
+	pipeline := mongo.Pipeline{{{"$match", bson.M{"operationType": "insert"}}}}
+	watchOpt := option.NewWatchWithHandler().
+		SetDatabaseName(o.opt.DatabaseName).
+		SetCollectionName(o.opt.CollectionName).
+		SetContextFuncTimeout(opt.ClaimTimeout)
+	for {
+		err := o.template.WatchWithHandler(ctx, pipeline, func(eventCtx *EventContext) {
+			if id, ok := eventCtx.Event.DocumentKey.ID.(primitive.ObjectID); ok {
+				o.dispatch(eventCtx, id, publisher, opt)
+			}
+		}, watchOpt)
+		if helper.IsNotNil(err) {
+			logger.Info("outbox change stream unavailable, relay polling only, err:", err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(opt.PollInterval):
+		}
+	}
+}
+
+func (o *Outbox) dispatchPending(ctx context.Context, publisher OutboxPublisher, opt *option.OutboxRelay) {
+	cursor, err := o.collection().Find(ctx, o.claimableFilter(time.Now()), options.Find().
+		SetSort(bson.D{{"_id", 1}}).
+		SetLimit(opt.BatchSize).
+		SetProjection(bson.M{"_id": 1}))
+	if helper.IsNotNil(err) {
+		if helper.IsNil(ctx.Err()) {
+			logger.Error("error find outbox pending messages, err:", err)
+		}
+		return
+	}
+	var messages []OutboxMessage
+	err = cursor.All(ctx, &messages)
+	if helper.IsNotNil(err) {
+		logger.Error("error decode outbox pending messages, err:", err)
+		return
+	}
+	for _, message := range messages {
+		if helper.IsNotNil(ctx.Err()) {
+			return
+		}
+		o.dispatch(ctx, message.Id, publisher, opt)
+	}
+}
+
+func (o *Outbox) dispatch(ctx context.Context, id primitive.ObjectID, publisher OutboxPublisher, opt *option.OutboxRelay) {
+	collection := o.collection()
+	now := time.Now()
+	filter := o.claimableFilter(now)
+	filter["_id"] = id
+	var message OutboxMessage
+	err := collection.FindOneAndUpdate(ctx, filter, bson.M{
+		"$set": bson.M{"status": OutboxStatusProcessing, "claimExpiresAt": now.Add(opt.ClaimTimeout)},
+		"$inc": bson.M{"attempts": 1},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&message)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return
+	} else if helper.IsNotNil(err) {
+		logger.Error("error claim outbox message", id.Hex(), "err:", err)
+		return
+	}
+	publishCtx, cancel := context.WithTimeout(ctx, opt.PublishTimeout)
+	errPublish := publisher(publishCtx, message)
+	cancel()
+	claimFilter := bson.M{"_id": id, "status": OutboxStatusProcessing, "claimExpiresAt": message.ClaimExpiresAt}
+	if helper.IsNil(errPublish) {
+		_, err = collection.UpdateOne(ctx, claimFilter, bson.M{
+			"$set":   bson.M{"status": OutboxStatusDispatched, "dispatchedAt": time.Now()},
+			"$unset": bson.M{"claimExpiresAt": "", "lastError": ""},
+		})
+	} else if helper.IsGreaterThanOrEqual(message.Attempts, opt.MaxAttempts) {
+		err = o.deadLetter(ctx, message, claimFilter, errPublish)
+	} else {
+		_, err = collection.UpdateOne(ctx, claimFilter, bson.M{
+			"$set": bson.M{
+				"status":        OutboxStatusPending,
+				"nextAttemptAt": time.Now().Add(outboxBackoff(message.Attempts, opt)),
+				"lastError":     errPublish.Error(),
+			},
+			"$unset": bson.M{"claimExpiresAt": ""},
+		})
+	}
+	if helper.IsNotNil(err) {
+		logger.Error("error update outbox message", id.Hex(), "err:", err)
+	}
+}
+
+// deadLetter moves the message to the dead letter collection, it is first marked as dead letter with the claimFilter
+// parameter, so a relay whose claim expired does not move a message claimed by another one, and if the relay stops
+// before deleting it, the message is kept on the outbox collection without being dispatched again.
+func (o *Outbox) deadLetter(ctx context.Context, message OutboxMessage, claimFilter bson.M, errPublish error) error {
+	collection := o.collection()
+	result, err := collection.UpdateOne(ctx, claimFilter, bson.M{
+		"$set":   bson.M{"status": OutboxStatusDeadLetter, "lastError": errPublish.Error()},
+		"$unset": bson.M{"claimExpiresAt": ""},
+	})
+	if helper.IsNotNil(err) || helper.Equals(result.MatchedCount, int64(0)) {
+		return err
+	}
+	message.Status = OutboxStatusDeadLetter
+	message.LastError = errPublish.Error()
+	message.ClaimExpiresAt = time.Time{}
+	database := o.template.client.Database(o.opt.DatabaseName)
+	_, err = database.Collection(o.opt.DeadLetterCollectionName).InsertOne(ctx, message)
+	if helper.IsNotNil(err) && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	_, err = collection.DeleteOne(ctx, bson.M{"_id": message.Id, "status": OutboxStatusDeadLetter})
+	return err
+}
+
+func (o *Outbox) claimableFilter(now time.Time) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"status": OutboxStatusPending, "nextAttemptAt": bson.M{"$lte": now}},
+		bson.M{"status": OutboxStatusProcessing, "claimExpiresAt": bson.M{"$lte": now}},
+	}}
+}
+
+func (o *Outbox) createIndexes(ctx context.Context) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.indexCreated {
+		return nil
+	}
+	_, err := o.collection().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		parseIndexInputToModel(IndexInput{
+			Keys:    bson.D{{"status", 1}, {"nextAttemptAt", 1}, {"claimExpiresAt", 1}},
+			Options: option.NewIndex().SetName("status_nextAttemptAt_claimExpiresAt"),
+		}),
+		parseIndexInputToModel(IndexInput{
+			Keys: bson.D{{"dispatchedAt", 1}},
+			Options: option.NewIndex().
+				SetName("dispatchedAt_ttl").
+				SetExpireAfterSeconds(int32(o.opt.DispatchedRetention.Seconds())),
+		}),
+	})
+	if helper.IsNotNil(err) {
+		return err
+	}
+	o.indexCreated = true
+	return nil
+}
+
+func (o *Outbox) collection() *mongo.Collection {
+	return o.template.client.Database(o.opt.DatabaseName).Collection(o.opt.CollectionName)
+}
+
+func outboxBackoff(attempts int, opt *option.OutboxRelay) time.Duration {
+	result := opt.InitialBackoff
+	for i := 1; i < attempts && helper.IsLessThan(result, opt.MaxBackoff); i++ {
+		result *= 2
+	}
+	if helper.IsGreaterThan(result, opt.MaxBackoff) {
+		result = opt.MaxBackoff
+	}
+	return result
+}
@@ -44,6 +44,12 @@ func (p pageItemContent) Decode(dest any) error {
 	return helper.ConvertToDest(p, dest)
 }
 
+// NewPageResult creates a PageResult of the page input parameter with the result content, a slice of documents, and the
+// countTotal of documents matched by the filter, e.g. to implement FindPageable on a fake TemplateAPI.
+func NewPageResult(pageInput PageInput, result any, countTotal int64) *PageResult {
+	return newPageResult(pageInput, result, countTotal)
+}
+
 func newPageResult(pageInput PageInput, result any, countTotal int64) *PageResult {
 	minPageTotal := 1
 	if helper.IsEmpty(result) {
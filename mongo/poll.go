@@ -0,0 +1,232 @@
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"strings"
+	"time"
+)
+
+// changeStreamNotSupportedCode is the server error code returned when the $changeStream stage is used on a
+// deployment that is not a replica set or a sharded cluster.
+const changeStreamNotSupportedCode = 40573
+
+// pollingState is the watermark of the polling fallback, it is saved on the option.TokenStore as the resume token
+// {"polling": {"value": ..., "id": ..., "maxId": ...}}.
+type pollingState struct {
+	// Value of the polling field of the last document read
+	Value bson.RawValue
+	// ID of the last document read, used to break ties of the same Value
+	ID bson.RawValue
+	// MaxID greatest ObjectID read, the documents with greater ObjectID are synthesized as insert events
+	MaxID bson.RawValue
+}
+
+func (t *Template) pollWithHandler(ctx context.Context, handler EventHandlerE, opt *option.WatchWithHandler) error {
+	if helper.IsEmpty(opt.DatabaseName) {
+		return ErrDatabaseNotConfigured
+	} else if helper.IsEmpty(opt.CollectionName) {
+		return ErrCollectionNotConfigured
+	}
+	collection := t.client.Database(opt.DatabaseName).Collection(opt.CollectionName)
+	state, err := t.loadPollingState(ctx, collection, opt)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	ns := Namespace{DB: opt.DatabaseName, Coll: opt.CollectionName}
+	pool := newEventPool(ctx, handler, opt)
+	for helper.IsNil(pool.ctx.Err()) {
+		var read int
+		read, err = pollNextBatch(pool, collection, ns, state, opt)
+		if helper.IsNotNil(err) && helper.IsNil(pool.ctx.Err()) && !isResumableChangeStreamError(err) {
+			break
+		}
+		delay := opt.PollingInterval
+		if helper.IsNotNil(err) {
+			sendWatchError(opt, err)
+			logger.Error("watch handler polling interrupted, retrying in", opt.DelayLoop.String(), "err:", err)
+			delay = opt.DelayLoop
+		} else if helper.IsGreaterThanOrEqual(int64(read), opt.PollingBatchSize) {
+			continue
+		}
+		select {
+		case <-pool.ctx.Done():
+		case <-time.After(delay):
+		}
+		err = nil
+	}
+	errEvent := pool.wait()
+	if helper.IsNotNil(ctx.Err()) {
+		return nil
+	} else if helper.IsNotNil(errEvent) {
+		return errEvent
+	}
+	return err
+}
+
+func (t *Template) loadPollingState(ctx context.Context, collection *mongo.Collection,
+	opt *option.WatchWithHandler) (*pollingState, error) {
+	if helper.IsNotNil(opt.TokenStore) {
+		token, err := opt.TokenStore.Load(ctx, opt.TokenKey)
+		if helper.IsNotNil(err) {
+			return nil, err
+		} else if isPollingToken(token) {
+			return parsePollingToken(token)
+		}
+	}
+	// without checkpoint, the polling starts from the current last document, like a new change stream
+	state := &pollingState{}
+	last, err := collection.FindOne(ctx, pollingFilter(opt.PollingField, state), options.FindOne().
+		SetSort(pollingSort(opt.PollingField, -1)).
+		SetProjection(bson.M{opt.PollingField: 1})).Raw()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return state, nil
+	} else if helper.IsNotNil(err) {
+		return nil, err
+	}
+	state.Value = lookupPollingField(last, opt.PollingField)
+	state.ID = last.Lookup("_id")
+	if helper.Equals(opt.PollingField, "_id") {
+		state.MaxID = state.ID
+		return state, nil
+	}
+	last, err = collection.FindOne(ctx, bson.M{}, options.FindOne().
+		SetSort(bson.D{{"_id", -1}}).
+		SetProjection(bson.M{"_id": 1})).Raw()
+	if helper.IsNotNil(err) && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	} else if helper.IsNil(err) {
+		state.MaxID = last.Lookup("_id")
+	}
+	return state, nil
+}
+
+func pollNextBatch(pool *eventPool, collection *mongo.Collection, ns Namespace, state *pollingState,
+	opt *option.WatchWithHandler) (int, error) {
+	cursor, err := collection.Find(pool.ctx, pollingFilter(opt.PollingField, state), options.Find().
+		SetSort(pollingSort(opt.PollingField, 1)).
+		SetLimit(opt.PollingBatchSize))
+	if helper.IsNotNil(err) {
+		return 0, err
+	}
+	defer func() {
+		_ = cursor.Close(context.WithoutCancel(pool.ctx))
+	}()
+	var read int
+	for cursor.Next(pool.ctx) {
+		current, resumeToken, err := newPollingEvent(cursor.Current, ns, state, opt.PollingField)
+		if helper.IsNotNil(err) {
+			return read, err
+		} else if !pool.dispatch(current, resumeToken) {
+			return read, nil
+		}
+		read++
+	}
+	return read, cursor.Err()
+}
+
+// newPollingEvent synthesizes the change event of the document read by the polling, advancing the state, the
+// returned resume token is the state after the event.
+func newPollingEvent(document bson.Raw, ns Namespace, state *pollingState, field string) (bson.Raw, bson.Raw,
+	error) {
+	id := document.Lookup("_id")
+	operationType := OperationTypeUpdate
+	if objectId, ok := id.ObjectIDOK(); ok {
+		maxId, ok := state.MaxID.ObjectIDOK()
+		if !ok || helper.IsGreaterThan(bytes.Compare(objectId[:], maxId[:]), 0) {
+			operationType = OperationTypeInsert
+			state.MaxID = id
+		}
+	} else if helper.Equals(field, "_id") {
+		operationType = OperationTypeInsert
+	}
+	state.Value = lookupPollingField(document, field)
+	state.ID = id
+	resumeToken, err := bson.Marshal(bson.D{{"polling", state.document()}})
+	if helper.IsNotNil(err) {
+		return nil, nil, err
+	}
+	current, err := bson.Marshal(bson.D{
+		{"_id", bson.Raw(resumeToken)},
+		{"operationType", operationType},
+		{"wallTime", primitive.NewDateTimeFromTime(time.Now())},
+		{"ns", ns},
+		{"documentKey", bson.D{{"_id", id}}},
+		{"fullDocument", document},
+	})
+	if helper.IsNotNil(err) {
+		return nil, nil, err
+	}
+	return current, resumeToken, nil
+}
+
+// pollingFilter returns the filter of the documents written after the state, ordered by the polling field and _id.
+func pollingFilter(field string, state *pollingState) bson.D {
+	if helper.Equals(field, "_id") {
+		if state.ID.IsZero() {
+			return bson.D{}
+		}
+		return bson.D{{"_id", bson.D{{"$gt", state.ID}}}}
+	} else if state.Value.IsZero() {
+		return bson.D{{field, bson.D{{"$exists", true}}}}
+	}
+	return bson.D{{"$or", bson.A{
+		bson.D{{field, bson.D{{"$gt", state.Value}}}},
+		bson.D{{field, state.Value}, {"_id", bson.D{{"$gt", state.ID}}}},
+	}}}
+}
+
+func pollingSort(field string, order int) bson.D {
+	if helper.Equals(field, "_id") {
+		return bson.D{{"_id", order}}
+	}
+	return bson.D{{field, order}, {"_id", order}}
+}
+
+func lookupPollingField(document bson.Raw, field string) bson.RawValue {
+	value, _ := document.LookupErr(strings.Split(field, ".")...)
+	return value
+}
+
+func (p *pollingState) document() bson.D {
+	var result bson.D
+	for _, e := range []bson.E{{"value", p.Value}, {"id", p.ID}, {"maxId", p.MaxID}} {
+		if !e.Value.(bson.RawValue).IsZero() {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func parsePollingToken(token bson.Raw) (*pollingState, error) {
+	document, ok := token.Lookup("polling").DocumentOK()
+	if !ok {
+		return nil, ErrInvalidPollingToken
+	}
+	return &pollingState{
+		Value: document.Lookup("value"),
+		ID:    document.Lookup("id"),
+		MaxID: document.Lookup("maxId"),
+	}, nil
+}
+
+func isPollingToken(token bson.Raw) bool {
+	if helper.IsEmpty(token) {
+		return false
+	}
+	_, err := token.LookupErr("polling")
+	return helper.IsNil(err)
+}
+
+func isChangeStreamNotSupportedError(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(changeStreamNotSupportedCode)
+}
@@ -0,0 +1,182 @@
+package mongo
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// EventRouter dispatches the change stream events to handlers registered by operation type and collection, so a
+// single change stream serves many handlers, see Template.WatchWithRouter.
+type EventRouter struct {
+	routes []eventRoute
+	err    error
+}
+
+type eventRoute struct {
+	databaseName   string
+	collectionName string
+	operationTypes []OperationType
+	ddl            bool
+	handler        EventHandlerE
+}
+
+var dmlOperationTypes = []OperationType{
+	OperationTypeInsert,
+	OperationTypeUpdate,
+	OperationTypeReplace,
+	OperationTypeDelete,
+}
+
+// NewEventRouter creates a new EventRouter instance.
+func NewEventRouter() *EventRouter {
+	return &EventRouter{}
+}
+
+// OnInsert registers the handler for the insert events of the collection of the ref parameter, the ref parameter
+// must be the collection structure with database and collection tags configured.
+func (r *EventRouter) OnInsert(ref any, handler EventHandlerE) *EventRouter {
+	return r.on(ref, handler, false, OperationTypeInsert)
+}
+
+// OnUpdate registers the handler for the update events of the collection of the ref parameter, the ref parameter
+// must be the collection structure with database and collection tags configured.
+func (r *EventRouter) OnUpdate(ref any, handler EventHandlerE) *EventRouter {
+	return r.on(ref, handler, false, OperationTypeUpdate)
+}
+
+// OnReplace registers the handler for the replace events of the collection of the ref parameter, the ref parameter
+// must be the collection structure with database and collection tags configured.
+func (r *EventRouter) OnReplace(ref any, handler EventHandlerE) *EventRouter {
+	return r.on(ref, handler, false, OperationTypeReplace)
+}
+
+// OnDelete registers the handler for the delete events of the collection of the ref parameter, the ref parameter
+// must be the collection structure with database and collection tags configured.
+func (r *EventRouter) OnDelete(ref any, handler EventHandlerE) *EventRouter {
+	return r.on(ref, handler, false, OperationTypeDelete)
+}
+
+// OnDDL registers the handler for the data definition events of the collection of the ref parameter, e.g. drop,
+// rename and dropDatabase, the ref parameter must be the collection structure with database and collection tags
+// configured. The createIndexes, dropIndexes, modify, create and sharding events are only sent by the server with
+// option.WatchWithHandler.ShowExpandedEvents.
+func (r *EventRouter) OnDDL(ref any, handler EventHandlerE) *EventRouter {
+	return r.on(ref, handler, true)
+}
+
+// Err returns the first error of the registered routes, e.g. a ref without database or collection tag.
+func (r *EventRouter) Err() error {
+	return r.err
+}
+
+// Pipeline returns the change stream pipeline with the $match stage built from the registered routes.
+func (r *EventRouter) Pipeline() Pipeline {
+	var or bson.A
+	for _, route := range r.routes {
+		filter := bson.D{{"ns.db", route.databaseName}}
+		if route.ddl {
+			filter = append(filter,
+				bson.E{Key: "operationType", Value: bson.M{"$nin": dmlOperationTypes}},
+				bson.E{Key: "$or", Value: bson.A{
+					bson.M{"ns.coll": route.collectionName},
+					bson.M{"operationType": OperationTypeDropDatabase},
+				}},
+			)
+		} else {
+			filter = append(filter,
+				bson.E{Key: "ns.coll", Value: route.collectionName},
+				bson.E{Key: "operationType", Value: bson.M{"$in": route.operationTypes}},
+			)
+		}
+		or = append(or, filter)
+	}
+	return Pipeline{bson.D{{"$match", bson.D{{"$or", or}}}}}
+}
+
+// Handle dispatches the event to the handlers of the matching routes, in the order they were registered, returning
+// the first handler error. Events without matching route are ignored.
+func (r *EventRouter) Handle(ctx *EventContext) error {
+	for _, route := range r.routes {
+		if !route.match(ctx.Event) {
+			continue
+		}
+		if err := route.handler(ctx); helper.IsNotNil(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// WatchWithRouter executes WatchWithHandlerE with the pipeline and the handler of the router parameter. If all the
+// routes are on the same database and the opts parameter has no database name, the change stream is opened on that
+// database, otherwise on the whole deployment.
+//
+// The opts parameter can be used to specify options for change stream creation (see the option.WatchWithHandler
+// documentation), the CollectionName field is ignored, since the routes can be on different collections.
+func (t *Template) WatchWithRouter(ctx context.Context, router *EventRouter, opts ...*option.WatchWithHandler) error {
+	if helper.IsNil(router) || helper.IsEmpty(router.routes) {
+		return ErrEventRouterIsEmpty
+	} else if helper.IsNotNil(router.err) {
+		return router.err
+	}
+	opt := option.MergeWatchHandlerByParams(opts)
+	opt.CollectionName = ""
+	if helper.IsEmpty(opt.DatabaseName) {
+		opt.DatabaseName = router.databaseName()
+	}
+	return t.WatchWithHandlerE(ctx, router.Pipeline(), router.Handle, opt)
+}
+
+func (r *EventRouter) on(ref any, handler EventHandlerE, ddl bool, operationTypes ...OperationType) *EventRouter {
+	databaseName, collectionName, err := getMongoNamesByAny(ref)
+	if helper.IsNotNil(err) {
+		if helper.IsNil(r.err) {
+			r.err = err
+		}
+		return r
+	} else if handler == nil {
+		if helper.IsNil(r.err) {
+			r.err = ErrEventHandlerIsNil
+		}
+		return r
+	}
+	r.routes = append(r.routes, eventRoute{
+		databaseName:   databaseName,
+		collectionName: collectionName,
+		operationTypes: operationTypes,
+		ddl:            ddl,
+		handler:        handler,
+	})
+	return r
+}
+
+func (r *EventRouter) databaseName() string {
+	var result string
+	for i, route := range r.routes {
+		if helper.Equals(i, 0) {
+			result = route.databaseName
+		} else if helper.IsNotEqualTo(result, route.databaseName) {
+			return ""
+		}
+	}
+	return result
+}
+
+func (e eventRoute) match(event Event) bool {
+	if helper.IsNotEqualTo(e.databaseName, event.NS.DB) {
+		return false
+	} else if e.ddl {
+		return event.OperationType.IsDDL() && (helper.Equals(e.collectionName, event.NS.Coll) ||
+			helper.Equals(event.OperationType, OperationTypeDropDatabase))
+	} else if helper.IsNotEqualTo(e.collectionName, event.NS.Coll) {
+		return false
+	}
+	for _, operationType := range e.operationTypes {
+		if helper.Equals(operationType, event.OperationType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+package mongo
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	tObjectID   = reflect.TypeOf(primitive.ObjectID{})
+	tTime       = reflect.TypeOf(time.Time{})
+	tDateTime   = reflect.TypeOf(primitive.DateTime(0))
+	tDecimal128 = reflect.TypeOf(primitive.Decimal128{})
+	tTimestamp  = reflect.TypeOf(primitive.Timestamp{})
+	tBinary     = reflect.TypeOf(primitive.Binary{})
+	tRegex      = reflect.TypeOf(primitive.Regex{})
+	tRaw        = reflect.TypeOf(bson.Raw{})
+	tD          = reflect.TypeOf(primitive.D{})
+	tA          = reflect.TypeOf(primitive.A{})
+	tBytes      = reflect.TypeOf([]byte{})
+)
+
+// JSONSchemaFor builds a $jsonSchema validator document from the ref structure, ready to be used as the validator of
+// a collection (see Template.ApplyValidator).
+//
+// The ref parameter must be a structure or slice of the structure. The property names are read from the bson tags
+// and the bsonType of each property is inferred from the Go type of the field, pointers are considered optional and
+// also accept null, nested structures are described recursively and the fields with the validate:"required" tag are
+// listed as required. The min, max, len and oneof rules of the validate tag are converted to the length, items,
+// properties or value bounds, depending on the field type, and to the enum of the property.
+//
+// For more information about schema validation, see https://www.mongodb.com/docs/manual/core/schema-validation/.
+func JSONSchemaFor(ref any) (bson.M, error) {
+	t := reflect.TypeOf(ref)
+	for helper.IsNotNil(t) && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if helper.IsNil(t) || t.Kind() != reflect.Struct {
+		return nil, ErrRefDocument
+	}
+	return bson.M{"$jsonSchema": schemaForStruct(t, map[reflect.Type]bool{})}, nil
+}
+
+func schemaForStruct(t reflect.Type, visiting map[reflect.Type]bool) bson.M {
+	schema := bson.M{"bsonType": "object"}
+	if visiting[t] {
+		return schema
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+	properties := bson.M{}
+	var required []string
+	appendStructProperties(t, properties, &required, visiting)
+	if helper.IsNotEmpty(properties) {
+		schema["properties"] = properties
+	}
+	if helper.IsNotEmpty(required) {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func appendStructProperties(t reflect.Type, properties bson.M, required *[]string, visiting map[reflect.Type]bool) {
+	for i := 0; helper.IsLessThan(i, t.NumField()); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, inline, skip := parseBsonTag(field)
+		if skip {
+			continue
+		}
+		if inline {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				appendStructProperties(ft, properties, required, visiting)
+			}
+			continue
+		}
+		properties[name] = schemaForField(field, visiting)
+		if isFieldRequired(field) {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// schemaForField returns the schema of the field type with the keywords of the min, max, len and oneof rules of its
+// validate tag, the rules after dive apply to the elements and are ignored.
+func schemaForField(field reflect.StructField, visiting map[reflect.Type]bool) bson.M {
+	schema := schemaForType(field.Type, visiting)
+	t := field.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	minKey, maxKey := boundKeywords(t)
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		key, param, _ := strings.Cut(rule, "=")
+		switch key {
+		case "dive":
+			return schema
+		case "min", "gte":
+			setSchemaBound(schema, t, minKey, param)
+		case "max", "lte":
+			setSchemaBound(schema, t, maxKey, param)
+		case "len":
+			setSchemaBound(schema, t, minKey, param)
+			setSchemaBound(schema, t, maxKey, param)
+		case "oneof":
+			var enum bson.A
+			for _, value := range strings.Fields(param) {
+				if v, ok := parseSchemaValue(t, value); ok {
+					enum = append(enum, v)
+				}
+			}
+			if helper.IsNotEmpty(enum) && field.Type.Kind() == reflect.Pointer {
+				enum = append(enum, nil)
+			}
+			if helper.IsNotEmpty(enum) {
+				schema["enum"] = enum
+			}
+		}
+	}
+	return schema
+}
+
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) bson.M {
+	if t.Kind() == reflect.Pointer {
+		schema := schemaForType(t.Elem(), visiting)
+		if bsonType, ok := schema["bsonType"]; ok {
+			schema["bsonType"] = appendNullBsonType(bsonType)
+		}
+		return schema
+	}
+	switch t {
+	case tObjectID:
+		return bson.M{"bsonType": "objectId"}
+	case tTime, tDateTime:
+		return bson.M{"bsonType": "date"}
+	case tDecimal128:
+		return bson.M{"bsonType": "decimal"}
+	case tTimestamp:
+		return bson.M{"bsonType": "timestamp"}
+	case tBinary, tBytes:
+		return bson.M{"bsonType": "binData"}
+	case tRegex:
+		return bson.M{"bsonType": "regex"}
+	case tRaw, tD:
+		return bson.M{"bsonType": "object"}
+	case tA:
+		return bson.M{"bsonType": "array"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return bson.M{"bsonType": "string"}
+	case reflect.Bool:
+		return bson.M{"bsonType": "bool"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return bson.M{"bsonType": "int"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return bson.M{"bsonType": bson.A{"int", "long"}}
+	case reflect.Float32, reflect.Float64:
+		return bson.M{"bsonType": "double"}
+	case reflect.Slice, reflect.Array:
+		schema := bson.M{"bsonType": bson.A{"array", "null"}}
+		if t.Kind() == reflect.Array {
+			schema["bsonType"] = "array"
+		}
+		if items := schemaForType(t.Elem(), visiting); helper.IsNotEmpty(items) {
+			schema["items"] = items
+		}
+		return schema
+	case reflect.Map:
+		return bson.M{"bsonType": bson.A{"object", "null"}}
+	case reflect.Struct:
+		return schemaForStruct(t, visiting)
+	default:
+		return bson.M{}
+	}
+}
+
+func appendNullBsonType(bsonType any) any {
+	switch v := bsonType.(type) {
+	case string:
+		return bson.A{v, "null"}
+	case bson.A:
+		for _, s := range v {
+			if helper.Equals(s, "null") {
+				return v
+			}
+		}
+		return append(v, "null")
+	default:
+		return bsonType
+	}
+}
+
+func parseBsonTag(field reflect.StructField) (name string, inline, skip bool) {
+	tag, ok := field.Tag.Lookup("bson")
+	if !ok && !strings.Contains(string(field.Tag), ":") {
+		tag = string(field.Tag)
+	}
+	if helper.Equals(tag, "-") {
+		return "", false, true
+	}
+	split := strings.Split(tag, ",")
+	name = split[0]
+	for _, flag := range split[1:] {
+		if helper.Equals(flag, "inline") {
+			inline = true
+		}
+	}
+	if helper.IsEmpty(name) {
+		name = strings.ToLower(field.Name)
+	}
+	return name, inline, false
+}
+
+func isFieldRequired(field reflect.StructField) bool {
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		if helper.Equals(rule, "required") {
+			return true
+		}
+	}
+	return false
+}
+
+// boundKeywords returns the keywords of the min and max rules for the type, empty if they do not apply to it.
+func boundKeywords(t reflect.Type) (string, string) {
+	switch t {
+	case tObjectID, tDateTime, tBinary, tBytes:
+		return "", ""
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "minLength", "maxLength"
+	case reflect.Slice, reflect.Array:
+		return "minItems", "maxItems"
+	case reflect.Map:
+		return "minProperties", "maxProperties"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return "minimum", "maximum"
+	default:
+		return "", ""
+	}
+}
+
+func setSchemaBound(schema bson.M, t reflect.Type, key, param string) {
+	if helper.IsEmpty(key) {
+		return
+	}
+	if helper.Equals(key, "minimum") || helper.Equals(key, "maximum") {
+		if v, ok := parseSchemaValue(t, param); ok {
+			schema[key] = v
+		}
+	} else if v, err := strconv.ParseInt(param, 10, 64); helper.IsNil(err) {
+		schema[key] = v
+	}
+}
+
+// parseSchemaValue converts the value of a validate rule to the kind of the t parameter.
+func parseSchemaValue(t reflect.Type, value string) (any, bool) {
+	switch t.Kind() {
+	case reflect.String:
+		return value, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseInt(value, 10, 64)
+		return v, helper.IsNil(err)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(value, 64)
+		return v, helper.IsNil(err)
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,55 @@
+package mongo
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func TestJSONSchemaFor(t *testing.T) {
+	for _, tt := range initListTestJSONSchemaFor() {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := JSONSchemaFor(tt.ref)
+			if helper.IsNotEqualTo(helper.IsNotNil(err), tt.wantErr) {
+				t.Errorf("JSONSchemaFor() error = %v, wantErr %v", err, tt.wantErr)
+			} else if helper.IsNotNil(err) {
+				t.Log("err expected:", err)
+			} else if schema, ok := result["$jsonSchema"].(bson.M); !ok || helper.IsNotEqualTo(schema["bsonType"], "object") {
+				t.Errorf("JSONSchemaFor() = %v, want bsonType object", result)
+			} else if properties, _ := schema["properties"].(bson.M); helper.IsNotEqualTo(len(properties), 7) ||
+				helper.IsNotEqualTo(properties["_id"], bson.M{"bsonType": "objectId"}) {
+				t.Errorf("JSONSchemaFor() properties = %v", properties)
+			}
+		})
+	}
+}
+
+func TestJSONSchemaForValidate(t *testing.T) {
+	result, err := JSONSchemaFor(testSchemaStruct{})
+	if helper.IsNotNil(err) {
+		t.Fatal("JSONSchemaFor() error:", err)
+	}
+	want := bson.M{"$jsonSchema": bson.M{
+		"bsonType": "object",
+		"required": []string{"name", "emails"},
+		"properties": bson.M{
+			"_id":    bson.M{"bsonType": "objectId"},
+			"name":   bson.M{"bsonType": "string", "minLength": int64(2), "maxLength": int64(50)},
+			"code":   bson.M{"bsonType": "string", "minLength": int64(3), "maxLength": int64(3)},
+			"status": bson.M{"bsonType": "string", "enum": bson.A{"active", "inactive"}},
+			"age": bson.M{"bsonType": bson.A{"int", "long", "null"}, "minimum": int64(18),
+				"maximum": int64(120)},
+			"level":  bson.M{"bsonType": bson.A{"int", "null"}, "enum": bson.A{int64(1), int64(2), int64(3), nil}},
+			"score":  bson.M{"bsonType": "double", "maximum": 9.5},
+			"emails": bson.M{"bsonType": bson.A{"array", "null"}, "items": bson.M{"bsonType": "string"}, "minItems": int64(1)},
+			"address": bson.M{
+				"bsonType":   "object",
+				"required":   []string{"city"},
+				"properties": bson.M{"city": bson.M{"bsonType": "string"}},
+			},
+		},
+	}}
+	if helper.IsNotEqualTo(result, want) {
+		t.Errorf("JSONSchemaFor() = %v, want %v", result, want)
+	}
+}
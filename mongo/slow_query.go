@@ -0,0 +1,153 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
+	"time"
+)
+
+const slowQueryExplainTimeout = 10 * time.Second
+const slowQueryExplainConcurrency = 4
+
+// slowQueryExplains bounds the explains running in background, so a burst of slow queries does not add more load to
+// the server.
+var slowQueryExplains = make(chan struct{}, slowQueryExplainConcurrency)
+
+// slowQuery represents a query watched by the slow query log, see option.Global.SlowQueryThreshold.
+type slowQuery struct {
+	command    string
+	collection *mongo.Collection
+	filter     any
+	sort       any
+	pipeline   any
+	update     any
+	multi      bool
+	skip       *int64
+	limit      *int64
+	start      time.Time
+}
+
+// finish logs the query if its duration, since the start field, exceeds the global slow query threshold, the explain,
+// if enabled, runs in background, outside the operation session, unless the slowQueryExplains are busy.
+func (q slowQuery) finish() {
+	duration := time.Since(q.start)
+	threshold := globalOption.SlowQueryThreshold
+	if threshold <= 0 || duration < threshold || helper.IsNil(q.collection) {
+		return
+	}
+	if !globalOption.SlowQueryExplain {
+		logger.Warning(q.logValues(duration)...)
+		return
+	}
+	select {
+	case slowQueryExplains <- struct{}{}:
+	default:
+		logger.Warning(append(q.logValues(duration), "explainErr:", "skipped, too many explains running")...)
+		return
+	}
+	go func() {
+		defer func() {
+			<-slowQueryExplains
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), slowQueryExplainTimeout)
+		defer cancel()
+		v := q.logValues(duration)
+		result, err := runExplain(ctx, q.collection.Database(), q.explainCommand(), nil)
+		if helper.IsNotNil(err) {
+			v = append(v, "explainErr:", err)
+		} else {
+			v = append(v, "winningPlan:", strings.Join(result.Stages, " <- "), "docsExaminedRatio:",
+				result.DocsExaminedRatio())
+		}
+		logger.Warning(v...)
+	}()
+}
+
+func (q slowQuery) logValues(duration time.Duration) []any {
+	v := []any{"Slow query on mongoDB, namespace:", q.collection.Database().Name() + "." + q.collection.Name(),
+		"command:", q.command}
+	if helper.IsNotNil(q.pipeline) {
+		v = append(v, "pipeline:", extJSONString(sanitizeFilter(q.pipeline)))
+	} else {
+		v = append(v, "filter:", extJSONString(sanitizeFilter(q.filter)))
+	}
+	if helper.IsNotNil(q.sort) {
+		v = append(v, "sort:", extJSONString(q.sort))
+	}
+	return append(v, "duration:", duration)
+}
+
+// explainCommand returns the command of the query to be explained.
+func (q slowQuery) explainCommand() bson.D {
+	filter := q.filter
+	if helper.IsNil(filter) {
+		filter = bson.D{}
+	}
+	switch q.command {
+	case "aggregate":
+		return bson.D{{"aggregate", q.collection.Name()}, {"pipeline", q.pipeline}, {"cursor", bson.D{}}}
+	case "count":
+		return bson.D{{"count", q.collection.Name()}, {"query", filter}}
+	case "update":
+		return bson.D{{"update", q.collection.Name()}, {"updates", bson.A{
+			bson.D{{"q", filter}, {"u", q.update}, {"multi", q.multi}},
+		}}}
+	}
+	command := bson.D{{"find", q.collection.Name()}, {"filter", filter}}
+	if helper.IsNotNil(q.sort) {
+		command = append(command, bson.E{Key: "sort", Value: q.sort})
+	}
+	if helper.IsNotNil(q.skip) {
+		command = append(command, bson.E{Key: "skip", Value: *q.skip})
+	}
+	if helper.IsNotNil(q.limit) {
+		command = append(command, bson.E{Key: "limit", Value: *q.limit})
+	}
+	return command
+}
+
+// sanitizeFilter returns the filter parameter with its values replaced by "?", keeping the fields and operators.
+func sanitizeFilter(filter any) any {
+	if helper.IsNil(filter) {
+		return bson.D{}
+	}
+	raw, err := bson.Marshal(bson.D{{"filter", filter}})
+	if helper.IsNotNil(err) {
+		return "?"
+	}
+	return sanitizeValue(bson.Raw(raw).Lookup("filter"))
+}
+
+func sanitizeValue(value bson.RawValue) any {
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		elements, _ := value.Document().Elements()
+		result := bson.D{}
+		for _, element := range elements {
+			result = append(result, bson.E{Key: element.Key(), Value: sanitizeValue(element.Value())})
+		}
+		return result
+	case bsontype.Array:
+		values, _ := value.Array().Values()
+		result := bson.A{}
+		for _, v := range values {
+			result = append(result, sanitizeValue(v))
+		}
+		return result
+	}
+	return "?"
+}
+
+func extJSONString(v any) string {
+	b, err := bson.MarshalExtJSON(bson.D{{"v", v}}, false, false)
+	if helper.IsNotNil(err) {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(string(b), `{"v":`), "}")
+}
@@ -0,0 +1,91 @@
+package mongo
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"testing"
+	"time"
+)
+
+func TestSanitizeFilter(t *testing.T) {
+	got := extJSONString(sanitizeFilter(bson.M{
+		"$or": bson.A{bson.M{"name": "Ana"}, bson.M{"age": bson.M{"$gte": 30}}},
+	}))
+	want := `{"$or":[{"name":"?"},{"age":{"$gte":"?"}}]}`
+	if helper.IsNotEqualTo(got, want) {
+		t.Errorf("sanitizeFilter() = %v, want %v", got, want)
+	}
+	if got = extJSONString(sanitizeFilter(nil)); helper.IsNotEqualTo(got, "{}") {
+		t.Errorf("sanitizeFilter() nil = %v, want {}", got)
+	}
+}
+
+func TestSlowQueryExplainCommand(t *testing.T) {
+	client, _ := mongo.NewClient(options.Client())
+	collection := client.Database("test").Collection("test")
+	q := slowQuery{command: "find", collection: collection, filter: bson.M{"name": "Ana"}, sort: bson.M{"age": 1},
+		skip: helper.ConvertToPointer[int64](20), limit: helper.ConvertToPointer[int64](10)}
+	want := bson.D{{"find", "test"}, {"filter", bson.M{"name": "Ana"}}, {"sort", bson.M{"age": 1}}, {"skip", int64(20)},
+		{"limit", int64(10)}}
+	if got := q.explainCommand(); helper.IsNotEqualTo(got, want) {
+		t.Errorf("explainCommand() = %v, want %v", got, want)
+	}
+	q = slowQuery{command: "update", collection: collection, update: bson.M{"$set": bson.M{"age": 2}}, multi: true}
+	want = bson.D{{"update", "test"}, {"updates", bson.A{
+		bson.D{{"q", bson.D{}}, {"u", bson.M{"$set": bson.M{"age": 2}}}, {"multi", true}},
+	}}}
+	if got := q.explainCommand(); helper.IsNotEqualTo(got, want) {
+		t.Errorf("explainCommand() = %v, want %v", got, want)
+	}
+}
+
+func TestSlowQueryExplainBusy(t *testing.T) {
+	defer func(global *option.Global) {
+		globalOption = global
+	}(globalOption)
+	globalOption = &option.Global{SlowQueryThreshold: time.Nanosecond, SlowQueryExplain: true}
+	for i := 0; i < slowQueryExplainConcurrency; i++ {
+		slowQueryExplains <- struct{}{}
+	}
+	defer func() {
+		for i := 0; i < slowQueryExplainConcurrency; i++ {
+			<-slowQueryExplains
+		}
+	}()
+	client, _ := mongo.NewClient(options.Client())
+	done := make(chan struct{})
+	go func() {
+		slowQuery{command: "find", collection: client.Database("test").Collection("test"),
+			start: time.Now().Add(-time.Second)}.finish()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("finish() blocked with the explains busy")
+	}
+	if helper.IsNotEqualTo(len(slowQueryExplains), slowQueryExplainConcurrency) {
+		t.Errorf("finish() explains = %v, want %v", len(slowQueryExplains), slowQueryExplainConcurrency)
+	}
+}
+
+func TestTemplateSlowQuery(t *testing.T) {
+	initDocument()
+	mongoTemplate.SetGlobalOption(&option.Global{SlowQueryThreshold: time.Nanosecond, SlowQueryExplain: true})
+	defer mongoTemplate.SetGlobalOption(nil)
+	var dest []testStruct
+	err := mongoTemplate.Find(context.TODO(), bson.M{"name": bson.M{"$exists": true}}, &dest,
+		option.NewFind().SetSort(bson.M{"_id": -1}))
+	if helper.IsNotNil(err) {
+		t.Error("Find() error:", err)
+	}
+	_, err = mongoTemplate.CountDocuments(context.TODO(), bson.M{"name": bson.M{"$exists": true}}, testStruct{})
+	if helper.IsNotNil(err) {
+		t.Error("CountDocuments() error:", err)
+	}
+	time.Sleep(time.Second)
+}
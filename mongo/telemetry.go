@@ -0,0 +1,211 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const instrumentationName = "github.com/GabrielHCataldo/go-mongo-template/mongo"
+
+// telemetry creates the spans and records the metrics of the Template operations, see NewTemplateWithTelemetry.
+type telemetry struct {
+	tracer       trace.Tracer
+	duration     metric.Float64Histogram
+	errors       metric.Int64Counter
+	commandSpans sync.Map
+}
+
+// NewTemplateWithTelemetry creates the Template like NewTemplate, instrumented with OpenTelemetry.
+//
+// Each public method creates a span, named by the method and the namespace, with the db.system, db.name,
+// db.mongodb.collection, db.operation and, on the CRUD operations, db.mongodb.document_count attributes, and records
+// its duration on the db.client.operation.duration histogram and its failures on the db.client.operation.errors
+// counter. The server commands are traced as child spans of the method span, and the events of WatchWithHandler,
+// WatchWithHandlerE and WatchWithRouter create a span for each handler call.
+//
+// The command monitor of the opts parameter, if any, keeps being called.
+//
+// The telemetry parameter can be used to specify the providers (see the option.Telemetry documentation).
+func NewTemplateWithTelemetry(ctx context.Context, telemetryOpt *option.Telemetry, opts ...*options.ClientOptions) (
+	*Template, error) {
+	opt := option.MergeTelemetryByParams([]*option.Telemetry{telemetryOpt})
+	tel := newTelemetry(opt)
+	if !*opt.DisableCommandSpans {
+		var next *event.CommandMonitor
+		for _, clientOpt := range opts {
+			if helper.IsNotNil(clientOpt) && helper.IsNotNil(clientOpt.Monitor) {
+				next = clientOpt.Monitor
+			}
+		}
+		opts = append(opts, options.Client().SetMonitor(tel.commandMonitor(next)))
+	}
+	t, err := NewTemplate(ctx, opts...)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	t.telemetry = tel
+	return t, nil
+}
+
+func newTelemetry(opt *option.Telemetry) *telemetry {
+	meter := opt.MeterProvider.Meter(instrumentationName)
+	duration, err := meter.Float64Histogram("db.client.operation.duration", metric.WithUnit("s"),
+		metric.WithDescription("Duration of the Template operations."))
+	if helper.IsNotNil(err) {
+		duration = noop.Float64Histogram{}
+	}
+	errorsCounter, err := meter.Int64Counter("db.client.operation.errors",
+		metric.WithDescription("Number of failed Template operations."))
+	if helper.IsNotNil(err) {
+		errorsCounter = noop.Int64Counter{}
+	}
+	return &telemetry{
+		tracer:   opt.TracerProvider.Tracer(instrumentationName),
+		duration: duration,
+		errors:   errorsCounter,
+	}
+}
+
+// middleware creates the span of the operation, records its metrics and calls the next func on the span context.
+// The document count is taken from the Result of the operation, or from its Ref, see documentCount.
+func (tel *telemetry) middleware(ctx context.Context, op *Operation, next OperationFunc) error {
+	ctx, end := tel.start(ctx, op)
+	err := next(ctx, op)
+	counted := op.Result
+	if helper.IsNil(counted) {
+		counted = op.Ref
+	}
+	end(counted, err)
+	return err
+}
+
+// start starts the span of the operation, returning the func that ends it and records the metrics.
+func (tel *telemetry) start(ctx context.Context, op *Operation) (context.Context, func(counted any, err error)) {
+	attributes := []attribute.KeyValue{
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.operation", op.Name),
+	}
+	spanName := op.Name
+	if helper.IsNotEmpty(op.Namespace.Coll) {
+		attributes = append(attributes, attribute.String("db.name", op.Namespace.DB),
+			attribute.String("db.mongodb.collection", op.Namespace.Coll))
+		spanName += " " + op.Namespace.DB + "." + op.Namespace.Coll
+	}
+	startTime := time.Now()
+	ctx, span := tel.tracer.Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...))
+	return ctx, func(counted any, err error) {
+		failed := helper.IsNotNil(err) && !errors.Is(err, ErrNoDocuments)
+		if failed {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			tel.errors.Add(context.WithoutCancel(ctx), 1, metric.WithAttributes(attributes...))
+		} else if count, ok := documentCount(counted); ok && helper.IsNil(err) {
+			span.SetAttributes(attribute.Int64("db.mongodb.document_count", count))
+		}
+		span.End()
+		tel.duration.Record(context.WithoutCancel(ctx), time.Since(startTime).Seconds(),
+			metric.WithAttributes(attributes...))
+	}
+}
+
+// eventHandler returns the handler that calls the handler parameter on a span for each event.
+func (tel *telemetry) eventHandler(name string, handler EventHandlerE) EventHandlerE {
+	return func(ctx *EventContext) error {
+		spanCtx, span := tel.tracer.Start(ctx.Context, name+" event", trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(
+				attribute.String("db.system", "mongodb"),
+				attribute.String("db.operation", string(ctx.Event.OperationType)),
+				attribute.String("db.name", ctx.Event.NS.DB),
+				attribute.String("db.mongodb.collection", ctx.Event.NS.Coll),
+			))
+		defer span.End()
+		err := handler(&EventContext{Context: spanCtx, Event: ctx.Event, Raw: ctx.Raw})
+		if helper.IsNotNil(err) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	}
+}
+
+// commandMonitor returns the monitor that traces the server commands as child spans of the context span, calling
+// the next monitor parameter, if any.
+func (tel *telemetry) commandMonitor(next *event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			_, span := tel.tracer.Start(ctx, evt.CommandName, trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("db.system", "mongodb"),
+					attribute.String("db.name", evt.DatabaseName),
+					attribute.String("db.operation", evt.CommandName),
+					attribute.String("db.mongodb.connection_id", evt.ConnectionID),
+				))
+			tel.commandSpans.Store(evt.RequestID, span)
+			if helper.IsNotNil(next) && next.Started != nil {
+				next.Started(ctx, evt)
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			if span, ok := tel.commandSpans.LoadAndDelete(evt.RequestID); ok {
+				span.(trace.Span).End()
+			}
+			if helper.IsNotNil(next) && next.Succeeded != nil {
+				next.Succeeded(ctx, evt)
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			if span, ok := tel.commandSpans.LoadAndDelete(evt.RequestID); ok {
+				span.(trace.Span).SetStatus(codes.Error, evt.Failure)
+				span.(trace.Span).End()
+			}
+			if helper.IsNotNil(next) && next.Failed != nil {
+				next.Failed(ctx, evt)
+			}
+		},
+	}
+}
+
+// documentCount returns the number of documents of the operation result, or of its ref, a pointer to a struct
+// counts as one document.
+func documentCount(counted any) (int64, bool) {
+	switch result := counted.(type) {
+	case *DeleteResult:
+		if helper.IsNotNil(result) {
+			return result.DeletedCount, true
+		}
+	case *UpdateResult:
+		if helper.IsNotNil(result) {
+			return result.MatchedCount + result.UpsertedCount, true
+		}
+	case *PageResult:
+		if helper.IsNotNil(result) {
+			return int64(len(result.Content)), true
+		}
+	case int64:
+		return result, true
+	}
+	v := reflect.ValueOf(counted)
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		if v.Elem().Kind() == reflect.Struct {
+			return 1, true
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice {
+		return int64(v.Len()), true
+	}
+	return 0, false
+}
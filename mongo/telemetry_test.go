@@ -0,0 +1,122 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+func initTestTelemetry() (*Template, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	opt := option.MergeTelemetryByParams([]*option.Telemetry{option.NewTelemetry().
+		SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))).
+		SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))})
+	return &Template{telemetry: newTelemetry(opt)}, recorder, reader
+}
+
+func TestTelemetryMiddleware(t *testing.T) {
+	template, recorder, reader := initTestTelemetry()
+	ctx := context.TODO()
+	var dest []testStruct
+	err := template.execute(ctx, &Operation{Name: "Find", Ref: &dest}, func(ctx context.Context, op *Operation) error {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			t.Error("execute() ctx without span")
+		}
+		dest = append(dest, testStruct{}, testStruct{})
+		return nil
+	})
+	if helper.IsNotNil(err) {
+		t.Fatalf("execute() error = %v", err)
+	}
+	err = template.Aggregate(ctx, Pipeline{}, testStruct{})
+	if helper.IsNotEqualTo(err, ErrDestIsNotPointer) {
+		t.Fatalf("Aggregate() error = %v, want %v", err, ErrDestIsNotPointer)
+	}
+	spans := recorder.Ended()
+	if helper.IsNotEqualTo(len(spans), 2) {
+		t.Fatalf("Ended() spans = %v, want 2", len(spans))
+	}
+	if helper.IsNotEqualTo(spans[0].Name(), "Find test.test") {
+		t.Errorf("span name = %v", spans[0].Name())
+	}
+	wantCount := attribute.Int64("db.mongodb.document_count", 2)
+	found := false
+	for _, kv := range spans[0].Attributes() {
+		found = found || helper.Equals(kv, wantCount)
+	}
+	if !found {
+		t.Errorf("span attributes = %v, want %v", spans[0].Attributes(), wantCount)
+	}
+	if helper.IsNotEqualTo(spans[1].Status().Code, codes.Error) {
+		t.Errorf("span status = %v, want error", spans[1].Status())
+	}
+	var metrics metricdata.ResourceMetrics
+	if err = reader.Collect(ctx, &metrics); helper.IsNotNil(err) {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	names := map[string]bool{}
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			names[m.Name] = true
+		}
+	}
+	if !names["db.client.operation.duration"] || !names["db.client.operation.errors"] {
+		t.Errorf("Collect() metrics = %v", names)
+	}
+}
+
+func TestTelemetryEventHandler(t *testing.T) {
+	template, recorder, _ := initTestTelemetry()
+	handlerErr := errors.New("test error")
+	handler := template.telemetry.eventHandler("WatchWithHandler", func(ctx *EventContext) error {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			t.Error("eventHandler() ctx without span")
+		}
+		return handlerErr
+	})
+	event := Event{}
+	event.OperationType = OperationTypeInsert
+	event.NS = Namespace{DB: "test", Coll: "test"}
+	if err := handler(&EventContext{Context: context.TODO(), Event: event}); helper.IsNotEqualTo(err, handlerErr) {
+		t.Errorf("handler() error = %v, want %v", err, handlerErr)
+	}
+	spans := recorder.Ended()
+	if helper.IsNotEqualTo(len(spans), 1) || helper.IsNotEqualTo(spans[0].Status().Code, codes.Error) {
+		t.Errorf("Ended() spans = %v", spans)
+	}
+}
+
+func TestDocumentCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		counted any
+		want    int64
+		wantOk  bool
+	}{
+		{"delete result", &DeleteResult{DeletedCount: 3}, 3, true},
+		{"update result", &UpdateResult{MatchedCount: 1, UpsertedCount: 1}, 2, true},
+		{"count", int64(7), 7, true},
+		{"struct pointer", &testStruct{}, 1, true},
+		{"slice pointer", &[]testStruct{{}, {}}, 2, true},
+		{"struct", testStruct{}, 0, false},
+		{"nil", nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := documentCount(tt.counted)
+			if helper.IsNotEqualTo(got, tt.want) || helper.IsNotEqualTo(ok, tt.wantOk) {
+				t.Errorf("documentCount() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
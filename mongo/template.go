@@ -7,11 +7,17 @@ import (
 	"github.com/GabrielHCataldo/go-logger/logger"
 	"github.com/GabrielHCataldo/go-mongo-template/internal/util"
 	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Pipeline is a type that makes creating aggregation pipelines easier. It is a
@@ -68,8 +74,16 @@ type IndexSpecification struct {
 }
 
 type Template struct {
-	client  *mongo.Client
-	session mongo.Session
+	client            *mongo.Client
+	session           mongo.Session
+	pendingEvictions  []func(ctx context.Context)
+	cache             atomic.Pointer[templateCache]
+	telemetry         *telemetry
+	middlewares       []Middleware
+	audit             *option.Audit
+	versionIndexes    sync.Map
+	lastClusterTime   bson.Raw
+	lastOperationTime *primitive.Timestamp
 }
 
 var globalOption = &option.Global{}
@@ -107,15 +121,21 @@ func (t *Template) SetGlobalOption(opt *option.Global) {
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/insert/.
 func (t *Template) InsertOne(ctx context.Context, document any, opts ...*option.InsertOne) error {
-	opt := option.MergeInsertOneByParams(opts, globalOption)
-	err := t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			err = t.insertOne(sc, document, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return err
+	op := &Operation{Name: "InsertOne", Ref: document, Documents: document, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		opt := option.MergeInsertOneByParams(operationOptions(op, opts), globalOption)
+		err := t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				err = t.insertOne(sc, op.Documents, opt)
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheByRef(ctx, op.Documents, false)
+		}
+		return err
+	})
 }
 
 // InsertMany executes an insert command to insert multiple documents into the collection. If recording errors occur
@@ -129,15 +149,24 @@ func (t *Template) InsertOne(ctx context.Context, document any, opts ...*option.
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/insert/.
 func (t *Template) InsertMany(ctx context.Context, documents any, opts ...*option.InsertMany) error {
-	opt := option.MergeInsertManyByParams(opts, globalOption)
-	err := t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			err = t.insertMany(sc, documents, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return err
+	op := &Operation{Name: "InsertMany", Ref: documents, Documents: documents, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		opt := option.MergeInsertManyByParams(operationOptions(op, opts), globalOption)
+		err := t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				err = t.insertMany(sc, op.Documents, opt)
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			documents := reflect.ValueOf(op.Documents)
+			for i := 0; helper.IsLessThan(i, documents.Len()); i++ {
+				t.evictCacheByRef(ctx, documents.Index(i).Interface(), false)
+			}
+		}
+		return err
+	})
 }
 
 // DeleteOne executes a delete command to delete at most one document from the collection.
@@ -153,17 +182,23 @@ func (t *Template) InsertMany(ctx context.Context, documents any, opts ...*optio
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/delete/.
 func (t *Template) DeleteOne(ctx context.Context, filter, ref any, opts ...*option.Delete) (*DeleteResult, error) {
-	var result *DeleteResult
-	var err error
-	opt := option.MergeDeleteByParams(opts, globalOption)
-	err = t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			result, err = t.deleteOne(sc, filter, ref, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return result, err
+	op := &Operation{Name: "DeleteOne", Ref: ref, Filter: filter, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*DeleteResult, error) {
+		var result *DeleteResult
+		var err error
+		opt := option.MergeDeleteByParams(operationOptions(op, opts), globalOption)
+		err = t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				result, err = t.deleteOne(sc, op.Filter, ref, opt)
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheByRef(ctx, ref, true)
+		}
+		return result, err
+	})
 }
 
 // DeleteOneById executes an update command to update the document whose _id value matches the provided ID in the collection.
@@ -178,17 +213,23 @@ func (t *Template) DeleteOne(ctx context.Context, filter, ref any, opts ...*opti
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/delete/.
 func (t *Template) DeleteOneById(ctx context.Context, id, ref any, opts ...*option.Delete) (*DeleteResult, error) {
-	var result *DeleteResult
-	var err error
-	opt := option.MergeDeleteByParams(opts, globalOption)
-	err = t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			result, err = t.deleteOne(sc, bson.D{{"_id", id}}, ref, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return result, err
+	op := &Operation{Name: "DeleteOneById", Ref: ref, Filter: bson.D{{"_id", id}}, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*DeleteResult, error) {
+		var result *DeleteResult
+		var err error
+		opt := option.MergeDeleteByParams(operationOptions(op, opts), globalOption)
+		err = t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				result, err = t.deleteOne(sc, op.Filter, ref, opt)
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheById(ctx, ref, id)
+		}
+		return result, err
+	})
 }
 
 // DeleteMany executes a delete command to delete documents from the collection.
@@ -204,17 +245,23 @@ func (t *Template) DeleteOneById(ctx context.Context, id, ref any, opts ...*opti
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/delete/.
 func (t *Template) DeleteMany(ctx context.Context, filter, ref any, opts ...*option.Delete) (*DeleteResult, error) {
-	var result *DeleteResult
-	var err error
-	opt := option.MergeDeleteByParams(opts, globalOption)
-	err = t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			result, err = t.deleteMany(sc, filter, ref, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return result, err
+	op := &Operation{Name: "DeleteMany", Ref: ref, Filter: filter, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*DeleteResult, error) {
+		var result *DeleteResult
+		var err error
+		opt := option.MergeDeleteByParams(operationOptions(op, opts), globalOption)
+		err = t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				result, err = t.deleteMany(sc, op.Filter, ref, opt)
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheByRef(ctx, ref, true)
+		}
+		return result, err
+	})
 }
 
 // UpdateOneById executes an update command to update the document whose _id value matches the provided ID in the collection.
@@ -233,17 +280,33 @@ func (t *Template) DeleteMany(ctx context.Context, filter, ref any, opts ...*opt
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/update/.
 func (t *Template) UpdateOneById(ctx context.Context, id, update, ref any, opts ...*option.Update) (*UpdateResult, error) {
-	var result *UpdateResult
-	var err error
-	opt := option.MergeUpdateByParams(opts, globalOption)
-	err = t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			result, err = t.updateOne(sc, bson.D{{"_id", id}}, update, ref, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return result, err
+	op := &Operation{Name: "UpdateOneById", Ref: ref, Filter: bson.D{{"_id", id}}, Update: update, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*UpdateResult, error) {
+		var result *UpdateResult
+		var err error
+		opt := option.MergeUpdateByParams(operationOptions(op, opts), globalOption)
+		err = t.ensureVersionIndex(ctx, ref)
+		if helper.IsNil(err) {
+			err = t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		}
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				var write *versionWrite
+				write, err = t.beginVersion(sc, op.Filter, ref)
+				if helper.IsNil(err) {
+					result, err = t.updateOne(sc, op.Filter, op.Update, ref, opt)
+				}
+				if helper.IsNil(err) {
+					err = write.commit(sc, result)
+				}
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheById(ctx, ref, id)
+		}
+		return result, err
+	})
 }
 
 // UpdateOne executes an update command to update at most one document in the collection.
@@ -264,17 +327,23 @@ func (t *Template) UpdateOneById(ctx context.Context, id, update, ref any, opts
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/update/.
 func (t *Template) UpdateOne(ctx context.Context, filter any, update, ref any, opts ...*option.Update) (*UpdateResult,
 	error) {
-	var result *UpdateResult
-	var err error
-	opt := option.MergeUpdateByParams(opts, globalOption)
-	err = t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			result, err = t.updateOne(sc, filter, update, ref, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return result, err
+	op := &Operation{Name: "UpdateOne", Ref: ref, Filter: filter, Update: update, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*UpdateResult, error) {
+		var result *UpdateResult
+		var err error
+		opt := option.MergeUpdateByParams(operationOptions(op, opts), globalOption)
+		err = t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				result, err = t.updateOne(sc, op.Filter, op.Update, ref, opt)
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheByRef(ctx, ref, true)
+		}
+		return result, err
+	})
 }
 
 // UpdateMany executes an update command to update documents in the collection.
@@ -294,17 +363,23 @@ func (t *Template) UpdateOne(ctx context.Context, filter any, update, ref any, o
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/update/.
 func (t *Template) UpdateMany(ctx context.Context, filter any, update, ref any, opts ...*option.Update) (*UpdateResult,
 	error) {
-	var result *UpdateResult
-	var err error
-	opt := option.MergeUpdateByParams(opts, globalOption)
-	err = t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			result, err = t.updateMany(sc, filter, update, ref, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return result, err
+	op := &Operation{Name: "UpdateMany", Ref: ref, Filter: filter, Update: update, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*UpdateResult, error) {
+		var result *UpdateResult
+		var err error
+		opt := option.MergeUpdateByParams(operationOptions(op, opts), globalOption)
+		err = t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				result, err = t.updateMany(sc, op.Filter, op.Update, ref, opt)
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheByRef(ctx, ref, true)
+		}
+		return result, err
+	})
 }
 
 // ReplaceOne executes an update command to replace at most one document in the collection.
@@ -324,17 +399,23 @@ func (t *Template) UpdateMany(ctx context.Context, filter any, update, ref any,
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/update/.
 func (t *Template) ReplaceOne(ctx context.Context, filter any, update, ref any, opts ...*option.Replace) (*UpdateResult,
 	error) {
-	var result *UpdateResult
-	var err error
-	opt := option.MergeReplaceByParams(opts, globalOption)
-	err = t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			result, err = t.replaceOne(sc, filter, update, ref, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return result, err
+	op := &Operation{Name: "ReplaceOne", Ref: ref, Filter: filter, Update: update, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*UpdateResult, error) {
+		var result *UpdateResult
+		var err error
+		opt := option.MergeReplaceByParams(operationOptions(op, opts), globalOption)
+		err = t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				result, err = t.replaceOne(sc, op.Filter, op.Update, ref, opt)
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheByRef(ctx, ref, true)
+		}
+		return result, err
+	})
 }
 
 // ReplaceOneById executes an update command to update the document whose _id value matches the provided ID in the collection.
@@ -348,17 +429,33 @@ func (t *Template) ReplaceOne(ctx context.Context, filter any, update, ref any,
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/update/.
 func (t *Template) ReplaceOneById(ctx context.Context, id, replacement, ref any, opts ...*option.Replace) (*UpdateResult,
 	error) {
-	var result *UpdateResult
-	var err error
-	opt := option.MergeReplaceByParams(opts, globalOption)
-	err = t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			result, err = t.replaceOne(sc, bson.D{{"_id", id}}, replacement, ref, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return result, err
+	op := &Operation{Name: "ReplaceOneById", Ref: ref, Filter: bson.D{{"_id", id}}, Update: replacement, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*UpdateResult, error) {
+		var result *UpdateResult
+		var err error
+		opt := option.MergeReplaceByParams(operationOptions(op, opts), globalOption)
+		err = t.ensureVersionIndex(ctx, ref)
+		if helper.IsNil(err) {
+			err = t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		}
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				var write *versionWrite
+				write, err = t.beginVersion(sc, op.Filter, ref)
+				if helper.IsNil(err) {
+					result, err = t.replaceOne(sc, op.Filter, op.Update, ref, opt)
+				}
+				if helper.IsNil(err) {
+					err = write.commit(sc, result)
+				}
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheById(ctx, ref, id)
+		}
+		return result, err
+	})
 }
 
 // FindOneById executes a search command whose _id value matches the ID given in the collection.
@@ -370,22 +467,31 @@ func (t *Template) ReplaceOneById(ctx context.Context, id, replacement, ref any,
 // The dest parameter must be a pointer to the return expected by the operation, it is important to have the
 // database and collection tags configured.
 //
+// If a cache is configured by SetCache, the document is read through it, unless option.FindOneById.DisableCache.
+//
 // The opts parameter can be used to specify options for this operation (see the option.FindOneById documentation).
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/find/.
 func (t *Template) FindOneById(ctx context.Context, id, dest any, opts ...*option.FindOneById) error {
-	opt := option.MergeFindOneByIdByParams(opts)
-	return t.findOne(ctx, bson.D{{"_id", id}}, dest, &option.FindOne{
-		AllowPartialResults: opt.AllowPartialResults,
-		Collation:           opt.Collation,
-		Comment:             opt.Comment,
-		Hint:                opt.Hint,
-		Max:                 opt.Max,
-		MaxTime:             opt.MaxTime,
-		Min:                 opt.Min,
-		Projection:          opt.Projection,
-		ReturnKey:           opt.ReturnKey,
-		ShowRecordID:        opt.ShowRecordID,
+	op := &Operation{Name: "FindOneById", Ref: dest, Filter: bson.D{{"_id", id}}, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		opt := option.MergeFindOneByIdByParams(operationOptions(op, opts))
+		return t.findOne(ctx, op.Filter, dest, &option.FindOne{
+			AllowPartialResults: opt.AllowPartialResults,
+			Collation:           opt.Collation,
+			Comment:             opt.Comment,
+			Hint:                opt.Hint,
+			Max:                 opt.Max,
+			MaxTime:             opt.MaxTime,
+			Min:                 opt.Min,
+			Projection:          opt.Projection,
+			ReturnKey:           opt.ReturnKey,
+			ShowRecordID:        opt.ShowRecordID,
+			DisableCache:        opt.DisableCache,
+			ReadPreference:      opt.ReadPreference,
+			ReadConcern:         opt.ReadConcern,
+			CausalConsistency:   opt.CausalConsistency,
+		})
 	})
 }
 
@@ -398,11 +504,16 @@ func (t *Template) FindOneById(ctx context.Context, id, dest any, opts ...*optio
 // The dest parameter must be a pointer to the return expected by the operation, it is important to have the
 // database and collection tags configured.
 //
+// If a cache is configured by SetCache, the document is read through it, unless option.FindOne.DisableCache.
+//
 // The opts parameter can be used to specify options for this operation (see the option.FindOne documentation).
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/find/.
 func (t *Template) FindOne(ctx context.Context, filter, dest any, opts ...*option.FindOne) error {
-	return t.findOne(ctx, filter, dest, opts...)
+	op := &Operation{Name: "FindOne", Ref: dest, Filter: filter, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		return t.findOne(ctx, op.Filter, dest, operationOptions(op, opts)...)
+	})
 }
 
 // FindOneAndDeleteById executes a findAndModify command whose _id value matches the ID given in the collection.
@@ -419,15 +530,21 @@ func (t *Template) FindOne(ctx context.Context, filter, dest any, opts ...*optio
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/findAndModify/.
 func (t *Template) FindOneAndDeleteById(ctx context.Context, id, dest any, opts ...*option.FindOneAndDelete) error {
-	opt := option.MergeFindOneAndDeleteByParams(opts, globalOption)
-	err := t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			err = t.findOneAndDelete(sc, bson.D{{"_id", id}}, dest, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return err
+	op := &Operation{Name: "FindOneAndDeleteById", Ref: dest, Filter: bson.D{{"_id", id}}, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		opt := option.MergeFindOneAndDeleteByParams(operationOptions(op, opts), globalOption)
+		err := t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				err = t.findOneAndDelete(sc, op.Filter, dest, opt)
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheById(ctx, dest, id)
+		}
+		return err
+	})
 }
 
 // FindOneAndDelete executes a findAndModify command to delete at most one document from the collection. and returns the
@@ -444,15 +561,21 @@ func (t *Template) FindOneAndDeleteById(ctx context.Context, id, dest any, opts
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/findAndModify/.
 func (t *Template) FindOneAndDelete(ctx context.Context, filter, dest any, opts ...*option.FindOneAndDelete) error {
-	opt := option.MergeFindOneAndDeleteByParams(opts, globalOption)
-	err := t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			err = t.findOneAndDelete(sc, filter, dest, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return err
+	op := &Operation{Name: "FindOneAndDelete", Ref: dest, Filter: filter, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		opt := option.MergeFindOneAndDeleteByParams(operationOptions(op, opts), globalOption)
+		err := t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				err = t.findOneAndDelete(sc, op.Filter, dest, opt)
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheByRef(ctx, dest, true)
+		}
+		return err
+	})
 }
 
 // FindOneAndReplaceById executes a findAndModify command whose _id value matches the ID given in the collection.
@@ -472,15 +595,22 @@ func (t *Template) FindOneAndDelete(ctx context.Context, filter, dest any, opts
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/findAndModify/.
 func (t *Template) FindOneAndReplaceById(ctx context.Context, id, replacement, dest any, opts ...*option.FindOneAndReplace) error {
-	opt := option.MergeFindOneAndReplaceByParams(opts, globalOption)
-	err := t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			err = t.findOneAndReplace(sc, bson.D{{"_id", id}}, replacement, dest, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return err
+	op := &Operation{Name: "FindOneAndReplaceById", Ref: dest, Filter: bson.D{{"_id", id}}, Update: replacement,
+		Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		opt := option.MergeFindOneAndReplaceByParams(operationOptions(op, opts), globalOption)
+		err := t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				err = t.findOneAndReplace(sc, op.Filter, op.Update, dest, opt)
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheById(ctx, dest, id)
+		}
+		return err
+	})
 }
 
 // FindOneAndReplace executes a findAndModify command to replace at most one document in the collection
@@ -500,15 +630,21 @@ func (t *Template) FindOneAndReplaceById(ctx context.Context, id, replacement, d
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/findAndModify/.
 func (t *Template) FindOneAndReplace(ctx context.Context, filter, replacement, dest any, opts ...*option.FindOneAndReplace) error {
-	opt := option.MergeFindOneAndReplaceByParams(opts, globalOption)
-	err := t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			err = t.findOneAndReplace(sc, filter, replacement, dest, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return err
+	op := &Operation{Name: "FindOneAndReplace", Ref: dest, Filter: filter, Update: replacement, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		opt := option.MergeFindOneAndReplaceByParams(operationOptions(op, opts), globalOption)
+		err := t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				err = t.findOneAndReplace(sc, op.Filter, op.Update, dest, opt)
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheByRef(ctx, dest, true)
+		}
+		return err
+	})
 }
 
 // FindOneAndUpdateById executes a findAndModify command whose _id value matches the ID given in the collection.
@@ -527,15 +663,21 @@ func (t *Template) FindOneAndReplace(ctx context.Context, filter, replacement, d
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/findAndModify/.
 func (t *Template) FindOneAndUpdateById(ctx context.Context, id, update, dest any, opts ...*option.FindOneAndUpdate) error {
-	opt := option.MergeFindOneAndUpdateByParams(opts, globalOption)
-	err := t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			err = t.findOneAndUpdate(sc, bson.D{{"_id", id}}, update, dest, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return err
+	op := &Operation{Name: "FindOneAndUpdateById", Ref: dest, Filter: bson.D{{"_id", id}}, Update: update, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		opt := option.MergeFindOneAndUpdateByParams(operationOptions(op, opts), globalOption)
+		err := t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				err = t.findOneAndUpdate(sc, op.Filter, op.Update, dest, opt)
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheById(ctx, dest, id)
+		}
+		return err
+	})
 }
 
 // FindOneAndUpdate executes a findAndModify command to update at most one document in the collection and returns the
@@ -554,15 +696,21 @@ func (t *Template) FindOneAndUpdateById(ctx context.Context, id, update, dest an
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/findAndModify/.
 func (t *Template) FindOneAndUpdate(ctx context.Context, filter, update, dest any, opts ...*option.FindOneAndUpdate) error {
-	opt := option.MergeFindOneAndUpdateByParams(opts, globalOption)
-	err := t.startSession(ctx, *opt.ForceRecreateSession)
-	if helper.IsNil(err) {
-		err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
-			err = t.findOneAndUpdate(sc, filter, update, dest, opt)
-			return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
-		})
-	}
-	return err
+	op := &Operation{Name: "FindOneAndUpdate", Ref: dest, Filter: filter, Update: update, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		opt := option.MergeFindOneAndUpdateByParams(operationOptions(op, opts), globalOption)
+		err := t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				err = t.findOneAndUpdate(sc, op.Filter, op.Update, dest, opt)
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheByRef(ctx, dest, true)
+		}
+		return err
+	})
 }
 
 // Find executes a find command, if successful it returns the corresponding documents in the collection in the dest
@@ -578,7 +726,10 @@ func (t *Template) FindOneAndUpdate(ctx context.Context, filter, update, dest an
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/find/.
 func (t *Template) Find(ctx context.Context, filter, dest any, opts ...*option.Find) error {
-	return t.find(ctx, filter, dest, opts...)
+	op := &Operation{Name: "Find", Ref: dest, Filter: filter, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		return t.find(ctx, op.Filter, dest, operationOptions(op, opts)...)
+	})
 }
 
 // FindAll execute a search command. This is equivalent to running Find(ctx, bson.D{}, dest, opts...).
@@ -590,7 +741,10 @@ func (t *Template) Find(ctx context.Context, filter, dest any, opts ...*option.F
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/find/.
 func (t *Template) FindAll(ctx context.Context, dest any, opts ...*option.Find) error {
-	return t.find(ctx, bson.D{}, dest, opts...)
+	op := &Operation{Name: "FindAll", Ref: dest, Filter: bson.D{}, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		return t.find(ctx, op.Filter, dest, operationOptions(op, opts)...)
+	})
 }
 
 // FindPageable executes a find command, if successful, returns the paginated documents in the
@@ -606,46 +760,60 @@ func (t *Template) FindAll(ctx context.Context, dest any, opts ...*option.Find)
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/find/.
 func (t *Template) FindPageable(ctx context.Context, filter any, input PageInput, opts ...*option.FindPageable) (
 	*PageResult, error) {
-	if helper.IsNotStruct(input.Ref) {
-		return nil, errors.New("mongo: input.Ref need to be structure")
-	}
-	_, collection, err := t.getMongoInfosByAny(input.Ref)
-	if helper.IsNotNil(err) {
-		return nil, err
-	}
-	opt := option.MergeFindPageableByParams(opts)
-	skip := input.Page * input.PageSize
-	cursor, err := collection.Find(ctx, filter, &options.FindOptions{
-		AllowDiskUse:        opt.AllowDiskUse,
-		AllowPartialResults: opt.AllowPartialResults,
-		BatchSize:           opt.BatchSize,
-		Collation:           option.ParseCollationMongoOptions(opt.Collation),
-		Comment:             opt.Comment,
-		CursorType:          option.ParseCursorType(opt.CursorType),
-		Hint:                opt.Hint,
-		Limit:               &input.PageSize,
-		Max:                 opt.Max,
-		MaxAwaitTime:        opt.MaxAwaitTime,
-		MaxTime:             opt.MaxTime,
-		Min:                 opt.Min,
-		NoCursorTimeout:     opt.NoCursorTimeout,
-		Projection:          opt.Projection,
-		ReturnKey:           opt.ReturnKey,
-		ShowRecordID:        opt.ShowRecordID,
-		Skip:                &skip,
-		Sort:                input.Sort,
-		Let:                 opt.Let,
-	})
-	defer t.closeCursor(ctx, cursor)
-	if helper.IsNil(err) {
-		dest := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(input.Ref)), 0, 0).Interface()
-		err = cursor.All(ctx, &dest)
+	op := &Operation{Name: "FindPageable", Ref: input.Ref, Filter: filter, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*PageResult, error) {
+		if helper.IsNotStruct(input.Ref) {
+			return nil, errors.New("mongo: input.Ref need to be structure")
+		}
+		opt := option.MergeFindPageableByParams(operationOptions(op, opts))
+		concern, err := newReadConcern(opt.ReadPreference, opt.ReadConcern)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		_, collection, err := t.getMongoInfosByAny(input.Ref, concern)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		ctx, endSession, err := t.readContext(ctx, opt.CausalConsistency)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		defer endSession()
+		skip := input.Page * input.PageSize
+		defer slowQuery{command: "find", collection: collection, filter: op.Filter, sort: input.Sort,
+			skip: &skip, limit: &input.PageSize, start: time.Now()}.finish()
+		cursor, err := collection.Find(ctx, op.Filter, &options.FindOptions{
+			AllowDiskUse:        opt.AllowDiskUse,
+			AllowPartialResults: opt.AllowPartialResults,
+			BatchSize:           opt.BatchSize,
+			Collation:           option.ParseCollationMongoOptions(opt.Collation),
+			Comment:             opt.Comment,
+			CursorType:          option.ParseCursorType(opt.CursorType),
+			Hint:                opt.Hint,
+			Limit:               &input.PageSize,
+			Max:                 opt.Max,
+			MaxAwaitTime:        opt.MaxAwaitTime,
+			MaxTime:             opt.MaxTime,
+			Min:                 opt.Min,
+			NoCursorTimeout:     opt.NoCursorTimeout,
+			Projection:          opt.Projection,
+			ReturnKey:           opt.ReturnKey,
+			ShowRecordID:        opt.ShowRecordID,
+			Skip:                &skip,
+			Sort:                input.Sort,
+			Let:                 opt.Let,
+		})
+		defer t.closeCursor(ctx, cursor)
 		if helper.IsNil(err) {
-			countTotal, _ := collection.CountDocuments(ctx, filter)
-			return newPageResult(input, dest, countTotal), nil
+			dest := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(input.Ref)), 0, 0).Interface()
+			err = cursor.All(ctx, &dest)
+			if helper.IsNil(err) {
+				countTotal, _ := collection.CountDocuments(ctx, op.Filter)
+				return newPageResult(input, dest, countTotal), nil
+			}
 		}
-	}
-	return nil, err
+		return nil, err
+	})
 }
 
 // Exists executes the count command, if the quantity is greater than 0 with a limit of 1, true is returned,
@@ -655,7 +823,10 @@ func (t *Template) FindPageable(ctx context.Context, filter any, input PageInput
 //
 // The opts parameter can be used to specify options for the operation (see the option.Exists documentation).
 func (t *Template) Exists(ctx context.Context, filter, ref any, opts ...*option.Exists) (bool, error) {
-	return t.exists(ctx, filter, ref, opts...)
+	op := &Operation{Name: "Exists", Ref: ref, Filter: filter, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (bool, error) {
+		return t.exists(ctx, op.Filter, ref, operationOptions(op, opts)...)
+	})
 }
 
 // ExistsById executes a count command whose _id value matches the ID given in the collection.
@@ -665,7 +836,10 @@ func (t *Template) Exists(ctx context.Context, filter, ref any, opts ...*option.
 //
 // The opts parameter can be used to specify options for the operation (see the option.Exists documentation).
 func (t *Template) ExistsById(ctx context.Context, id, ref any, opts ...*option.Exists) (bool, error) {
-	return t.exists(ctx, bson.D{{"_id", id}}, ref, opts...)
+	op := &Operation{Name: "ExistsById", Ref: ref, Filter: bson.D{{"_id", id}}, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (bool, error) {
+		return t.exists(ctx, op.Filter, ref, operationOptions(op, opts)...)
+	})
 }
 
 // Aggregate executes a find command, if successful it returns the corresponding documents in the collection in the dest
@@ -684,31 +858,44 @@ func (t *Template) ExistsById(ctx context.Context, id, ref any, opts ...*option.
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/aggregate/.
 func (t *Template) Aggregate(ctx context.Context, pipeline any, dest any, opts ...*option.Aggregate) error {
-	if helper.IsNotPointer(dest) {
-		return ErrDestIsNotPointer
-	}
-	_, collection, err := t.getMongoInfosByAny(dest)
-	if helper.IsNotNil(err) {
+	op := &Operation{Name: "Aggregate", Ref: dest, Pipeline: pipeline, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		if helper.IsNotPointer(dest) {
+			return ErrDestIsNotPointer
+		}
+		opt := option.MergeAggregateByParams(operationOptions(op, opts))
+		concern, err := newReadConcern(opt.ReadPreference, opt.ReadConcern)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		_, collection, err := t.getMongoInfosByAny(dest, concern)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		ctx, endSession, err := t.readContext(ctx, opt.CausalConsistency)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		defer endSession()
+		defer slowQuery{command: "aggregate", collection: collection, pipeline: op.Pipeline, start: time.Now()}.finish()
+		cursor, err := collection.Aggregate(ctx, op.Pipeline, &options.AggregateOptions{
+			AllowDiskUse:             opt.AllowDiskUse,
+			BatchSize:                opt.BatchSize,
+			BypassDocumentValidation: opt.BypassDocumentValidation,
+			Collation:                option.ParseCollationMongoOptions(opt.Collation),
+			MaxTime:                  opt.MaxTime,
+			MaxAwaitTime:             opt.MaxAwaitTime,
+			Comment:                  opt.Comment,
+			Hint:                     opt.Hint,
+			Let:                      opt.Let,
+			Custom:                   opt.Custom,
+		})
+		defer t.closeCursor(ctx, cursor)
+		if helper.IsNil(err) {
+			err = cursor.All(ctx, dest)
+		}
 		return err
-	}
-	opt := option.MergeAggregateByParams(opts)
-	cursor, err := collection.Aggregate(ctx, pipeline, &options.AggregateOptions{
-		AllowDiskUse:             opt.AllowDiskUse,
-		BatchSize:                opt.BatchSize,
-		BypassDocumentValidation: opt.BypassDocumentValidation,
-		Collation:                option.ParseCollationMongoOptions(opt.Collation),
-		MaxTime:                  opt.MaxTime,
-		MaxAwaitTime:             opt.MaxAwaitTime,
-		Comment:                  opt.Comment,
-		Hint:                     opt.Hint,
-		Let:                      opt.Let,
-		Custom:                   opt.Custom,
 	})
-	defer t.closeCursor(ctx, cursor)
-	if helper.IsNil(err) {
-		err = cursor.All(ctx, dest)
-	}
-	return err
 }
 
 // CountDocuments returns the number of documents in the collection. For a fast count of the documents in the
@@ -722,7 +909,10 @@ func (t *Template) Aggregate(ctx context.Context, pipeline any, dest any, opts .
 //
 // The opts parameter can be used to specify options for the operation (see the option.Count documentation).
 func (t *Template) CountDocuments(ctx context.Context, filter, ref any, opts ...*option.Count) (int64, error) {
-	return t.countDocuments(ctx, filter, ref, opts...)
+	op := &Operation{Name: "CountDocuments", Ref: ref, Filter: filter, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (int64, error) {
+		return t.countDocuments(ctx, op.Filter, ref, operationOptions(op, opts)...)
+	})
 }
 
 // EstimatedDocumentCount executes a count command and returns an estimate of the number of documents in the collection
@@ -736,16 +926,19 @@ func (t *Template) CountDocuments(ctx context.Context, filter, ref any, opts ...
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/count/.
 func (t *Template) EstimatedDocumentCount(ctx context.Context, ref any, opts ...*option.EstimatedDocumentCount) (int64,
 	error) {
-	_, collection, err := t.getMongoInfosByAny(ref)
-	if helper.IsNotNil(err) {
-		return 0, err
-	}
-	opt := option.MergeEstimatedDocumentCountByParams(opts)
-	count, err := collection.EstimatedDocumentCount(ctx, &options.EstimatedDocumentCountOptions{
-		Comment: opt.Comment,
-		MaxTime: opt.MaxTime,
+	op := &Operation{Name: "EstimatedDocumentCount", Ref: ref, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (int64, error) {
+		_, collection, err := t.getMongoInfosByAny(ref)
+		if helper.IsNotNil(err) {
+			return 0, err
+		}
+		opt := option.MergeEstimatedDocumentCountByParams(operationOptions(op, opts))
+		count, err := collection.EstimatedDocumentCount(ctx, &options.EstimatedDocumentCountOptions{
+			Comment: opt.Comment,
+			MaxTime: opt.MaxTime,
+		})
+		return count, err
 	})
-	return count, err
 }
 
 // Distinct executes a distinct command to find the unique values for a specified field in the collection.
@@ -763,23 +956,35 @@ func (t *Template) EstimatedDocumentCount(ctx context.Context, ref any, opts ...
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/distinct/.
 func (t *Template) Distinct(ctx context.Context, fieldName string, filter, dest, ref any, opts ...*option.Distinct) error {
-	if helper.IsNotPointer(dest) {
-		return ErrDestIsNotPointer
-	}
-	opt := option.MergeDistinctByParams(opts)
-	_, collection, err := t.getMongoInfosByAny(ref)
-	if helper.IsNotNil(err) {
+	op := &Operation{Name: "Distinct", Ref: ref, Filter: filter, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		if helper.IsNotPointer(dest) {
+			return ErrDestIsNotPointer
+		}
+		opt := option.MergeDistinctByParams(operationOptions(op, opts))
+		concern, err := newReadConcern(opt.ReadPreference, opt.ReadConcern)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		_, collection, err := t.getMongoInfosByAny(ref, concern)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		ctx, endSession, err := t.readContext(ctx, opt.CausalConsistency)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		defer endSession()
+		result, err := collection.Distinct(ctx, fieldName, op.Filter, &options.DistinctOptions{
+			Collation: option.ParseCollationMongoOptions(opt.Collation),
+			Comment:   opt.Comment,
+			MaxTime:   opt.MaxTime,
+		})
+		if helper.IsNil(err) {
+			err = helper.ConvertToDest(result, dest)
+		}
 		return err
-	}
-	result, err := collection.Distinct(ctx, fieldName, filter, &options.DistinctOptions{
-		Collation: option.ParseCollationMongoOptions(opt.Collation),
-		Comment:   opt.Comment,
-		MaxTime:   opt.MaxTime,
 	})
-	if helper.IsNil(err) {
-		err = helper.ConvertToDest(result, dest)
-	}
-	return err
 }
 
 // Watch returns a change stream for all changes on the deployment. See
@@ -795,34 +1000,37 @@ func (t *Template) Distinct(ctx context.Context, fieldName string, filter, dest,
 //
 // The opts parameter can be used to specify options for change stream creation (see the option.Watch documentation).
 func (t *Template) Watch(ctx context.Context, pipeline any, opts ...*option.Watch) (*mongo.ChangeStream, error) {
-	opt := option.MergeWatchByParams(opts)
-	var watchChangeEvents *mongo.ChangeStream
-	var err error
-	optionsChangeStream := &options.ChangeStreamOptions{
-		BatchSize:                opt.BatchSize,
-		Collation:                option.ParseCollationMongoOptions(opt.Collation),
-		Comment:                  opt.Comment,
-		FullDocument:             option.ParseFullDocument(opt.FullDocument),
-		FullDocumentBeforeChange: option.ParseFullDocument(opt.FullDocumentBeforeChange),
-		MaxAwaitTime:             opt.MaxAwaitTime,
-		ResumeAfter:              opt.ResumeAfter,
-		ShowExpandedEvents:       opt.ShowExpandedEvents,
-		StartAtOperationTime:     opt.StartAtOperationTime,
-		StartAfter:               opt.StartAfter,
-		Custom:                   opt.Custom,
-		CustomPipeline:           opt.CustomPipeline,
-	}
-	if helper.IsNotEmpty(opt.DatabaseName) {
-		database := t.client.Database(opt.DatabaseName)
-		if helper.IsNotEmpty(opt.CollectionName) {
-			watchChangeEvents, err = database.Collection(opt.CollectionName).Watch(ctx, pipeline, optionsChangeStream)
+	op := &Operation{Name: "Watch", Pipeline: pipeline, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*mongo.ChangeStream, error) {
+		opt := option.MergeWatchByParams(operationOptions(op, opts))
+		var watchChangeEvents *mongo.ChangeStream
+		var err error
+		optionsChangeStream := &options.ChangeStreamOptions{
+			BatchSize:                opt.BatchSize,
+			Collation:                option.ParseCollationMongoOptions(opt.Collation),
+			Comment:                  opt.Comment,
+			FullDocument:             option.ParseFullDocument(opt.FullDocument),
+			FullDocumentBeforeChange: option.ParseFullDocument(opt.FullDocumentBeforeChange),
+			MaxAwaitTime:             opt.MaxAwaitTime,
+			ResumeAfter:              opt.ResumeAfter,
+			ShowExpandedEvents:       opt.ShowExpandedEvents,
+			StartAtOperationTime:     opt.StartAtOperationTime,
+			StartAfter:               opt.StartAfter,
+			Custom:                   opt.Custom,
+			CustomPipeline:           opt.CustomPipeline,
+		}
+		if helper.IsNotEmpty(opt.DatabaseName) {
+			database := t.client.Database(opt.DatabaseName)
+			if helper.IsNotEmpty(opt.CollectionName) {
+				watchChangeEvents, err = database.Collection(opt.CollectionName).Watch(ctx, op.Pipeline, optionsChangeStream)
+			} else {
+				watchChangeEvents, err = database.Watch(ctx, op.Pipeline, optionsChangeStream)
+			}
 		} else {
-			watchChangeEvents, err = database.Watch(ctx, pipeline, optionsChangeStream)
+			watchChangeEvents, err = t.client.Watch(ctx, op.Pipeline, optionsChangeStream)
 		}
-	} else {
-		watchChangeEvents, err = t.client.Watch(ctx, pipeline, optionsChangeStream)
-	}
-	return watchChangeEvents, err
+		return watchChangeEvents, err
+	})
 }
 
 // WatchWithHandler is a function that facilitates the reading of watch events, it triggers the Watch function and
@@ -830,14 +1038,137 @@ func (t *Template) Watch(ctx context.Context, pipeline any, opts ...*option.Watc
 // after this conversion, we call the handler parameter passing the context with all the information, so you can
 // process it in the way you see fit.
 //
+// It is the same as WatchWithHandlerE with a handler that never fails, see the WatchWithHandlerE documentation.
+//
 // The opts parameter can be used to specify options for change stream creation (see the option.WatchWithHandler
 // documentation).
 func (t *Template) WatchWithHandler(ctx context.Context, pipeline any, handler EventHandler,
+	opts ...*option.WatchWithHandler) error {
+	var handlerE EventHandlerE
+	if handler != nil {
+		handlerE = func(ctx *EventContext) error {
+			handler(ctx)
+			return nil
+		}
+	}
+	return t.WatchWithHandlerE(ctx, pipeline, handlerE, opts...)
+}
+
+// WatchWithHandlerE is a function that facilitates the reading of watch events, it triggers the Watch function and
+// when an event occurs it reads this event transforming all the data obtained by mongoDB into a Context, right
+// after this conversion, we call the handler parameter passing the context with all the information, so you can
+// process it in the way you see fit.
+//
+// If the handler returns an error, it is called again for the same event following the retry policy of the opts
+// parameter, if it still fails, or if the event could not be decoded, the event is passed to the
+// option.WatchWithHandler.DeadLetter func, and without it, the function returns the error without advancing past
+// the event. All the errors are also sent to the option.WatchWithHandler.Errors channel.
+//
+// The events are processed by option.WatchWithHandler.Concurrency workers, partitioned by the DocumentKey, so the
+// events of the same document keep their order, and when all the workers are busy the reading of the change stream
+// waits, see option.WatchWithHandler.QueueSize.
+//
+// If the deployment does not support change streams, e.g. a standalone server, and
+// option.WatchWithHandler.PollingFallback is true, the collection is polled instead, see the option documentation.
+//
+// If the change stream is interrupted by a resumable error, e.g. network error or primary stepdown, it is reconnected
+// after option.WatchWithHandler.DelayLoop from the last checkpoint. The resume token is checkpointed on the
+// option.WatchWithHandler.TokenStore only past the events that were processed with all the previous ones, so a
+// restarted consumer continues where it left off, events processed after the checkpoint can be delivered again.
+// The function returns when the ctx is done, or with the error if it is not resumable.
+//
+// The opts parameter can be used to specify options for change stream creation (see the option.WatchWithHandler
+// documentation).
+func (t *Template) WatchWithHandlerE(ctx context.Context, pipeline any, handler EventHandlerE,
 	opts ...*option.WatchWithHandler) error {
 	opt := option.MergeWatchHandlerByParams(opts)
-	watchEventChanges, err := t.Watch(ctx, pipeline, &option.Watch{
-		DatabaseName:             opt.DatabaseName,
-		CollectionName:           opt.CollectionName,
+	watchOpt := parseWatchHandlerToWatch(opt)
+	if helper.IsEmpty(opt.TokenKey) {
+		opt.TokenKey = opt.DatabaseName + "." + opt.CollectionName
+	}
+	if handler == nil {
+		return ErrEventHandlerIsNil
+	} else if helper.IsNotNil(t.telemetry) {
+		handler = t.telemetry.eventHandler("WatchWithHandler", handler)
+	}
+	if helper.IsNotNil(opt.TokenStore) {
+		resumeToken, err := opt.TokenStore.Load(ctx, opt.TokenKey)
+		if helper.IsNotNil(err) {
+			return err
+		} else if helper.IsNotEmpty(resumeToken) && !isPollingToken(resumeToken) {
+			setResumeTokenOnWatch(watchOpt, resumeToken)
+		}
+	}
+	for {
+		watchEventChanges, err := t.Watch(ctx, pipeline, watchOpt)
+		if isChangeStreamNotSupportedError(err) && *opt.PollingFallback {
+			logger.Info("change stream not supported, watch handler polling collection", opt.CollectionName)
+			return t.pollWithHandler(ctx, handler, opt)
+		} else if helper.IsNil(err) {
+			pool := newEventPool(ctx, handler, opt)
+			for watchEventChanges.Next(pool.ctx) {
+				current := append(bson.Raw(nil), watchEventChanges.Current...)
+				resumeToken := append(bson.Raw(nil), watchEventChanges.ResumeToken()...)
+				if !pool.dispatch(current, resumeToken) {
+					break
+				}
+			}
+			err = watchEventChanges.Err()
+			_ = watchEventChanges.Close(context.WithoutCancel(ctx))
+			errEvent := pool.wait()
+			if resumeToken := pool.checkpoint(); helper.IsNotEmpty(resumeToken) {
+				setResumeTokenOnWatch(watchOpt, resumeToken)
+			}
+			if helper.IsNotNil(ctx.Err()) {
+				return nil
+			} else if helper.IsNotNil(errEvent) {
+				return errEvent
+			}
+		}
+		if helper.IsNotNil(ctx.Err()) || helper.IsNil(err) {
+			return nil
+		} else if !isResumableChangeStreamError(err) {
+			return err
+		}
+		sendWatchError(opt, err)
+		logger.Error("watch handler interrupted, reconnecting in", opt.DelayLoop.String(), "err:", err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(opt.DelayLoop):
+		}
+	}
+}
+
+// WatchChan returns a change stream for all changes on the collection of the ref parameter, delivering the events
+// on the events channel, so they can be received on select loops. Both channels are closed when the ctx is done, or
+// after an error is sent on the error channel, e.g. a decode error or a change stream error that is not resumable.
+//
+// The ref parameter must be the collection structure with database and collection tags configured.
+//
+// The pipeline parameter must be an array of documents, each representing a pipeline stage, see the Watch
+// documentation.
+//
+// If the change stream is interrupted by a resumable error, e.g. network error or primary stepdown, it is reconnected
+// after option.WatchChan.DelayLoop from the last event sent on the events channel.
+//
+// The opts parameter can be used to specify options for change stream creation (see the option.WatchChan
+// documentation).
+func (t *Template) WatchChan(ctx context.Context, ref, pipeline any, opts ...*option.WatchChan) (<-chan Event,
+	<-chan error) {
+	opt := option.MergeWatchChanByParams(opts)
+	events := make(chan Event, opt.BufferSize)
+	errs := make(chan error, 1)
+	databaseName, collectionName, err := getMongoNamesByAny(ref)
+	if helper.IsNotNil(err) {
+		errs <- err
+		close(events)
+		close(errs)
+		return events, errs
+	}
+	go t.watchChan(ctx, pipeline, &option.Watch{
+		DatabaseName:             databaseName,
+		CollectionName:           collectionName,
 		BatchSize:                opt.BatchSize,
 		Collation:                opt.Collation,
 		Comment:                  opt.Comment,
@@ -850,17 +1181,8 @@ func (t *Template) WatchWithHandler(ctx context.Context, pipeline any, handler E
 		StartAfter:               opt.StartAfter,
 		Custom:                   opt.Custom,
 		CustomPipeline:           opt.CustomPipeline,
-	})
-	if helper.IsNotNil(err) {
-		return err
-	}
-	for watchEventChanges.Next(ctx) {
-		var event Event
-		_ = watchEventChanges.Decode(&event)
-		processNextEvent(handler, event, opt)
-	}
-	_ = watchEventChanges.Close(ctx)
-	return nil
+	}, opt.DelayLoop, events, errs)
+	return events, errs
 }
 
 // DropCollection drops the collection on the server. This method ignores "namespace not found" errors,
@@ -868,11 +1190,18 @@ func (t *Template) WatchWithHandler(ctx context.Context, pipeline any, handler E
 //
 // The ref parameter must be the collection structure with database and collection tags configured.
 func (t *Template) DropCollection(ctx context.Context, ref any) error {
-	_, collection, err := t.getMongoInfosByAny(ref)
-	if helper.IsNotNil(err) {
+	op := &Operation{Name: "DropCollection", Ref: ref}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		_, collection, err := t.getMongoInfosByAny(ref)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		err = collection.Drop(ctx)
+		if helper.IsNil(err) {
+			t.evictCacheByRef(ctx, ref, true)
+		}
 		return err
-	}
-	return collection.Drop(ctx)
+	})
 }
 
 // DropDatabase drops the database on the server. This method ignores "namespace not found" errors,
@@ -880,11 +1209,55 @@ func (t *Template) DropCollection(ctx context.Context, ref any) error {
 //
 // The ref parameter must be the collection structure with database and collection tags configured.
 func (t *Template) DropDatabase(ctx context.Context, ref any) error {
-	database, _, err := t.getMongoInfosByAny(ref)
-	if helper.IsNotNil(err) {
+	op := &Operation{Name: "DropDatabase", Ref: ref}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		database, _, err := t.getMongoInfosByAny(ref)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		err = database.Drop(ctx)
+		if cache := t.cache.Load(); helper.IsNil(err) && helper.IsNotNil(cache) {
+			cache.evictNamespace(ctx, database.Name(), "")
+		}
 		return err
-	}
-	return database.Drop(ctx)
+	})
+}
+
+// ApplyValidator executes a collMod command to install the $jsonSchema validator generated by JSONSchemaFor on the
+// collection, if the collection does not exist yet, it is created with the validator by the create command.
+//
+// The ref parameter must be the collection structure with database and collection tags configured.
+//
+// The level parameter determines how strictly the validation rules are applied to existing valid documents and the
+// action parameter determines whether to reject the invalid documents or only log the violations.
+//
+// For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/collMod/.
+func (t *Template) ApplyValidator(ctx context.Context, ref any, level ValidationLevel, action ValidationAction) error {
+	op := &Operation{Name: "ApplyValidator", Ref: ref}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		database, collection, err := t.getMongoInfosByAny(ref)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		validator, err := JSONSchemaFor(ref)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		err = database.RunCommand(ctx, bson.D{
+			{"collMod", collection.Name()},
+			{"validator", validator},
+			{"validationLevel", level},
+			{"validationAction", action},
+		}).Err()
+		var commandErr mongo.CommandError
+		if errors.As(err, &commandErr) && commandErr.HasErrorCode(errCodeNamespaceNotFound) {
+			err = database.CreateCollection(ctx, collection.Name(), options.CreateCollection().
+				SetValidator(validator).
+				SetValidationLevel(string(level)).
+				SetValidationAction(string(action)))
+		}
+		return err
+	})
 }
 
 // CreateOneIndex executes a createIndexes command to create an index on the collection and returns the name of the new
@@ -895,7 +1268,10 @@ func (t *Template) DropDatabase(ctx context.Context, ref any) error {
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/createIndexes/.
 func (t *Template) CreateOneIndex(ctx context.Context, input IndexInput) (string, error) {
-	return t.createOneIndex(ctx, input)
+	op := &Operation{Name: "CreateOneIndex", Ref: input.Ref}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (string, error) {
+		return t.createOneIndex(ctx, input)
+	})
 }
 
 // CreateManyIndex executes a createIndexes command to create multiple indexes on the collection and returns the names of
@@ -908,7 +1284,10 @@ func (t *Template) CreateOneIndex(ctx context.Context, input IndexInput) (string
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/createIndexes/.
 func (t *Template) CreateManyIndex(ctx context.Context, inputs []IndexInput) ([]string, error) {
-	return t.createManyIndex(ctx, inputs)
+	op := &Operation{Name: "CreateManyIndex"}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) ([]string, error) {
+		return t.createManyIndex(ctx, inputs)
+	})
 }
 
 // DropOneIndex executes a dropIndexes operation to drop an index on the collection. If the operation succeeds, this returns
@@ -924,12 +1303,15 @@ func (t *Template) CreateManyIndex(ctx context.Context, inputs []IndexInput) ([]
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/dropIndexes/.
 func (t *Template) DropOneIndex(ctx context.Context, name string, ref any, opts ...*option.DropIndex) error {
-	opt := option.MergeDropIndexByParams(opts)
-	_, collection, err := t.getMongoInfosByAny(ref)
-	if helper.IsNil(err) {
-		_, err = collection.Indexes().DropOne(ctx, name, &options.DropIndexesOptions{MaxTime: opt.MaxTime})
-	}
-	return err
+	op := &Operation{Name: "DropOneIndex", Ref: ref, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		opt := option.MergeDropIndexByParams(operationOptions(op, opts))
+		_, collection, err := t.getMongoInfosByAny(ref)
+		if helper.IsNil(err) {
+			_, err = collection.Indexes().DropOne(ctx, name, &options.DropIndexesOptions{MaxTime: opt.MaxTime})
+		}
+		return err
+	})
 }
 
 // DropAllIndexes executes a dropIndexes operation to drop all indexes on the collection. If the operation succeeds, this
@@ -942,12 +1324,15 @@ func (t *Template) DropOneIndex(ctx context.Context, name string, ref any, opts
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/dropIndexes/.
 func (t *Template) DropAllIndexes(ctx context.Context, ref any, opts ...*option.DropIndex) error {
-	opt := option.MergeDropIndexByParams(opts)
-	_, collection, err := t.getMongoInfosByAny(ref)
-	if helper.IsNil(err) {
-		_, err = collection.Indexes().DropAll(ctx, &options.DropIndexesOptions{MaxTime: opt.MaxTime})
-	}
-	return err
+	op := &Operation{Name: "DropAllIndexes", Ref: ref, Options: opts}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		opt := option.MergeDropIndexByParams(operationOptions(op, opts))
+		_, collection, err := t.getMongoInfosByAny(ref)
+		if helper.IsNil(err) {
+			_, err = collection.Indexes().DropAll(ctx, &options.DropIndexesOptions{MaxTime: opt.MaxTime})
+		}
+		return err
+	})
 }
 
 // ListIndexes executes a listIndexes command and returns a cursor over the indexes in the collection.
@@ -958,21 +1343,24 @@ func (t *Template) DropAllIndexes(ctx context.Context, ref any, opts ...*option.
 //
 // For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/listIndexes/.
 func (t *Template) ListIndexes(ctx context.Context, ref any, opts ...*option.ListIndexes) ([]IndexResult, error) {
-	_, collection, err := t.getMongoInfosByAny(ref)
-	if helper.IsNotNil(err) {
-		return nil, err
-	}
-	opt := option.MergeListIndexesByParams(opts)
-	cursor, err := collection.Indexes().List(ctx, &options.ListIndexesOptions{
-		BatchSize: opt.BatchSize,
-		MaxTime:   opt.MaxTime,
+	op := &Operation{Name: "ListIndexes", Ref: ref, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) ([]IndexResult, error) {
+		_, collection, err := t.getMongoInfosByAny(ref)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		opt := option.MergeListIndexesByParams(operationOptions(op, opts))
+		cursor, err := collection.Indexes().List(ctx, &options.ListIndexesOptions{
+			BatchSize: opt.BatchSize,
+			MaxTime:   opt.MaxTime,
+		})
+		defer t.closeCursor(ctx, cursor)
+		var results []IndexResult
+		if helper.IsNil(err) {
+			err = cursor.All(ctx, &results)
+		}
+		return results, err
 	})
-	defer t.closeCursor(ctx, cursor)
-	var results []IndexResult
-	if helper.IsNil(err) {
-		err = cursor.All(ctx, &results)
-	}
-	return results, err
 }
 
 // ListIndexSpecifications executes a List command and returns a slice of returned IndexSpecifications.
@@ -980,57 +1368,93 @@ func (t *Template) ListIndexes(ctx context.Context, ref any, opts ...*option.Lis
 // The ref parameter must be the collection structure with database and collection tags configured.
 func (t *Template) ListIndexSpecifications(ctx context.Context, ref any, opts ...*option.ListIndexes) (
 	[]IndexSpecification, error) {
-	_, collection, err := t.getMongoInfosByAny(ref)
-	if helper.IsNotNil(err) {
-		return nil, err
-	}
-	opt := option.MergeListIndexesByParams(opts)
-	mongoResult, err := collection.Indexes().ListSpecifications(ctx, &options.ListIndexesOptions{
-		BatchSize: opt.BatchSize,
-		MaxTime:   opt.MaxTime,
-	})
-	var result []IndexSpecification
-	for _, v := range mongoResult {
-		if helper.IsNotNil(v) {
-			result = append(result, IndexSpecification{
-				Name:               v.Name,
-				Namespace:          v.Namespace,
-				KeysDocument:       v.KeysDocument,
-				Version:            v.Version,
-				ExpireAfterSeconds: v.ExpireAfterSeconds,
-				Sparse:             v.Sparse,
-				Unique:             v.Unique,
-				Clustered:          v.Clustered,
-			})
+	op := &Operation{Name: "ListIndexSpecifications", Ref: ref, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) ([]IndexSpecification, error) {
+		_, collection, err := t.getMongoInfosByAny(ref)
+		if helper.IsNotNil(err) {
+			return nil, err
 		}
-	}
-	return result, err
+		opt := option.MergeListIndexesByParams(operationOptions(op, opts))
+		mongoResult, err := collection.Indexes().ListSpecifications(ctx, &options.ListIndexesOptions{
+			BatchSize: opt.BatchSize,
+			MaxTime:   opt.MaxTime,
+		})
+		var result []IndexSpecification
+		for _, v := range mongoResult {
+			if helper.IsNotNil(v) {
+				result = append(result, IndexSpecification{
+					Name:               v.Name,
+					Namespace:          v.Namespace,
+					KeysDocument:       v.KeysDocument,
+					Version:            v.Version,
+					ExpireAfterSeconds: v.ExpireAfterSeconds,
+					Sparse:             v.Sparse,
+					Unique:             v.Unique,
+					Clustered:          v.Clustered,
+				})
+			}
+		}
+		return result, err
+	})
 }
 
 // StartSession creates a new session and a new transaction and stores it in the template itself for the next operations.
+//
+// The transaction is started with the option.Global.WriteConcern, the write concern of the next operations is ignored
+// until the session is closed, since the write concern of a transaction is set when it starts.
 func (t *Template) StartSession(ctx context.Context) error {
-	return t.startSession(ctx, true)
+	op := &Operation{Name: "StartSession"}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		return t.startSession(ctx, true, globalOption.WriteConcern, nil)
+	})
 }
 
 // CloseSession closes session and transaction, if param abort is false it will commit the changes,
 // otherwise it will abort all transactions.
 func (t *Template) CloseSession(ctx context.Context, abort bool) error {
-	return t.closeSession(ctx, abort)
+	op := &Operation{Name: "CloseSession"}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		return t.closeSession(ctx, abort)
+	})
 }
 
 // CommitTransaction commit all transactions on session
 func (t *Template) CommitTransaction(ctx context.Context) error {
-	return t.commitTransaction(ctx)
+	op := &Operation{Name: "CommitTransaction"}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		return t.commitTransaction(ctx)
+	})
 }
 
 // AbortTransaction abort all transactions on session
 func (t *Template) AbortTransaction(ctx context.Context) error {
-	return t.abortTransaction(ctx)
+	op := &Operation{Name: "AbortTransaction"}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		return t.abortTransaction(ctx)
+	})
+}
+
+// WithSession calls the fn parameter with the context of the open session, so the operations executed on the mongo
+// driver by the fn, e.g. writes on collections without a mapped structure, are committed or aborted in the same
+// transaction of the Template operations. The session is not closed, see CloseSession.
+//
+// If there is no open session, the ErrNoOpenSession error is returned.
+func (t *Template) WithSession(ctx context.Context, fn func(sc mongo.SessionContext) error) error {
+	op := &Operation{Name: "WithSession"}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		if helper.IsNil(t.session) {
+			return ErrNoOpenSession
+		}
+		return mongo.WithSession(ctx, t.session, fn)
+	})
 }
 
 // Disconnect closes the mongodb connection client with return error
 func (t *Template) Disconnect(ctx context.Context) error {
-	return t.client.Disconnect(ctx)
+	op := &Operation{Name: "Disconnect"}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		return t.client.Disconnect(ctx)
+	})
 }
 
 // SimpleDisconnect closes the mongodb connection client without return error
@@ -1055,11 +1479,21 @@ func (t *Template) insertOne(sc mongo.SessionContext, document any, opt *option.
 		return ErrDocumentIsNotStruct
 	} else if helper.IsEmpty(document) {
 		return ErrDocumentIsEmpty
+	} else if err := validateDocument(document, ""); helper.IsNotNil(err) {
+		return err
 	}
+	return t.insertDocument(sc, document, opt)
+}
+
+func (t *Template) insertDocument(sc mongo.SessionContext, document any, opt *option.InsertOne) error {
 	_, collection, err := t.getMongoInfosByAny(document)
 	if helper.IsNotNil(err) {
 		return err
 	}
+	audit, _, err := t.beginAudit(sc, collection, OperationTypeInsert, nil, nil)
+	if helper.IsNotNil(err) {
+		return err
+	}
 	result, err := collection.InsertOne(sc, document, &options.InsertOneOptions{
 		BypassDocumentValidation: opt.BypassDocumentValidation,
 		Comment:                  opt.Comment,
@@ -1068,7 +1502,7 @@ func (t *Template) insertOne(sc mongo.SessionContext, document any, opt *option.
 		return err
 	}
 	util.SetInsertedIdOnDocument(result.InsertedID, document)
-	return nil
+	return audit.commit(sc, result.InsertedID)
 }
 
 func (t *Template) insertMany(sc mongo.SessionContext, a any, opt *option.InsertMany) error {
@@ -1078,6 +1512,9 @@ func (t *Template) insertMany(sc mongo.SessionContext, a any, opt *option.Insert
 		return ErrDocumentsIsEmpty
 	}
 	documents := reflect.ValueOf(a)
+	if err := validateDocuments(documents); helper.IsNotNil(err) {
+		return err
+	}
 	var errs []string
 	for i := 0; helper.IsLessThan(i, documents.Len()); i++ {
 		indexValue := documents.Index(i)
@@ -1089,7 +1526,7 @@ func (t *Template) insertMany(sc mongo.SessionContext, a any, opt *option.Insert
 		} else if helper.IsEmpty(document) {
 			errs = append(errs, helper.Sprintln(ErrDocumentIsEmpty.Error(), "index:", i))
 		} else {
-			err := t.insertOne(sc, document, &option.InsertOne{
+			err := t.insertDocument(sc, document, &option.InsertOne{
 				BypassDocumentValidation: opt.BypassDocumentValidation,
 				Comment:                  opt.Comment,
 			})
@@ -1116,6 +1553,11 @@ func (t *Template) deleteOne(sc mongo.SessionContext, filter, ref any, opt *opti
 	if helper.IsNotNil(err) {
 		return nil, err
 	}
+	audit, filter, err := t.beginAudit(sc, collection, OperationTypeDelete, filter,
+		newAuditFindOptions(true, nil, opt.Collation, opt.Let))
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
 	mongoResult, err := collection.DeleteOne(sc, filter, &options.DeleteOptions{
 		Collation: option.ParseCollationMongoOptions(opt.Collation),
 		Comment:   opt.Comment,
@@ -1128,6 +1570,9 @@ func (t *Template) deleteOne(sc mongo.SessionContext, filter, ref any, opt *opti
 			DeletedCount: mongoResult.DeletedCount,
 		}
 	}
+	if helper.IsNil(err) {
+		err = audit.commit(sc, nil)
+	}
 	return result, err
 }
 
@@ -1136,6 +1581,11 @@ func (t *Template) deleteMany(sc mongo.SessionContext, filter, ref any, opt *opt
 	if helper.IsNotNil(err) {
 		return nil, err
 	}
+	audit, filter, err := t.beginAudit(sc, collection, OperationTypeDelete, filter,
+		newAuditFindOptions(false, nil, opt.Collation, opt.Let))
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
 	mongoResult, err := collection.DeleteMany(sc, filter, &options.DeleteOptions{
 		Collation: option.ParseCollationMongoOptions(opt.Collation),
 		Comment:   opt.Comment,
@@ -1148,6 +1598,9 @@ func (t *Template) deleteMany(sc mongo.SessionContext, filter, ref any, opt *opt
 			DeletedCount: mongoResult.DeletedCount,
 		}
 	}
+	if helper.IsNil(err) {
+		err = audit.commit(sc, nil)
+	}
 	return result, err
 }
 
@@ -1156,6 +1609,13 @@ func (t *Template) updateOne(sc mongo.SessionContext, filter, update, ref any, o
 	if helper.IsNotNil(err) {
 		return nil, err
 	}
+	defer slowQuery{command: "update", collection: collection, filter: filter, update: update,
+		start: time.Now()}.finish()
+	audit, filter, err := t.beginAudit(sc, collection, OperationTypeUpdate, filter,
+		newAuditFindOptions(true, nil, opt.Collation, opt.Let))
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
 	mongoResult, err := collection.UpdateOne(sc, filter, update, &options.UpdateOptions{
 		ArrayFilters:             option.ParseArrayFiltersMongoOptions(opt.ArrayFilters),
 		BypassDocumentValidation: opt.BypassDocumentValidation,
@@ -1174,6 +1634,9 @@ func (t *Template) updateOne(sc mongo.SessionContext, filter, update, ref any, o
 			UpsertedID:    mongoResult.UpsertedID,
 		}
 	}
+	if helper.IsNil(err) {
+		err = audit.commit(sc, mongoResult.UpsertedID)
+	}
 	return result, err
 }
 
@@ -1182,6 +1645,13 @@ func (t *Template) updateMany(sc mongo.SessionContext, filter, update, ref any,
 	if helper.IsNotNil(err) {
 		return nil, err
 	}
+	defer slowQuery{command: "update", collection: collection, filter: filter, update: update, multi: true,
+		start: time.Now()}.finish()
+	audit, filter, err := t.beginAudit(sc, collection, OperationTypeUpdate, filter,
+		newAuditFindOptions(false, nil, opt.Collation, opt.Let))
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
 	mongoResult, err := collection.UpdateMany(sc, filter, update, &options.UpdateOptions{
 		ArrayFilters:             option.ParseArrayFiltersMongoOptions(opt.ArrayFilters),
 		BypassDocumentValidation: opt.BypassDocumentValidation,
@@ -1200,15 +1670,26 @@ func (t *Template) updateMany(sc mongo.SessionContext, filter, update, ref any,
 			UpsertedID:    mongoResult.UpsertedID,
 		}
 	}
+	if helper.IsNil(err) {
+		err = audit.commit(sc, mongoResult.UpsertedID)
+	}
 	return result, err
 }
 
 func (t *Template) replaceOne(sc mongo.SessionContext, filter, update, ref any, opt *option.Replace) (*UpdateResult,
 	error) {
+	if err := validateDocument(update, ""); helper.IsNotNil(err) {
+		return nil, err
+	}
 	_, collection, err := t.getMongoInfosByAny(ref)
 	if helper.IsNotNil(err) {
 		return nil, err
 	}
+	audit, filter, err := t.beginAudit(sc, collection, OperationTypeReplace, filter,
+		newAuditFindOptions(true, nil, opt.Collation, opt.Let))
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
 	mongoResult, err := collection.ReplaceOne(sc, filter, update, &options.ReplaceOptions{
 		BypassDocumentValidation: opt.BypassDocumentValidation,
 		Collation:                option.ParseCollationMongoOptions(opt.Collation),
@@ -1226,6 +1707,9 @@ func (t *Template) replaceOne(sc mongo.SessionContext, filter, update, ref any,
 			UpsertedID:    mongoResult.UpsertedID,
 		}
 	}
+	if helper.IsNil(err) {
+		err = audit.commit(sc, mongoResult.UpsertedID)
+	}
 	return result, err
 }
 
@@ -1233,11 +1717,22 @@ func (t *Template) find(ctx context.Context, filter, dest any, opts ...*option.F
 	if helper.IsNotPointer(dest) {
 		return ErrDestIsNotPointer
 	}
-	_, collection, err := t.getMongoInfosByAny(dest)
+	opt := option.MergeFindByParams(opts)
+	concern, err := newReadConcern(opt.ReadPreference, opt.ReadConcern)
 	if helper.IsNotNil(err) {
 		return err
 	}
-	opt := option.MergeFindByParams(opts)
+	_, collection, err := t.getMongoInfosByAny(dest, concern)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	ctx, endSession, err := t.readContext(ctx, opt.CausalConsistency)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	defer endSession()
+	defer slowQuery{command: "find", collection: collection, filter: filter, sort: opt.Sort,
+		skip: opt.Skip, limit: opt.Limit, start: time.Now()}.finish()
 	cursor, err := collection.Find(ctx, filter, &options.FindOptions{
 		AllowDiskUse:        opt.AllowDiskUse,
 		AllowPartialResults: opt.AllowPartialResults,
@@ -1272,12 +1767,35 @@ func (t *Template) findOne(ctx context.Context, filter, dest any, opts ...*optio
 	} else if helper.IsNotStruct(dest) {
 		return ErrDestIsNotStruct
 	}
-	_, collection, err := t.getMongoInfosByAny(dest)
+	databaseName, collectionName, err := getMongoNamesByAny(dest)
 	if helper.IsNotNil(err) {
 		return err
 	}
 	opt := option.MergeFindOneByParams(opts)
-	err = collection.FindOne(ctx, filter, &options.FindOneOptions{
+	concern, err := newReadConcern(opt.ReadPreference, opt.ReadConcern)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	cache := t.cache.Load()
+	var cacheKey string
+	if helper.IsNotNil(cache) {
+		cacheKey = cache.findOneKey(databaseName, collectionName, filter, opt)
+		if helper.IsNotEmpty(cacheKey) && cache.get(ctx, cacheKey, dest) {
+			return nil
+		}
+	}
+	ctx, endSession, err := t.readContext(ctx, opt.CausalConsistency)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	defer endSession()
+	_, collection, err := t.getMongoInfosByAny(dest, concern)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	defer slowQuery{command: "find", collection: collection, filter: filter, sort: opt.Sort,
+		skip: opt.Skip, limit: helper.ConvertToPointer[int64](1), start: time.Now()}.finish()
+	result := collection.FindOne(ctx, filter, &options.FindOneOptions{
 		AllowPartialResults: opt.AllowPartialResults,
 		Collation:           option.ParseCollationMongoOptions(opt.Collation),
 		Comment:             opt.Comment,
@@ -1290,7 +1808,14 @@ func (t *Template) findOne(ctx context.Context, filter, dest any, opts ...*optio
 		ShowRecordID:        opt.ShowRecordID,
 		Skip:                opt.Skip,
 		Sort:                opt.Sort,
-	}).Decode(dest)
+	})
+	if helper.IsEmpty(cacheKey) {
+		err = result.Decode(dest)
+	} else if document, errRaw := result.Raw(); helper.IsNotNil(errRaw) {
+		err = errRaw
+	} else if err = bson.Unmarshal(document, dest); helper.IsNil(err) {
+		cache.set(ctx, databaseName, collectionName, cacheKey, document)
+	}
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return ErrNoDocuments
 	}
@@ -1307,6 +1832,11 @@ func (t *Template) findOneAndDelete(sc mongo.SessionContext, filter, dest any, o
 	if helper.IsNotNil(err) {
 		return err
 	}
+	audit, filter, err := t.beginAudit(sc, collection, OperationTypeDelete, filter,
+		newAuditFindOptions(true, opt.Sort, opt.Collation, opt.Let))
+	if helper.IsNotNil(err) {
+		return err
+	}
 	err = collection.FindOneAndDelete(sc, filter, &options.FindOneAndDeleteOptions{
 		Collation:  option.ParseCollationMongoOptions(opt.Collation),
 		Comment:    opt.Comment,
@@ -1318,8 +1848,10 @@ func (t *Template) findOneAndDelete(sc mongo.SessionContext, filter, dest any, o
 	}).Decode(dest)
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return ErrNoDocuments
+	} else if helper.IsNotNil(err) {
+		return err
 	}
-	return err
+	return audit.commit(sc, nil)
 }
 
 func (t *Template) findOneAndReplace(sc mongo.SessionContext, filter, replacement, dest any, opt *option.FindOneAndReplace) error {
@@ -1327,11 +1859,20 @@ func (t *Template) findOneAndReplace(sc mongo.SessionContext, filter, replacemen
 		return ErrDestIsNotPointer
 	} else if helper.IsNotStruct(dest) {
 		return ErrDestIsNotStruct
+	} else if err := validateDocument(replacement, ""); helper.IsNotNil(err) {
+		return err
 	}
 	_, collection, err := t.getMongoInfosByAny(dest)
 	if helper.IsNotNil(err) {
 		return err
 	}
+	audit, filter, err := t.beginAudit(sc, collection, OperationTypeReplace, filter,
+		newAuditFindOptions(true, opt.Sort, opt.Collation, opt.Let))
+	if helper.IsNotNil(err) {
+		return err
+	} else if helper.IsNotNil(audit) {
+		audit.upsert = helper.IsNotNil(opt.Upsert) && *opt.Upsert
+	}
 	err = collection.FindOneAndReplace(sc, filter, replacement, &options.FindOneAndReplaceOptions{
 		BypassDocumentValidation: opt.BypassDocumentValidation,
 		Collation:                option.ParseCollationMongoOptions(opt.Collation),
@@ -1346,8 +1887,10 @@ func (t *Template) findOneAndReplace(sc mongo.SessionContext, filter, replacemen
 	}).Decode(dest)
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return ErrNoDocuments
+	} else if helper.IsNotNil(err) {
+		return err
 	}
-	return err
+	return audit.commit(sc, nil)
 }
 
 func (t *Template) findOneAndUpdate(sc mongo.SessionContext, filter, update, dest any, opt *option.FindOneAndUpdate) error {
@@ -1360,6 +1903,13 @@ func (t *Template) findOneAndUpdate(sc mongo.SessionContext, filter, update, des
 	if helper.IsNotNil(err) {
 		return err
 	}
+	audit, filter, err := t.beginAudit(sc, collection, OperationTypeUpdate, filter,
+		newAuditFindOptions(true, opt.Sort, opt.Collation, opt.Let))
+	if helper.IsNotNil(err) {
+		return err
+	} else if helper.IsNotNil(audit) {
+		audit.upsert = helper.IsNotNil(opt.Upsert) && *opt.Upsert
+	}
 	err = collection.FindOneAndUpdate(sc, filter, update, &options.FindOneAndUpdateOptions{
 		ArrayFilters:             option.ParseArrayFiltersMongoOptions(opt.ArrayFilters),
 		BypassDocumentValidation: opt.BypassDocumentValidation,
@@ -1375,16 +1925,28 @@ func (t *Template) findOneAndUpdate(sc mongo.SessionContext, filter, update, des
 	}).Decode(dest)
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return ErrNoDocuments
+	} else if helper.IsNotNil(err) {
+		return err
 	}
-	return err
+	return audit.commit(sc, nil)
 }
 
 func (t *Template) countDocuments(ctx context.Context, filter, ref any, opts ...*option.Count) (int64, error) {
-	_, collection, err := t.getMongoInfosByAny(ref)
+	opt := option.MergeCountByParams(opts)
+	concern, err := newReadConcern(opt.ReadPreference, opt.ReadConcern)
 	if helper.IsNotNil(err) {
 		return 0, err
 	}
-	opt := option.MergeCountByParams(opts)
+	_, collection, err := t.getMongoInfosByAny(ref, concern)
+	if helper.IsNotNil(err) {
+		return 0, err
+	}
+	ctx, endSession, err := t.readContext(ctx, opt.CausalConsistency)
+	if helper.IsNotNil(err) {
+		return 0, err
+	}
+	defer endSession()
+	defer slowQuery{command: "count", collection: collection, filter: filter, start: time.Now()}.finish()
 	return collection.CountDocuments(ctx, filter, &options.CountOptions{
 		Collation: option.ParseCollationMongoOptions(opt.Collation),
 		Comment:   opt.Comment,
@@ -1443,15 +2005,24 @@ func (t *Template) createManyIndex(ctx context.Context, inputs []IndexInput) ([]
 	return result, nil
 }
 
-func (t *Template) startSession(ctx context.Context, forceRecreate bool) error {
+func (t *Template) startSession(ctx context.Context, forceRecreate bool, writeConcern *option.WriteConcern,
+	causalConsistency *bool) error {
 	if helper.IsNotNil(t.session) && !forceRecreate {
 		return nil
 	} else if helper.IsNotNil(t.session) {
 		t.endSession(ctx)
 	}
-	session, err := t.client.StartSession()
+	sessionOptions := options.Session()
+	if helper.IsNotNil(causalConsistency) {
+		sessionOptions.SetCausalConsistency(*causalConsistency)
+	}
+	transactionOptions := options.Transaction()
+	if helper.IsNotNil(writeConcern) {
+		transactionOptions.SetWriteConcern(option.ParseWriteConcern(writeConcern))
+	}
+	session, err := t.client.StartSession(sessionOptions)
 	if helper.IsNil(err) {
-		err = session.StartTransaction()
+		err = session.StartTransaction(transactionOptions)
 		if helper.IsNil(err) {
 			t.session = session
 		}
@@ -1479,7 +2050,8 @@ func (t *Template) closeSessionAutomatically(
 	err error,
 ) error {
 	if !disableAutoCloseSession {
-		abort := helper.IsNotNil(err) && !disableAutoRollbackSession
+		var validationErr *ValidationError
+		abort := helper.IsNotNil(err) && (!disableAutoRollbackSession || errors.As(err, &validationErr))
 		errClose := t.closeSession(sc, abort)
 		if helper.IsNil(err) && helper.IsNotNil(errClose) {
 			err = errClose
@@ -1492,20 +2064,32 @@ func (t *Template) commitTransaction(ctx context.Context) error {
 	if helper.IsNil(t.session) {
 		return ErrNoOpenSession
 	}
-	return t.session.CommitTransaction(ctx)
+	err := t.session.CommitTransaction(ctx)
+	t.evictPendingCache(ctx)
+	return err
 }
 
 func (t *Template) abortTransaction(ctx context.Context) error {
 	if helper.IsNil(t.session) {
 		return ErrNoOpenSession
 	}
-	return t.session.AbortTransaction(ctx)
+	err := t.session.AbortTransaction(ctx)
+	t.evictPendingCache(ctx)
+	return err
 }
 
 func (t *Template) endSession(ctx context.Context) {
 	if helper.IsNotNil(t.session) {
+		// the times of the session are kept, so the causally consistent reads see its writes after it ends
+		if clusterTime := t.session.ClusterTime(); helper.IsNotEmpty(clusterTime) {
+			t.lastClusterTime = clusterTime
+		}
+		if operationTime := t.session.OperationTime(); helper.IsNotNil(operationTime) {
+			t.lastOperationTime = operationTime
+		}
 		t.session.EndSession(ctx)
 		t.session = nil
+		t.evictPendingCache(ctx)
 	}
 }
 
@@ -1515,7 +2099,105 @@ func (t *Template) closeCursor(ctx context.Context, cursor *mongo.Cursor) {
 	}
 }
 
-func (t *Template) getMongoInfosByAny(a any) (*mongo.Database, *mongo.Collection, error) {
+func validateDocuments(documents reflect.Value) error {
+	var fields []FieldValidationError
+	for i := 0; helper.IsLessThan(i, documents.Len()); i++ {
+		var validationErr *ValidationError
+		err := validateDocument(documents.Index(i).Interface(), "["+strconv.Itoa(i)+"]")
+		if errors.As(err, &validationErr) {
+			fields = append(fields, validationErr.Fields...)
+		} else if helper.IsNotNil(err) {
+			return err
+		}
+	}
+	if helper.IsNotEmpty(fields) {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func validateDocument(document any, pathPrefix string) error {
+	if !globalOption.ValidateDocument || helper.IsNotStruct(document) {
+		return nil
+	}
+	err := helper.Validate().Struct(document)
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+	fields := make([]FieldValidationError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		path := fieldErr.Namespace()
+		if i := strings.Index(path, "."); helper.IsGreaterThanOrEqual(i, 0) {
+			path = path[i+1:]
+		}
+		if helper.IsNotEmpty(pathPrefix) {
+			path = pathPrefix + "." + path
+		}
+		fields = append(fields, FieldValidationError{
+			Path:  path,
+			Rule:  fieldErr.Tag(),
+			Param: fieldErr.Param(),
+			Value: fieldErr.Value(),
+		})
+	}
+	return &ValidationError{Fields: fields}
+}
+
+func (t *Template) watchChan(ctx context.Context, pipeline any, watchOpt *option.Watch, delayLoop time.Duration,
+	events chan<- Event, errs chan<- error) {
+	defer close(errs)
+	defer close(events)
+	for {
+		watchEventChanges, err := t.Watch(ctx, pipeline, watchOpt)
+		if helper.IsNil(err) {
+			for watchEventChanges.Next(ctx) {
+				var event Event
+				if err = watchEventChanges.Decode(&event); helper.IsNotNil(err) {
+					break
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+				}
+				setResumeTokenOnWatch(watchOpt, append(bson.Raw(nil), watchEventChanges.ResumeToken()...))
+			}
+			if helper.IsNil(err) {
+				err = watchEventChanges.Err()
+			}
+			_ = watchEventChanges.Close(context.WithoutCancel(ctx))
+		}
+		if helper.IsNotNil(ctx.Err()) || helper.IsNil(err) {
+			return
+		} else if !isResumableChangeStreamError(err) {
+			errs <- err
+			return
+		}
+		logger.Error("watch chan interrupted, reconnecting in", delayLoop.String(), "err:", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delayLoop):
+		}
+	}
+}
+
+func (t *Template) getMongoInfosByAny(a any, concerns ...operationConcern) (*mongo.Database, *mongo.Collection,
+	error) {
+	databaseName, collectionName, err := getMongoNamesByAny(a)
+	if helper.IsNotNil(err) {
+		return nil, nil, err
+	}
+	var databaseOptions []*options.DatabaseOptions
+	for _, concern := range concerns {
+		databaseOptions = append(databaseOptions, concern.databaseOptions())
+	}
+	database := t.client.Database(databaseName, databaseOptions...)
+	collection := database.Collection(collectionName)
+	return database, collection, nil
+}
+
+func getMongoNamesByAny(a any) (string, string, error) {
 	var databaseName string
 	var collectionName string
 	v := reflect.ValueOf(a)
@@ -1528,7 +2210,7 @@ func (t *Template) getMongoInfosByAny(a any) (*mongo.Database, *mongo.Collection
 			databaseName = util.GetDatabaseNameBySlice(a)
 			collectionName = util.GetCollectionNameBySlice(a)
 		} else {
-			return nil, nil, ErrRefDocument
+			return "", "", ErrRefDocument
 		}
 		break
 	case reflect.Struct:
@@ -1536,14 +2218,12 @@ func (t *Template) getMongoInfosByAny(a any) (*mongo.Database, *mongo.Collection
 		collectionName = util.GetCollectionNameByStruct(a)
 		break
 	default:
-		return nil, nil, ErrRefDocument
+		return "", "", ErrRefDocument
 	}
 	if helper.IsEmpty(databaseName) {
-		return nil, nil, ErrDatabaseNotConfigured
+		return "", "", ErrDatabaseNotConfigured
 	} else if helper.IsEmpty(collectionName) {
-		return nil, nil, ErrCollectionNotConfigured
+		return "", "", ErrCollectionNotConfigured
 	}
-	database := t.client.Database(databaseName)
-	collection := database.Collection(collectionName)
-	return database, collection, nil
+	return databaseName, collectionName, nil
 }
@@ -2,8 +2,13 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"testing"
 	"time"
 )
@@ -201,6 +206,23 @@ func TestTemplateReplaceOneById(t *testing.T) {
 	}
 }
 
+func TestTemplateUpsert(t *testing.T) {
+	initMongoTemplate()
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+	replacement := initTestStruct()
+	result, err := mongoTemplate.ReplaceOneById(ctx, primitive.NewObjectID(), replacement, testStruct{},
+		option.NewReplace().SetUpsert(true))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(result.UpsertedCount, int64(1)) {
+		t.Errorf("ReplaceOneById() result = %+v, err = %v, want upserted", result, err)
+	}
+	result, err = mongoTemplate.UpdateOne(ctx, bson.M{"_id": primitive.NewObjectID()},
+		bson.M{"$set": bson.M{"name": "Upsert"}}, testStruct{}, option.NewUpdate().SetUpsert(true))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(result.UpsertedCount, int64(1)) {
+		t.Errorf("UpdateOne() result = %+v, err = %v, want upserted", result, err)
+	}
+}
+
 func TestTemplateFindOneById(t *testing.T) {
 	initDocument()
 	for _, tt := range initListTestFindOneById() {
@@ -530,6 +552,76 @@ func TestTemplateWatchWithHandler(t *testing.T) {
 	}
 }
 
+func TestTemplateWatchWithHandlerE(t *testing.T) {
+	initMongoTemplate()
+	for _, tt := range initListTestWatchHandlerE() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), tt.durationTimeout)
+			defer cancel()
+			go func() {
+				time.Sleep(2 * time.Second)
+				initDocument()
+			}()
+			err := mongoTemplate.WatchWithHandlerE(ctx, tt.pipeline, tt.handler, tt.option)
+			if helper.IsNotEqualTo(helper.IsNotNil(err), tt.wantErr) {
+				t.Errorf("WatchWithHandlerE() error = %v, wantErr %v", err, tt.wantErr)
+			} else if helper.IsNotNil(err) {
+				t.Log("err expected:", err)
+			}
+		})
+	}
+}
+
+func TestTemplateWatchWithRouter(t *testing.T) {
+	initMongoTemplate()
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	go func() {
+		time.Sleep(2 * time.Second)
+		initDocument()
+	}()
+	router := NewEventRouter().OnInsert(testStruct{}, TypedEventHandler(
+		func(ctx context.Context, event *TypedEvent[testStruct]) error {
+			logger.Info("watch router insert:", event.FullDocument)
+			return nil
+		}))
+	err := mongoTemplate.WatchWithRouter(ctx, router, initOptionWatchHandler())
+	if helper.IsNotNil(err) {
+		t.Error("WatchWithRouter() error:", err)
+	}
+	err = mongoTemplate.WatchWithRouter(ctx, NewEventRouter())
+	if helper.IsNil(err) {
+		t.Error("WatchWithRouter() expected error router is empty")
+	}
+}
+
+func TestTemplateWatchChan(t *testing.T) {
+	initMongoTemplate()
+	for _, tt := range initListTestWatchChan() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), tt.durationTimeout)
+			defer cancel()
+			go func() {
+				time.Sleep(2 * time.Second)
+				initDocument()
+			}()
+			events, errs := mongoTemplate.WatchChan(ctx, tt.ref, tt.pipeline, tt.option)
+			var err error
+			select {
+			case event := <-events:
+				t.Log("watch chan event:", event.OperationType, event.DocumentKey)
+			case err = <-errs:
+			case <-ctx.Done():
+			}
+			if helper.IsNotEqualTo(helper.IsNotNil(err), tt.wantErr) {
+				t.Errorf("WatchChan() error = %v, wantErr %v", err, tt.wantErr)
+			} else if helper.IsNotNil(err) {
+				t.Log("err expected:", err)
+			}
+		})
+	}
+}
+
 func TestTemplateDropCollection(t *testing.T) {
 	initDocument()
 	time.Sleep(5 * time.Second)
@@ -564,6 +656,226 @@ func TestTemplateDropDatabase(t *testing.T) {
 	}
 }
 
+func TestTemplateApplyValidator(t *testing.T) {
+	initMongoTemplate()
+	for _, tt := range initListTestApplyValidator() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), tt.durationTimeout)
+			defer cancel()
+			err := mongoTemplate.ApplyValidator(ctx, tt.ref, tt.level, tt.action)
+			if helper.IsNotEqualTo(helper.IsNotNil(err), tt.wantErr) {
+				t.Errorf("ApplyValidator() error = %v, wantErr %v", err, tt.wantErr)
+			} else if helper.IsNotNil(err) {
+				t.Log("err expected:", err)
+			}
+		})
+	}
+}
+
+func TestTemplateNewLock(t *testing.T) {
+	initMongoTemplate()
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
+	defer cancel()
+	opt := initOptionLock()
+	lock := mongoTemplate.NewLock("test", 2*time.Second, opt)
+	other := mongoTemplate.NewLock("test", 2*time.Second, opt)
+	err := lock.Acquire(ctx)
+	if helper.IsNotNil(err) {
+		t.Fatal("Acquire() error:", err)
+	}
+	acquired, err := other.TryAcquire(ctx)
+	if helper.IsNotNil(err) || acquired {
+		t.Errorf("TryAcquire() acquired = %v, error = %v, expected lock held", acquired, err)
+	}
+	time.Sleep(3 * time.Second)
+	if !lock.IsHeld() {
+		t.Error("IsHeld() expected lock refreshed automatically")
+	}
+	token := lock.Token()
+	err = lock.Release(ctx)
+	if helper.IsNotNil(err) {
+		t.Error("Release() error:", err)
+	}
+	err = other.Acquire(ctx)
+	if helper.IsNotNil(err) {
+		t.Fatal("Acquire() error:", err)
+	} else if helper.IsLessThanOrEqual(other.Token(), token) {
+		t.Errorf("Token() = %v, expected greater than %v", other.Token(), token)
+	}
+	err = lock.Refresh(ctx)
+	if helper.IsNil(err) {
+		t.Error("Refresh() expected error lock not held")
+	} else {
+		t.Log("err expected:", err)
+	}
+	err = other.Release(ctx)
+	if helper.IsNotNil(err) {
+		t.Error("Release() error:", err)
+	}
+}
+
+func TestTemplateOutbox(t *testing.T) {
+	initMongoTemplate()
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
+	defer cancel()
+	outbox := mongoTemplate.NewOutbox(initOptionOutbox())
+	_ = outbox.collection().Drop(ctx)
+	err := mongoTemplate.StartSession(ctx)
+	if helper.IsNotNil(err) {
+		t.Fatal("StartSession() error:", err)
+	}
+	err = outbox.Add(ctx, OutboxEvent{Topic: "test.created", Key: "1", Payload: initTestStruct()},
+		initOptionOutboxAdd())
+	if helper.IsNotNil(err) {
+		t.Fatal("Add() error:", err)
+	}
+	err = outbox.Add(ctx, OutboxEvent{Topic: "test.retry", Payload: initTestStruct()}, initOptionOutboxAdd())
+	if helper.IsNotNil(err) {
+		t.Fatal("Add() error:", err)
+	}
+	err = outbox.Add(ctx, OutboxEvent{Topic: "test.dead", Payload: initTestStruct()},
+		initOptionInsertOne().SetDisableAutoCloseSession(false))
+	if helper.IsNotNil(err) {
+		t.Fatal("Add() error:", err)
+	}
+	err = outbox.Add(ctx, OutboxEvent{Payload: initTestStruct()}, initOptionInsertOne().SetDisableAutoCloseSession(false))
+	if helper.IsNil(err) {
+		t.Error("Add() expected error topic is empty")
+	}
+	published := make(chan OutboxMessage, 10)
+	relayCtx, relayCancel := context.WithCancel(ctx)
+	go func() {
+		_ = outbox.Relay(relayCtx, func(ctx context.Context, message OutboxMessage) error {
+			if helper.Equals(message.Topic, "test.dead") ||
+				(helper.Equals(message.Topic, "test.retry") && helper.Equals(message.Attempts, 1)) {
+				return errors.New("publish failed")
+			}
+			published <- message
+			return nil
+		}, initOptionOutboxRelay())
+	}()
+	for i := 0; i < 2; i++ {
+		select {
+		case message := <-published:
+			var dest testStruct
+			if err = message.DecodePayload(&dest); helper.IsNotNil(err) {
+				t.Error("DecodePayload() error:", err)
+			}
+			t.Log("message published:", message.Topic, "attempts:", message.Attempts)
+		case <-ctx.Done():
+			t.Fatal("Relay() expected messages published")
+		}
+	}
+	time.Sleep(time.Second)
+	relayCancel()
+	count, err := outbox.collection().CountDocuments(ctx, bson.M{"status": OutboxStatusDispatched})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(count, int64(2)) {
+		t.Errorf("Relay() dispatched = %v, error = %v, expected 2", count, err)
+	}
+	specifications, err := outbox.collection().Indexes().ListSpecifications(ctx)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(specifications), 3) {
+		t.Errorf("Relay() indexes = %v, error = %v, expected the claim and TTL indexes", len(specifications), err)
+	}
+	database := mongoTemplate.client.Database("test")
+	count, err = database.Collection("test_outbox_dead_letter").CountDocuments(ctx, bson.M{"topic": "test.dead"})
+	if helper.IsNotNil(err) || helper.IsLessThan(count, int64(1)) {
+		t.Errorf("Relay() dead letter = %v, error = %v, expected 1", count, err)
+	}
+}
+
+func TestTemplateOutboxDeadLetterClaimed(t *testing.T) {
+	initMongoTemplate()
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+	outbox := mongoTemplate.NewOutbox(initOptionOutbox())
+	_ = outbox.collection().Drop(ctx)
+	now := time.Now().Truncate(time.Millisecond)
+	message := OutboxMessage{Id: primitive.NewObjectID(), Topic: "test.claimed", Status: OutboxStatusProcessing,
+		Attempts: 2, CreatedAt: now, NextAttemptAt: now, ClaimExpiresAt: now.Add(time.Minute)}
+	_, err := outbox.collection().InsertOne(ctx, message)
+	if helper.IsNotNil(err) {
+		t.Fatal("InsertOne() error:", err)
+	}
+	// the claim of the relay expired and the message was claimed by another one
+	expired := message
+	expired.ClaimExpiresAt = now.Add(-time.Minute)
+	claimFilter := bson.M{"_id": message.Id, "status": OutboxStatusProcessing, "claimExpiresAt": expired.ClaimExpiresAt}
+	err = outbox.deadLetter(ctx, expired, claimFilter, errors.New("publish failed"))
+	if helper.IsNotNil(err) {
+		t.Error("deadLetter() error:", err)
+	}
+	count, err := outbox.collection().CountDocuments(ctx, bson.M{"status": OutboxStatusProcessing})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(count, int64(1)) {
+		t.Errorf("deadLetter() processing = %v, error = %v, expected the message kept", count, err)
+	}
+}
+
+func TestOutboxRelayPublisherIsNil(t *testing.T) {
+	err := (&Template{}).NewOutbox(initOptionOutbox()).Relay(context.TODO(), nil)
+	if helper.IsNotEqualTo(err, ErrOutboxPublisherIsNil) {
+		t.Errorf("Relay() error = %v, want %v", err, ErrOutboxPublisherIsNil)
+	}
+}
+
+func TestTemplateCache(t *testing.T) {
+	initMongoTemplate()
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+	mongoTemplate.SetCache(NewLRUCache(100), initOptionCache())
+	defer mongoTemplate.SetCache(nil)
+	go func() {
+		err := mongoTemplate.WatchCacheInvalidation(ctx, initOptionWatchHandler())
+		if helper.IsNotNil(err) {
+			t.Log("watch cache invalidation err:", err)
+		}
+	}()
+	test := initTestStruct()
+	err := mongoTemplate.InsertOne(ctx, test)
+	if helper.IsNotNil(err) {
+		t.Fatal("InsertOne() error:", err)
+	}
+	var result testStruct
+	for i := 0; i < 2; i++ {
+		err = mongoTemplate.FindOneById(ctx, test.Id, &result)
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(result.Name, test.Name) {
+			t.Fatalf("FindOneById() error = %v, result = %+v", err, result)
+		}
+	}
+	_, err = mongoTemplate.UpdateOneById(ctx, test.Id, bson.M{"$set": bson.M{"name": "Cache Updated"}}, testStruct{})
+	if helper.IsNotNil(err) {
+		t.Fatal("UpdateOneById() error:", err)
+	}
+	err = mongoTemplate.FindOne(ctx, bson.M{"_id": test.Id}, &result)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(result.Name, "Cache Updated") {
+		t.Errorf("FindOne() error = %v, result = %+v, expected evicted document", err, result)
+	}
+	err = mongoTemplate.FindOne(ctx, bson.M{"name": "Cache Updated"}, &result)
+	if helper.IsNotNil(err) {
+		t.Fatal("FindOne() error:", err)
+	}
+	_, err = mongoTemplate.UpdateMany(ctx, bson.M{"_id": test.Id}, bson.M{"$set": bson.M{"name": "Cache Many"}},
+		testStruct{})
+	if helper.IsNotNil(err) {
+		t.Fatal("UpdateMany() error:", err)
+	}
+	err = mongoTemplate.FindOne(ctx, bson.M{"name": "Cache Updated"}, &result)
+	if !errors.Is(err, ErrNoDocuments) {
+		t.Errorf("FindOne() error = %v, expected evicted filter", err)
+	}
+	err = mongoTemplate.FindOneById(ctx, test.Id, &result)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(result.Name, "Cache Many") {
+		t.Errorf("FindOneById() error = %v, result = %+v, expected evicted document", err, result)
+	}
+	_, err = mongoTemplate.DeleteOneById(ctx, test.Id, testStruct{})
+	if helper.IsNotNil(err) {
+		t.Fatal("DeleteOneById() error:", err)
+	}
+	err = mongoTemplate.FindOneById(ctx, test.Id, &result)
+	if !errors.Is(err, ErrNoDocuments) {
+		t.Errorf("FindOneById() error = %v, expected ErrNoDocuments", err)
+	}
+}
+
 func TestTemplateCreateOneIndex(t *testing.T) {
 	initDocument()
 	clearIndexes()
@@ -694,3 +1006,12 @@ func TestTemplateAbortTransaction(t *testing.T) {
 	err = mongoTemplate.AbortTransaction(ctx)
 	logger.Info("result err:", err)
 }
+
+func TestTemplateWithSession(t *testing.T) {
+	err := (&Template{}).WithSession(context.TODO(), func(sc mongo.SessionContext) error {
+		return nil
+	})
+	if helper.IsNotEqualTo(err, ErrNoOpenSession) {
+		t.Errorf("WithSession() error = %v, want %v", err, ErrNoOpenSession)
+	}
+}
@@ -0,0 +1,86 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"sync"
+	"time"
+)
+
+// TokenStore represents a storage of the change stream resume tokens, see the option.TokenStore documentation.
+type TokenStore = option.TokenStore
+
+// MongoTokenStore is a TokenStore that saves the resume tokens on a collection, each key is a document.
+type MongoTokenStore struct {
+	collection *mongo.Collection
+}
+
+// MemoryTokenStore is a TokenStore that keeps the resume tokens in memory, it is useful for tests and for consumers
+// that only need to resume after reconnections of the same process.
+type MemoryTokenStore struct {
+	mutex  sync.RWMutex
+	tokens map[string]bson.Raw
+}
+
+type tokenDocument struct {
+	Key       string    `bson:"_id"`
+	Token     bson.Raw  `bson:"token"`
+	UpdatedAt time.Time `bson:"updatedAt"`
+}
+
+// NewMongoTokenStore creates a MongoTokenStore saving the resume tokens on the collection of the databaseName and
+// collectionName parameters.
+func (t *Template) NewMongoTokenStore(databaseName, collectionName string) *MongoTokenStore {
+	return &MongoTokenStore{
+		collection: t.client.Database(databaseName).Collection(collectionName),
+	}
+}
+
+// NewMemoryTokenStore creates a new MemoryTokenStore instance.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: map[string]bson.Raw{},
+	}
+}
+
+// Load returns the last resume token saved with the key, or nil if there is none.
+func (m *MongoTokenStore) Load(ctx context.Context, key string) (bson.Raw, error) {
+	var result tokenDocument
+	err := m.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	} else if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return result.Token, nil
+}
+
+// Save stores the resume token with the key, replacing the previous one.
+func (m *MongoTokenStore) Save(ctx context.Context, key string, token bson.Raw) error {
+	_, err := m.collection.ReplaceOne(ctx, bson.M{"_id": key}, tokenDocument{
+		Key:       key,
+		Token:     token,
+		UpdatedAt: time.Now(),
+	}, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Load returns the last resume token saved with the key, or nil if there is none.
+func (m *MemoryTokenStore) Load(_ context.Context, key string) (bson.Raw, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.tokens[key], nil
+}
+
+// Save stores the resume token with the key, replacing the previous one.
+func (m *MemoryTokenStore) Save(_ context.Context, key string, token bson.Raw) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.tokens[key] = token
+	return nil
+}
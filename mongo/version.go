@@ -0,0 +1,293 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/internal/util"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+const historyCollectionSuffix = "_history"
+
+// DocumentVersion represents a version of a versioned document, the prior versions are stored on the
+// <collection>_history collection.
+//
+// The versioned documents are the structures with the versioned:"true" tag, next to the database and collection
+// tags, their UpdateOneById and ReplaceOneById operations copy the prior version of the document to the history
+// collection, in the same transaction of the write.
+type DocumentVersion struct {
+	Id primitive.ObjectID `bson:"_id"`
+	// DocumentId the _id of the versioned document
+	DocumentId any `bson:"documentId"`
+	// Version the version number, starting at 1
+	Version int64 `bson:"version"`
+	// ValidFrom the start of the validity interval, the first version is valid since the timestamp of the _id, if it
+	// is an ObjectID, otherwise since the zero time
+	ValidFrom time.Time `bson:"validFrom"`
+	// ValidTo the end of the validity interval, exclusive, zero if it is the current version
+	ValidTo time.Time `bson:"validTo,omitempty"`
+	// Document the document of the version
+	Document bson.Raw `bson:"document"`
+}
+
+// versionWrite represents a write on a versioned document, the document is read before the write, in the same
+// session.
+type versionWrite struct {
+	history *mongo.Collection
+	before  bson.Raw
+}
+
+// FindAsOf executes a find command returning the version of the document with the id parameter valid at the asOf
+// parameter, from the history collection or the current document, and decodes it into dest. If the document did not
+// exist at the asOf parameter, the ErrNoDocuments error is returned.
+//
+// The dest parameter must be a pointer to a versioned structure, see DocumentVersion.
+func (t *Template) FindAsOf(ctx context.Context, id any, asOf time.Time, dest any) error {
+	op := &Operation{Name: "FindAsOf", Ref: dest, Filter: bson.D{{"_id", id}}}
+	return t.execute(ctx, op, func(ctx context.Context, op *Operation) error {
+		if helper.IsNotPointer(dest) {
+			return ErrDestIsNotPointer
+		} else if helper.IsNotStruct(dest) {
+			return ErrDestIsNotStruct
+		}
+		_, collection, err := t.getMongoInfosByAny(dest)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		history := historyCollection(collection)
+		var version DocumentVersion
+		err = history.FindOne(ctx, bson.D{
+			{"documentId", id},
+			{"validFrom", bson.D{{"$lte", asOf}}},
+			{"validTo", bson.D{{"$gt", asOf}}},
+		}).Decode(&version)
+		if helper.IsNil(err) {
+			return bson.Unmarshal(version.Document, dest)
+		} else if !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+		current, err := currentVersion(ctx, collection, history, op.Filter)
+		if helper.IsNotNil(err) {
+			return err
+		} else if asOf.Before(current.ValidFrom) {
+			return ErrNoDocuments
+		}
+		return bson.Unmarshal(current.Document, dest)
+	})
+}
+
+// ListVersions executes a find command returning the versions of the document with the id parameter sorted by the
+// version number, the prior versions from the history collection followed by the current document, if it exists.
+//
+// The ref parameter must be a versioned structure, see DocumentVersion.
+func (t *Template) ListVersions(ctx context.Context, id, ref any) ([]DocumentVersion, error) {
+	op := &Operation{Name: "ListVersions", Ref: ref, Filter: bson.D{{"_id", id}}}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) ([]DocumentVersion, error) {
+		_, collection, err := t.getMongoInfosByAny(ref)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		history := historyCollection(collection)
+		cursor, err := history.Find(ctx, bson.D{{"documentId", id}}, options.Find().SetSort(bson.D{{"version", 1}}))
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		var result []DocumentVersion
+		if err = cursor.All(ctx, &result); helper.IsNotNil(err) {
+			return nil, err
+		}
+		current, err := currentVersion(ctx, collection, history, op.Filter)
+		if errors.Is(err, ErrNoDocuments) {
+			return result, nil
+		} else if helper.IsNotNil(err) {
+			return nil, err
+		}
+		return append(result, *current), nil
+	})
+}
+
+// RevertTo executes a replace command replacing the document with the id parameter by its version with the version
+// parameter, the current document is copied to the history collection as a new version, like ReplaceOneById. If the
+// version does not exist on the history collection, the ErrVersionNotFound error is returned.
+//
+// The ref parameter must be a versioned structure, see DocumentVersion.
+//
+// The opts parameter can be used to specify options for the operation (see the option.Replace documentation.)
+func (t *Template) RevertTo(ctx context.Context, id any, version int64, ref any, opts ...*option.Replace) (
+	*UpdateResult, error) {
+	op := &Operation{Name: "RevertTo", Ref: ref, Filter: bson.D{{"_id", id}}, Options: opts}
+	return executeResult(ctx, t, op, func(ctx context.Context, op *Operation) (*UpdateResult, error) {
+		_, collection, err := t.getMongoInfosByAny(ref)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		var documentVersion DocumentVersion
+		err = historyCollection(collection).FindOne(ctx, bson.D{{"documentId", id}, {"version", version}}).
+			Decode(&documentVersion)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrVersionNotFound
+		} else if helper.IsNotNil(err) {
+			return nil, err
+		}
+		var result *UpdateResult
+		opt := option.MergeReplaceByParams(operationOptions(op, opts), globalOption)
+		err = t.ensureVersionIndex(ctx, ref)
+		if helper.IsNil(err) {
+			err = t.startSession(ctx, *opt.ForceRecreateSession, opt.WriteConcern, opt.CausalConsistency)
+		}
+		if helper.IsNil(err) {
+			err = mongo.WithSession(ctx, t.session, func(sc mongo.SessionContext) error {
+				var write *versionWrite
+				write, err = t.beginVersion(sc, op.Filter, ref)
+				if helper.IsNil(err) {
+					result, err = t.replaceOne(sc, op.Filter, documentVersion.Document, ref, opt)
+				}
+				if helper.IsNil(err) {
+					err = write.commit(sc, result)
+				}
+				return t.closeSessionAutomatically(sc, *opt.DisableAutoCloseSession, *opt.DisableAutoRollbackSession, err)
+			})
+		}
+		if helper.IsNil(err) {
+			t.evictCacheById(ctx, ref, id)
+		}
+		return result, err
+	})
+}
+
+// ensureVersionIndex creates the unique index of the history collection of the ref parameter, if it is versioned,
+// outside the session, since the indexes can not be created within a transaction on an existing collection.
+func (t *Template) ensureVersionIndex(ctx context.Context, ref any) error {
+	if !util.IsVersionedStruct(ref) {
+		return nil
+	}
+	_, collection, err := t.getMongoInfosByAny(ref)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	history := historyCollection(collection)
+	key := history.Database().Name() + "." + history.Name()
+	if _, ok := t.versionIndexes.Load(key); ok {
+		return nil
+	}
+	_, err = history.Indexes().CreateOne(ctx, parseIndexInputToModel(IndexInput{
+		Keys:    bson.D{{"documentId", 1}, {"version", 1}},
+		Options: option.NewIndex().SetName("documentId_version").SetUnique(true),
+	}))
+	if helper.IsNil(err) {
+		t.versionIndexes.Store(key, struct{}{})
+	}
+	return err
+}
+
+// beginVersion reads the document matched by the filter parameter before the write, if the ref parameter is not
+// versioned or the document does not exist, it returns a nil versionWrite.
+func (t *Template) beginVersion(sc mongo.SessionContext, filter, ref any) (*versionWrite, error) {
+	if !util.IsVersionedStruct(ref) {
+		return nil, nil
+	}
+	_, collection, err := t.getMongoInfosByAny(ref)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	before, err := collection.FindOne(sc, filter).Raw()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	} else if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return &versionWrite{history: historyCollection(collection), before: before}, nil
+}
+
+// commit inserts the document read before the write on the history collection within the session, if it was
+// modified, the version number is the last version number plus one.
+func (v *versionWrite) commit(sc mongo.SessionContext, result *UpdateResult) error {
+	if helper.IsNil(v) || helper.IsNil(result) || helper.IsLessThanOrEqual(result.ModifiedCount, 0) {
+		return nil
+	}
+	id := v.before.Lookup("_id")
+	last, err := lastVersion(sc, v.history, id)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	version := DocumentVersion{
+		Id:         primitive.NewObjectID(),
+		DocumentId: id,
+		Version:    1,
+		ValidFrom:  versionCreatedAt(id),
+		ValidTo:    time.Now(),
+		Document:   v.before,
+	}
+	if helper.IsNotNil(last) {
+		version.Version = last.Version + 1
+		version.ValidFrom = last.ValidTo
+	}
+	_, err = v.history.InsertOne(sc, version)
+	return err
+}
+
+// currentVersion returns the document matched by the filter parameter as the version following the last version of
+// the history collection.
+func currentVersion(ctx context.Context, collection, history *mongo.Collection, filter any) (*DocumentVersion,
+	error) {
+	document, err := collection.FindOne(ctx, filter).Raw()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNoDocuments
+	} else if helper.IsNotNil(err) {
+		return nil, err
+	}
+	id := document.Lookup("_id")
+	last, err := lastVersion(ctx, history, id)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	result := &DocumentVersion{
+		DocumentId: id,
+		Version:    1,
+		ValidFrom:  versionCreatedAt(id),
+		Document:   document,
+	}
+	if helper.IsNotNil(last) {
+		result.Version = last.Version + 1
+		result.ValidFrom = last.ValidTo
+	}
+	return result, nil
+}
+
+func lastVersion(ctx context.Context, history *mongo.Collection, id any) (*DocumentVersion, error) {
+	var result DocumentVersion
+	err := history.FindOne(ctx, bson.D{{"documentId", id}}, options.FindOne().SetSort(bson.D{{"version", -1}})).
+		Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	} else if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func historyCollection(collection *mongo.Collection) *mongo.Collection {
+	return collection.Database().Collection(collection.Name() + historyCollectionSuffix)
+}
+
+// versionCreatedAt returns the timestamp of the id parameter, if it is an ObjectID, otherwise the zero time.
+func versionCreatedAt(id bson.RawValue) time.Time {
+	if objectId, ok := id.ObjectIDOK(); ok {
+		return objectId.Timestamp()
+	}
+	return time.Time{}
+}
+
+// Decode convert DocumentVersion.Document to dest
+func (v DocumentVersion) Decode(dest any) error {
+	if helper.IsNotPointer(dest) {
+		return ErrDestIsNotPointer
+	}
+	return bson.Unmarshal(v.Document, dest)
+}
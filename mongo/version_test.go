@@ -0,0 +1,71 @@
+package mongo
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+type testVersionedStruct struct {
+	Id   primitive.ObjectID `bson:"_id,omitempty" database:"test" collection:"testVersioned" versioned:"true"`
+	Name string             `bson:"name,omitempty"`
+}
+
+func TestVersionCreatedAt(t *testing.T) {
+	objectId := primitive.NewObjectIDFromTimestamp(time.Unix(1700000000, 0))
+	raw, _ := bson.Marshal(bson.D{{"objectId", objectId}, {"string", "id"}})
+	if got := versionCreatedAt(bson.Raw(raw).Lookup("objectId")); !got.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("versionCreatedAt() = %v, want %v", got, time.Unix(1700000000, 0))
+	}
+	if got := versionCreatedAt(bson.Raw(raw).Lookup("string")); !got.IsZero() {
+		t.Errorf("versionCreatedAt() = %v, want zero", got)
+	}
+}
+
+func TestTemplateVersioning(t *testing.T) {
+	initMongoTemplate()
+	ctx := context.TODO()
+	test := &testVersionedStruct{Name: "v1"}
+	if err := mongoTemplate.InsertOne(ctx, test); helper.IsNotNil(err) {
+		t.Fatal("InsertOne() error:", err)
+	}
+	_, err := mongoTemplate.UpdateOneById(ctx, test.Id, bson.M{"$set": bson.M{"name": "v2"}}, testVersionedStruct{})
+	if helper.IsNotNil(err) {
+		t.Fatal("UpdateOneById() error:", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	asOfV2 := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	_, err = mongoTemplate.ReplaceOneById(ctx, test.Id, testVersionedStruct{Name: "v3"}, testVersionedStruct{})
+	if helper.IsNotNil(err) {
+		t.Fatal("ReplaceOneById() error:", err)
+	}
+	versions, err := mongoTemplate.ListVersions(ctx, test.Id, testVersionedStruct{})
+	if helper.IsNotNil(err) {
+		t.Fatal("ListVersions() error:", err)
+	} else if helper.IsNotEqualTo(len(versions), 3) || helper.IsNotEqualTo(versions[2].Version, int64(3)) ||
+		!versions[2].ValidTo.IsZero() {
+		t.Fatalf("ListVersions() = %v", versions)
+	}
+	var dest testVersionedStruct
+	if err = mongoTemplate.FindAsOf(ctx, test.Id, asOfV2, &dest); helper.IsNotNil(err) {
+		t.Fatal("FindAsOf() error:", err)
+	} else if helper.IsNotEqualTo(dest.Name, "v2") {
+		t.Errorf("FindAsOf() name = %v, want v2", dest.Name)
+	}
+	if _, err = mongoTemplate.RevertTo(ctx, test.Id, 1, testVersionedStruct{}); helper.IsNotNil(err) {
+		t.Fatal("RevertTo() error:", err)
+	}
+	if err = mongoTemplate.FindOneById(ctx, test.Id, &dest); helper.IsNotNil(err) {
+		t.Fatal("FindOneById() error:", err)
+	} else if helper.IsNotEqualTo(dest.Name, "v1") {
+		t.Errorf("RevertTo() name = %v, want v1", dest.Name)
+	}
+	if _, err = mongoTemplate.RevertTo(ctx, test.Id, 10, testVersionedStruct{}); helper.IsNotEqualTo(err,
+		ErrVersionNotFound) {
+		t.Errorf("RevertTo() error = %v, want %v", err, ErrVersionNotFound)
+	}
+}
@@ -2,45 +2,146 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
 	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"hash/fnv"
+	"sync"
+	"time"
 )
 
 type FullDocument bson.M
 
+// ChangeEvent represents the fields of a change stream event that do not depend on the document type, for more
+// information, see https://www.mongodb.com/docs/manual/reference/change-events/.
+type ChangeEvent struct {
+	// ResumeToken the resume token of the event, it can be used on option.Watch.ResumeAfter or option.Watch.StartAfter
+	ResumeToken bson.Raw `bson:"_id"`
+	// OperationType the type of operation that occurred
+	OperationType OperationType `bson:"operationType"`
+	// ClusterTime the timestamp from the oplog entry associated with the event
+	ClusterTime primitive.Timestamp `bson:"clusterTime"`
+	// WallTime the server date and time of the database operation, only returned by MongoDB versions >= 6.0
+	WallTime time.Time `bson:"wallTime,omitempty"`
+	// TxnNumber the transaction number, only present if the operation is part of a multi-document transaction
+	TxnNumber *int64 `bson:"txnNumber,omitempty"`
+	// LSID the identifier of the session associated with the transaction, only present if the operation is part of a
+	// multi-document transaction
+	LSID bson.M `bson:"lsid,omitempty"`
+	// NS the namespace affected by the event
+	NS Namespace `bson:"ns"`
+	// To the new namespace of a rename event
+	To *Namespace `bson:"to,omitempty"`
+	// DocumentKey the _id of the document affected by the event, and the shard key fields on sharded collections
+	DocumentKey DocumentKey `bson:"documentKey"`
+	// UpdateDescription the fields that were updated or removed by an update event
+	UpdateDescription UpdateDescription `bson:"updateDescription"`
+	// CollectionUUID the UUID of the collection affected by the event, only present with
+	// option.Watch.ShowExpandedEvents
+	CollectionUUID *primitive.Binary `bson:"collectionUUID,omitempty"`
+	// OperationDescription additional information of the DDL events, e.g. the indexes of a createIndexes event, only
+	// present with option.Watch.ShowExpandedEvents
+	OperationDescription bson.M `bson:"operationDescription,omitempty"`
+	// StateBeforeChange the collection options before a modify event, only present with
+	// option.Watch.ShowExpandedEvents
+	StateBeforeChange bson.M `bson:"stateBeforeChange,omitempty"`
+	// NsType the type of the namespace of a create event, e.g. "collection", "timeseries" or "view"
+	NsType string `bson:"nsType,omitempty"`
+	// SplitEvent the fragment information of a large event split by the $changeStreamSplitLargeEvent stage
+	SplitEvent *SplitEvent `bson:"splitEvent,omitempty"`
+}
+
+// Event represents a change stream event, with the full documents as FullDocument maps.
 type Event struct {
-	DocumentKey       documentKey         `bson:"documentKey"`
-	NS                ns                  `bson:"ns"`
-	OperationType     string              `bson:"operationType"`
-	FullDocument      FullDocument        `bson:"fullDocument"`
-	UpdateDescription updateDescription   `bson:"updateDescription"`
-	ClusterTime       primitive.Timestamp `bson:"clusterTime"`
+	ChangeEvent `bson:",inline"`
+	// FullDocument the document after the change, see option.Watch.FullDocument
+	FullDocument FullDocument `bson:"fullDocument"`
+	// FullDocumentBeforeChange the document before the change, see option.Watch.FullDocumentBeforeChange
+	FullDocumentBeforeChange FullDocument `bson:"fullDocumentBeforeChange"`
 }
 
-type ns struct {
+// TypedEvent represents a change stream event with the full documents decoded into T, see DecodeEvent.
+type TypedEvent[T any] struct {
+	ChangeEvent `bson:",inline"`
+	// FullDocument the document after the change, see option.Watch.FullDocument
+	FullDocument *T `bson:"fullDocument"`
+	// FullDocumentBeforeChange the document before the change, see option.Watch.FullDocumentBeforeChange
+	FullDocumentBeforeChange *T `bson:"fullDocumentBeforeChange"`
+}
+
+// Namespace represents the database and collection of a change stream event.
+type Namespace struct {
 	DB   string `bson:"db"`
-	Coll string `bson:"coll"`
+	Coll string `bson:"coll,omitempty"`
+}
+
+// DocumentKey represents the key of the document affected by a change stream event.
+type DocumentKey struct {
+	// ID the _id of the document, it keeps the BSON type, e.g. primitive.ObjectID, string or primitive.D
+	ID any `bson:"_id"`
+	// ShardKey the shard key fields on sharded collections
+	ShardKey bson.M `bson:",inline"`
+}
+
+// UpdateDescription represents the changes of an update change stream event.
+type UpdateDescription struct {
+	UpdatedFields      map[string]any   `bson:"updatedFields"`
+	RemovedFields      []string         `bson:"removedFields"`
+	TruncatedArrays    []TruncatedArray `bson:"truncatedArrays"`
+	DisambiguatedPaths bson.M           `bson:"disambiguatedPaths,omitempty"`
 }
 
-type documentKey struct {
-	ID primitive.ObjectID `bson:"_id"`
+// TruncatedArray represents an array truncated by an update change stream event.
+type TruncatedArray struct {
+	Field   string `bson:"field"`
+	NewSize int32  `bson:"newSize"`
 }
 
-type updateDescription struct {
-	UpdatedFields   map[string]any `bson:"updatedFields"`
-	RemovedFields   []string       `bson:"removedFields"`
-	TruncatedArrays []string       `bson:"truncatedArrays"`
+// SplitEvent represents the fragment information of a split change stream event.
+type SplitEvent struct {
+	Fragment int32 `bson:"fragment"`
+	Of       int32 `bson:"of"`
 }
 
 type EventContext struct {
 	context.Context
 	Event Event
+	// Raw the change stream event as received from the server, it can be decoded with DecodeEvent
+	Raw bson.Raw
 }
 
 type EventHandler func(ctx *EventContext)
 
+// EventHandlerE is an EventHandler that returns an error when the event processing failed, see
+// Template.WatchWithHandlerE.
+type EventHandlerE func(ctx *EventContext) error
+
+// DecodeEvent decodes the raw change stream event into a TypedEvent, decoding the full documents straight into T,
+// e.g. DecodeEvent[User](ctx.Raw) on an EventHandler.
+func DecodeEvent[T any](raw bson.Raw) (*TypedEvent[T], error) {
+	var result TypedEvent[T]
+	err := bson.Unmarshal(raw, &result)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// TypedEventHandler adapts a handler of TypedEvent to EventHandlerE, decoding each event into T before calling it.
+func TypedEventHandler[T any](handler func(ctx context.Context, event *TypedEvent[T]) error) EventHandlerE {
+	return func(ctx *EventContext) error {
+		event, err := DecodeEvent[T](ctx.Raw)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		return handler(ctx, event)
+	}
+}
+
 // Decode convert Event.FullDocument to struct
 func (f FullDocument) Decode(dest any) error {
 	if helper.IsNotStruct(dest) {
@@ -49,22 +150,274 @@ func (f FullDocument) Decode(dest any) error {
 	return helper.ConvertToDest(f, dest)
 }
 
-func processNextEvent(handler EventHandler, event Event, opt *option.WatchWithHandler) {
-	ctx, cancel := context.WithTimeout(context.TODO(), opt.ContextFuncTimeout)
+type eventTask struct {
+	seq         uint64
+	current     bson.Raw
+	resumeToken bson.Raw
+}
+
+type eventPool struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	handler    EventHandlerE
+	opt        *option.WatchWithHandler
+	queues     []chan eventTask
+	wg         sync.WaitGroup
+	mutex      sync.Mutex
+	err        error
+	nextSeq    uint64
+	commitSeq  uint64
+	done       map[uint64]bson.Raw
+	lastCommit bson.Raw
+	savedSeq   uint64
+	saving     bool
+}
+
+func newEventPool(ctx context.Context, handler EventHandlerE, opt *option.WatchWithHandler) *eventPool {
+	poolCtx, cancel := context.WithCancel(ctx)
+	p := &eventPool{
+		ctx:     poolCtx,
+		cancel:  cancel,
+		handler: handler,
+		opt:     opt,
+		queues:  make([]chan eventTask, opt.Concurrency),
+		done:    map[uint64]bson.Raw{},
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan eventTask, opt.QueueSize)
+		p.wg.Add(1)
+		go p.work(p.queues[i])
+	}
+	return p
+}
+
+// dispatch sends the event to the worker of its partition, blocking while the worker queue is full, it returns false
+// if the pool was stopped by an event failure or by the ctx.
+func (p *eventPool) dispatch(current, resumeToken bson.Raw) bool {
+	task := eventTask{seq: p.nextSeq, current: current, resumeToken: resumeToken}
+	p.nextSeq++
+	select {
+	case p.queues[eventPartition(current, len(p.queues))] <- task:
+		return true
+	case <-p.ctx.Done():
+		return false
+	}
+}
+
+// wait stops receiving events, waits the workers to process the queued ones and returns the first event failure.
+func (p *eventPool) wait() error {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+	p.cancel()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.err
+}
+
+// checkpoint returns the resume token of the last event processed with all the previous ones.
+func (p *eventPool) checkpoint() bson.Raw {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.lastCommit
+}
+
+func (p *eventPool) work(queue <-chan eventTask) {
+	defer p.wg.Done()
+	for task := range queue {
+		if helper.IsNotNil(p.ctx.Err()) {
+			continue
+		}
+		err := processNextEventWithPolicy(p.ctx, p.handler, task.current, p.opt)
+		if helper.IsNotNil(err) {
+			p.fail(err)
+		} else {
+			p.complete(task)
+		}
+	}
+}
+
+func (p *eventPool) fail(err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if helper.IsNil(p.err) {
+		p.err = err
+	}
+	p.cancel()
+}
+
+// complete advances the checkpoint with the events processed in order and saves it on the option.WatchWithHandler
+// TokenStore outside the mutex. Only one worker saves at a time, saving the newest checkpoint until it stops advancing,
+// so the checkpoints advanced during a save are coalesced and an older one never overwrites a newer one.
+func (p *eventPool) complete(task eventTask) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.done[task.seq] = task.resumeToken
+	for {
+		resumeToken, ok := p.done[p.commitSeq]
+		if !ok {
+			break
+		}
+		delete(p.done, p.commitSeq)
+		p.commitSeq++
+		p.lastCommit = resumeToken
+	}
+	if helper.IsNil(p.opt.TokenStore) || p.saving {
+		return
+	}
+	p.saving = true
+	for helper.IsNotEqualTo(p.savedSeq, p.commitSeq) {
+		seq, resumeToken := p.commitSeq, p.lastCommit
+		p.mutex.Unlock()
+		err := p.opt.TokenStore.Save(context.WithoutCancel(p.ctx), p.opt.TokenKey, resumeToken)
+		if helper.IsNotNil(err) {
+			sendWatchError(p.opt, err)
+			logger.Error("error save resume token on watch handler, err:", err)
+		}
+		p.mutex.Lock()
+		p.savedSeq = seq
+	}
+	p.saving = false
+}
+
+func eventPartition(current bson.Raw, size int) int {
+	if helper.IsLessThanOrEqual(size, 1) {
+		return 0
+	}
+	h := fnv.New32a()
+	documentKey, err := current.LookupErr("documentKey")
+	if helper.IsNil(err) {
+		_, _ = h.Write(documentKey.Value)
+	}
+	return int(h.Sum32() % uint32(size))
+}
+
+func processNextEventWithPolicy(ctx context.Context, handler EventHandlerE, current bson.Raw,
+	opt *option.WatchWithHandler) error {
+	var event Event
+	err := bson.Unmarshal(current, &event)
+	if helper.IsNil(err) {
+		err = processNextEventWithRetry(ctx, handler, event, current, opt)
+	}
+	if helper.IsNil(err) || helper.IsNotNil(ctx.Err()) {
+		return err
+	}
+	sendWatchError(opt, err)
+	if opt.DeadLetter == nil {
+		return err
+	}
+	return opt.DeadLetter(ctx, append(bson.Raw(nil), current...), err)
+}
+
+func processNextEventWithRetry(ctx context.Context, handler EventHandlerE, event Event, current bson.Raw,
+	opt *option.WatchWithHandler) error {
+	delay := opt.RetryDelay
+	for retry := 0; ; retry++ {
+		err := processNextEvent(ctx, handler, event, current, opt)
+		if helper.IsNil(err) || helper.IsNotNil(ctx.Err()) || helper.IsGreaterThanOrEqual(retry, *opt.MaxRetries) {
+			return err
+		}
+		sendWatchError(opt, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if helper.IsGreaterThan(delay, opt.MaxRetryDelay) {
+			delay = opt.MaxRetryDelay
+		}
+	}
+}
+
+func processNextEvent(parent context.Context, handler EventHandlerE, event Event, current bson.Raw,
+	opt *option.WatchWithHandler) error {
+	ctx, cancel := context.WithTimeout(parent, opt.ContextFuncTimeout)
 	defer cancel()
-	signal := make(chan struct{}, 1)
-	go executeEventHandler(ctx, handler, event, &signal)
+	signal := make(chan error, 1)
+	go executeEventHandler(ctx, handler, event, current, signal)
 	select {
+	case err := <-signal:
+		if !isEventHandlerTimeout(ctx, err) {
+			return err
+		}
 	case <-ctx.Done():
-	case <-signal:
-		break
+		// the handler context is cancelled, so we wait the handler to return, avoiding that it runs concurrently with
+		// the next events of the same document
+		cancel()
+		select {
+		case <-signal:
+		case <-time.After(opt.ContextFuncTimeout):
+			logger.Error("event handler did not return after its context was cancelled")
+		}
 	}
+	if helper.IsNotNil(parent.Err()) {
+		return parent.Err()
+	}
+	if opt.OnTimeout != nil {
+		opt.OnTimeout(parent, append(bson.Raw(nil), current...), opt.ContextFuncTimeout)
+	}
+	if *opt.TimeoutAsFailure {
+		return ErrEventHandlerTimeout
+	}
+	return nil
+}
+
+// isEventHandlerTimeout returns true if the err parameter, returned by the handler, is the deadline of the handler
+// ctx, since the select picks at random when the deadline is reached together with the return of the handler.
+func isEventHandlerTimeout(ctx context.Context, err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) && helper.IsNotNil(ctx.Err())
 }
 
-func executeEventHandler(ctx context.Context, handler EventHandler, event Event, signal *chan struct{}) {
-	handler(&EventContext{
+func executeEventHandler(ctx context.Context, handler EventHandlerE, event Event, current bson.Raw,
+	signal chan<- error) {
+	signal <- handler(&EventContext{
 		Context: ctx,
 		Event:   event,
+		Raw:     current,
 	})
-	*signal <- struct{}{}
+}
+
+func sendWatchError(opt *option.WatchWithHandler, err error) {
+	if helper.IsNil(opt.Errors) {
+		return
+	}
+	select {
+	case opt.Errors <- err:
+	default:
+	}
+}
+
+func parseWatchHandlerToWatch(opt *option.WatchWithHandler) *option.Watch {
+	return &option.Watch{
+		DatabaseName:             opt.DatabaseName,
+		CollectionName:           opt.CollectionName,
+		BatchSize:                opt.BatchSize,
+		Collation:                opt.Collation,
+		Comment:                  opt.Comment,
+		FullDocument:             opt.FullDocument,
+		FullDocumentBeforeChange: opt.FullDocumentBeforeChange,
+		MaxAwaitTime:             opt.MaxAwaitTime,
+		ResumeAfter:              opt.ResumeAfter,
+		ShowExpandedEvents:       opt.ShowExpandedEvents,
+		StartAtOperationTime:     opt.StartAtOperationTime,
+		StartAfter:               opt.StartAfter,
+		Custom:                   opt.Custom,
+		CustomPipeline:           opt.CustomPipeline,
+	}
+}
+
+func setResumeTokenOnWatch(opt *option.Watch, resumeToken bson.Raw) {
+	opt.ResumeAfter = nil
+	opt.StartAtOperationTime = nil
+	opt.StartAfter = resumeToken
+}
+
+func isResumableChangeStreamError(err error) bool {
+	var labeledErr mongo.LabeledError
+	if errors.As(err, &labeledErr) && labeledErr.HasErrorLabel("ResumableChangeStreamError") {
+		return true
+	}
+	return mongo.IsNetworkError(err) || mongo.IsTimeout(err)
 }
@@ -0,0 +1,374 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-mongo-template/mongo/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEventPool(t *testing.T) {
+	tokenStore := NewMemoryTokenStore()
+	opt := option.MergeWatchHandlerByParams([]*option.WatchWithHandler{
+		option.NewWatchWithHandler().
+			SetConcurrency(4).
+			SetQueueSize(1).
+			SetTokenStore(tokenStore).
+			SetTokenKey("test"),
+	})
+	var mutex sync.Mutex
+	processed := map[int32][]int32{}
+	pool := newEventPool(context.TODO(), func(ctx *EventContext) error {
+		var value struct {
+			Seq int32 `bson:"seq"`
+			Key int32 `bson:"key"`
+		}
+		err := ctx.Event.FullDocument.Decode(&value)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		time.Sleep(time.Duration(value.Seq%3) * time.Millisecond)
+		mutex.Lock()
+		processed[value.Key] = append(processed[value.Key], value.Seq)
+		mutex.Unlock()
+		return nil
+	}, opt)
+	for i := int32(0); i < 50; i++ {
+		if !pool.dispatch(initTestEventRaw(i, i%5), initTestResumeToken(i)) {
+			t.Fatal("dispatch() expected true")
+		}
+	}
+	err := pool.wait()
+	if helper.IsNotNil(err) {
+		t.Fatal("wait() error:", err)
+	}
+	for key, seqs := range processed {
+		for i := 1; i < len(seqs); i++ {
+			if helper.IsLessThan(seqs[i], seqs[i-1]) {
+				t.Errorf("events of key %v out of order: %v", key, seqs)
+			}
+		}
+	}
+	token, _ := tokenStore.Load(context.TODO(), "test")
+	if helper.IsNotEqualTo(token.Lookup("seq").Int32(), int32(49)) {
+		t.Errorf("checkpoint = %v, expected seq 49", token)
+	}
+}
+
+func TestEventPoolFailure(t *testing.T) {
+	opt := option.MergeWatchHandlerByParams([]*option.WatchWithHandler{
+		option.NewWatchWithHandler().SetConcurrency(2).SetMaxRetries(0),
+	})
+	pool := newEventPool(context.TODO(), func(ctx *EventContext) error {
+		var value struct {
+			Seq int32 `bson:"seq"`
+		}
+		_ = ctx.Event.FullDocument.Decode(&value)
+		if helper.Equals(value.Seq, int32(3)) {
+			return errors.New("handler failed")
+		}
+		return nil
+	}, opt)
+	for i := int32(0); i < 10; i++ {
+		if !pool.dispatch(initTestEventRaw(i, 0), initTestResumeToken(i)) {
+			break
+		}
+	}
+	err := pool.wait()
+	if helper.IsNil(err) {
+		t.Error("wait() expected error handler failed")
+	}
+	if helper.IsNotEqualTo(pool.checkpoint().Lookup("seq").Int32(), int32(2)) {
+		t.Errorf("checkpoint = %v, expected seq 2", pool.checkpoint())
+	}
+}
+
+type blockingTokenStore struct {
+	*MemoryTokenStore
+	saves   int
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingTokenStore) Save(ctx context.Context, key string, resumeToken bson.Raw) error {
+	b.saves++
+	if helper.Equals(b.saves, 1) {
+		close(b.started)
+		<-b.release
+	}
+	return b.MemoryTokenStore.Save(ctx, key, resumeToken)
+}
+
+func TestEventPoolSaveCoalesced(t *testing.T) {
+	tokenStore := &blockingTokenStore{
+		MemoryTokenStore: NewMemoryTokenStore(),
+		started:          make(chan struct{}),
+		release:          make(chan struct{}),
+	}
+	pool := &eventPool{
+		ctx: context.TODO(),
+		opt: option.MergeWatchHandlerByParams([]*option.WatchWithHandler{
+			option.NewWatchWithHandler().SetTokenStore(tokenStore).SetTokenKey("test"),
+		}),
+		done: map[uint64]bson.Raw{},
+	}
+	saved := make(chan struct{})
+	go func() {
+		defer close(saved)
+		pool.complete(eventTask{seq: 0, resumeToken: initTestResumeToken(0)})
+	}()
+	<-tokenStore.started
+	// the save in progress does not hold the mutex, so the next events complete without waiting it
+	for i := int32(1); i < 5; i++ {
+		pool.complete(eventTask{seq: uint64(i), resumeToken: initTestResumeToken(i)})
+	}
+	if helper.IsNotEqualTo(pool.checkpoint().Lookup("seq").Int32(), int32(4)) {
+		t.Errorf("checkpoint = %v, expected seq 4", pool.checkpoint())
+	}
+	close(tokenStore.release)
+	<-saved
+	token, _ := tokenStore.Load(context.TODO(), "test")
+	if helper.IsNotEqualTo(tokenStore.saves, 2) || helper.IsNotEqualTo(token.Lookup("seq").Int32(), int32(4)) {
+		t.Errorf("Save() saves = %v, token = %v, expected 2 saves with seq 4", tokenStore.saves, token)
+	}
+}
+
+func TestProcessNextEventTimeout(t *testing.T) {
+	for _, timeoutAsFailure := range []bool{false, true} {
+		var timeouts int
+		var cancelled bool
+		opt := option.MergeWatchHandlerByParams([]*option.WatchWithHandler{
+			option.NewWatchWithHandler().
+				SetContextFuncTimeout(50 * time.Millisecond).
+				SetTimeoutAsFailure(timeoutAsFailure).
+				SetOnTimeout(func(ctx context.Context, event bson.Raw, timeout time.Duration) {
+					timeouts++
+				}),
+		})
+		err := processNextEvent(context.TODO(), func(ctx *EventContext) error {
+			<-ctx.Done()
+			cancelled = true
+			return ctx.Err()
+		}, Event{}, initTestEventRaw(0, 0), opt)
+		if helper.IsNotEqualTo(errors.Is(err, ErrEventHandlerTimeout), timeoutAsFailure) {
+			t.Errorf("processNextEvent() error = %v, timeoutAsFailure %v", err, timeoutAsFailure)
+		}
+		if !cancelled || helper.IsNotEqualTo(timeouts, 1) {
+			t.Errorf("processNextEvent() cancelled = %v, timeouts = %v", cancelled, timeouts)
+		}
+	}
+}
+
+func TestProcessNextEventDeadlineError(t *testing.T) {
+	var timeouts int
+	opt := option.MergeWatchHandlerByParams([]*option.WatchWithHandler{
+		option.NewWatchWithHandler().
+			SetContextFuncTimeout(time.Nanosecond).
+			SetTimeoutAsFailure(true).
+			SetOnTimeout(func(ctx context.Context, event bson.Raw, timeout time.Duration) {
+				timeouts++
+			}),
+	})
+	err := processNextEvent(context.TODO(), func(ctx *EventContext) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, Event{}, initTestEventRaw(0, 0), opt)
+	if !errors.Is(err, ErrEventHandlerTimeout) || helper.IsNotEqualTo(timeouts, 1) {
+		t.Errorf("processNextEvent() error = %v, timeouts = %v, want %v", err, timeouts, ErrEventHandlerTimeout)
+	}
+}
+
+func TestIsEventHandlerTimeout(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.TODO(), 0)
+	defer cancel()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{name: "deadline of the handler ctx", ctx: expired, err: context.DeadlineExceeded, want: true},
+		{name: "wrapped deadline", ctx: expired, err: fmt.Errorf("handler: %w", context.DeadlineExceeded), want: true},
+		{name: "other error", ctx: expired, err: errors.New("handler failed")},
+		{name: "deadline of another ctx", ctx: context.TODO(), err: context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isEventHandlerTimeout(tt.ctx, tt.err); helper.IsNotEqualTo(got, tt.want) {
+				t.Errorf("isEventHandlerTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	b, _ := bson.Marshal(bson.M{
+		"_id":           bson.M{"_data": "token"},
+		"operationType": "update",
+		"clusterTime":   primitive.Timestamp{T: 1, I: 1},
+		"wallTime":      primitive.NewDateTimeFromTime(time.Now()),
+		"txnNumber":     int64(1),
+		"lsid":          bson.M{"id": primitive.Binary{Subtype: 4, Data: make([]byte, 16)}},
+		"ns":            bson.M{"db": "test", "coll": "test"},
+		"documentKey":   bson.M{"_id": "string key", "region": "br"},
+		"updateDescription": bson.M{
+			"updatedFields":      bson.M{"name": "Test"},
+			"removedFields":      bson.A{"balance"},
+			"truncatedArrays":    bson.A{bson.M{"field": "emails", "newSize": int32(1)}},
+			"disambiguatedPaths": bson.M{},
+		},
+		"fullDocument":             bson.M{"name": "Test", "emails": bson.A{"test@gmail.com"}},
+		"fullDocumentBeforeChange": bson.M{"name": "Old", "balance": 10.5},
+	})
+	var event Event
+	err := bson.Unmarshal(b, &event)
+	if helper.IsNotNil(err) {
+		t.Fatal("Unmarshal() error:", err)
+	}
+	if helper.IsNotEqualTo(event.DocumentKey.ID, "string key") ||
+		helper.IsNotEqualTo(event.DocumentKey.ShardKey["region"], "br") ||
+		helper.IsNotEqualTo(event.OperationType, OperationTypeUpdate) ||
+		helper.IsNotEqualTo(event.UpdateDescription.TruncatedArrays[0].NewSize, int32(1)) ||
+		helper.IsNil(event.TxnNumber) || event.WallTime.IsZero() {
+		t.Errorf("Unmarshal() event = %+v", event)
+	}
+	typedEvent, err := DecodeEvent[testStruct](b)
+	if helper.IsNotNil(err) {
+		t.Fatal("DecodeEvent() error:", err)
+	}
+	if helper.IsNotEqualTo(typedEvent.FullDocument.Name, "Test") ||
+		helper.IsNotEqualTo(typedEvent.FullDocumentBeforeChange.Balance, 10.5) ||
+		helper.IsNotEqualTo(typedEvent.NS.Coll, "test") {
+		t.Errorf("DecodeEvent() event = %+v", typedEvent)
+	}
+}
+
+func TestEventRouter(t *testing.T) {
+	var calls []OperationType
+	handler := func(ctx *EventContext) error {
+		calls = append(calls, ctx.Event.OperationType)
+		return nil
+	}
+	router := NewEventRouter().
+		OnInsert(testStruct{}, handler).
+		OnUpdate(testStruct{}, handler).
+		OnReplace(testStruct{}, handler).
+		OnDelete(testStruct{}, handler).
+		OnDDL(testStruct{}, handler)
+	if helper.IsNotNil(router.Err()) {
+		t.Fatal("Err() error:", router.Err())
+	}
+	events := []Event{
+		{ChangeEvent: ChangeEvent{OperationType: OperationTypeInsert, NS: Namespace{DB: "test", Coll: "test"}}},
+		{ChangeEvent: ChangeEvent{OperationType: OperationTypeUpdate, NS: Namespace{DB: "test", Coll: "other"}}},
+		{ChangeEvent: ChangeEvent{OperationType: OperationTypeDelete, NS: Namespace{DB: "other", Coll: "test"}}},
+		{ChangeEvent: ChangeEvent{OperationType: OperationTypeDrop, NS: Namespace{DB: "test", Coll: "test"}}},
+		{ChangeEvent: ChangeEvent{OperationType: OperationTypeDropDatabase, NS: Namespace{DB: "test"}}},
+	}
+	for _, event := range events {
+		if err := router.Handle(&EventContext{Context: context.TODO(), Event: event}); helper.IsNotNil(err) {
+			t.Error("Handle() error:", err)
+		}
+	}
+	expected := []OperationType{OperationTypeInsert, OperationTypeDrop, OperationTypeDropDatabase}
+	if helper.IsNotEqualTo(calls, expected) {
+		t.Errorf("Handle() calls = %v, expected %v", calls, expected)
+	}
+	if helper.IsNotEqualTo(len(router.Pipeline()), 1) || helper.IsNotEqualTo(router.databaseName(), "test") {
+		t.Errorf("Pipeline() = %v", router.Pipeline())
+	}
+	if helper.IsNil(NewEventRouter().OnInsert(testInvalidStruct{}, handler).Err()) {
+		t.Error("Err() expected error database not configured")
+	}
+}
+
+func initTestEventRaw(seq, key int32) bson.Raw {
+	b, _ := bson.Marshal(bson.M{
+		"_id":           initTestResumeToken(seq),
+		"operationType": "insert",
+		"documentKey":   bson.M{"_id": primitive.ObjectID{byte(key)}},
+		"fullDocument":  bson.M{"seq": seq, "key": key},
+	})
+	return b
+}
+
+func initTestResumeToken(seq int32) bson.Raw {
+	b, _ := bson.Marshal(bson.M{"seq": seq})
+	return b
+}
+
+func TestNewPollingEvent(t *testing.T) {
+	ns := Namespace{DB: "test", Coll: "test"}
+	now := time.Now().Truncate(time.Millisecond)
+	oldId := primitive.NewObjectIDFromTimestamp(now.Add(-time.Hour))
+	newId := primitive.NewObjectIDFromTimestamp(now)
+	state := &pollingState{}
+	if helper.IsNotEqualTo(pollingFilter("updatedAt", state), bson.D{{"updatedAt", bson.D{{"$exists", true}}}}) {
+		t.Errorf("pollingFilter() = %v", pollingFilter("updatedAt", state))
+	}
+	maxId, _ := bson.Marshal(bson.M{"_id": oldId})
+	state.MaxID = bson.Raw(maxId).Lookup("_id")
+	var operationTypes []OperationType
+	var resumeToken bson.Raw
+	for _, id := range []primitive.ObjectID{oldId, newId} {
+		document, _ := bson.Marshal(bson.M{"_id": id, "name": "Test", "updatedAt": now})
+		current, token, err := newPollingEvent(document, ns, state, "updatedAt")
+		if helper.IsNotNil(err) {
+			t.Fatal("newPollingEvent() error:", err)
+		}
+		event, err := DecodeEvent[testStruct](current)
+		if helper.IsNotNil(err) {
+			t.Fatal("DecodeEvent() error:", err)
+		}
+		if helper.IsNotEqualTo(event.DocumentKey.ID, id) || helper.IsNotEqualTo(event.NS, ns) ||
+			helper.IsNotEqualTo(event.FullDocument.Name, "Test") || !isPollingToken(event.ResumeToken) {
+			t.Errorf("newPollingEvent() event = %+v", event)
+		}
+		operationTypes = append(operationTypes, event.OperationType)
+		resumeToken = token
+	}
+	expected := []OperationType{OperationTypeUpdate, OperationTypeInsert}
+	if helper.IsNotEqualTo(operationTypes, expected) {
+		t.Errorf("newPollingEvent() operation types = %v, expected %v", operationTypes, expected)
+	}
+	loaded, err := parsePollingToken(resumeToken)
+	if helper.IsNotNil(err) {
+		t.Fatal("parsePollingToken() error:", err)
+	}
+	if helper.IsNotEqualTo(loaded.ID.ObjectID(), newId) || helper.IsNotEqualTo(loaded.MaxID.ObjectID(), newId) ||
+		!loaded.Value.Time().Equal(now) || helper.IsNotEqualTo(len(pollingFilter("updatedAt", loaded)), 1) {
+		t.Errorf("parsePollingToken() state = %+v", loaded)
+	}
+	if isPollingToken(initTestResumeToken(0)) {
+		t.Error("isPollingToken() expected false for change stream token")
+	}
+}
+
+func TestWatchHandlerIsNil(t *testing.T) {
+	ctx := context.TODO()
+	if err := (&Template{}).WatchWithHandler(ctx, nil, nil); helper.IsNotEqualTo(err, ErrEventHandlerIsNil) {
+		t.Errorf("WatchWithHandler() error = %v, want %v", err, ErrEventHandlerIsNil)
+	}
+	if err := (&Template{}).WatchWithHandlerE(ctx, nil, nil); helper.IsNotEqualTo(err, ErrEventHandlerIsNil) {
+		t.Errorf("WatchWithHandlerE() error = %v, want %v", err, ErrEventHandlerIsNil)
+	}
+}
+
+func TestMergeWatchHandlerDeadLetter(t *testing.T) {
+	deadLetter := func(ctx context.Context, event bson.Raw, err error) error {
+		return nil
+	}
+	opt := option.MergeWatchHandlerByParams([]*option.WatchWithHandler{
+		option.NewWatchWithHandler().SetDeadLetter(deadLetter),
+		option.NewWatchWithHandler(),
+	})
+	if opt.DeadLetter == nil {
+		t.Error("MergeWatchHandlerByParams() DeadLetter = nil, want the func of the first option")
+	}
+}